@@ -0,0 +1,47 @@
+// Browser viewer for a terminal shared with ShareTerminal. It is served by the
+// share server itself, so viewers never load code from another origin.
+import { Terminal } from '@xterm/xterm'
+import '@xterm/xterm/css/xterm.css'
+
+interface ShareMessage {
+  type: 'mode' | 'resize' | 'output' | 'closed'
+  data?: string
+  rows?: number
+  cols?: number
+}
+
+const status = document.getElementById('status')!
+const term = new Terminal({ disableStdin: true, scrollback: 10000 })
+term.open(document.getElementById('terminal')!)
+
+const scheme = location.protocol === 'https:' ? 'wss://' : 'ws://'
+const ws = new WebSocket(scheme + location.host + location.pathname + '/ws')
+let closed = false
+
+ws.onmessage = (event) => {
+  const msg: ShareMessage = JSON.parse(event.data)
+  switch (msg.type) {
+    case 'mode': {
+      const readWrite = msg.data === 'read-write'
+      term.options.disableStdin = !readWrite
+      status.textContent = readWrite ? 'Connected (you can type)' : 'Connected (view only)'
+      break
+    }
+    case 'resize':
+      if (msg.rows && msg.cols && msg.rows > 0 && msg.cols > 0) term.resize(msg.cols, msg.rows)
+      break
+    case 'output':
+      term.write(msg.data ?? '')
+      break
+    case 'closed':
+      closed = true
+      status.textContent = msg.data ?? ''
+      break
+  }
+}
+ws.onclose = () => {
+  if (!closed) status.textContent = 'Disconnected'
+}
+term.onData((data) => {
+  if (ws.readyState === WebSocket.OPEN) ws.send(JSON.stringify({ type: 'input', data }))
+})
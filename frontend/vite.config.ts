@@ -18,5 +18,12 @@ export default defineConfig({
   build: {
     outDir: 'dist',
     emptyOutDir: true,
+    rollupOptions: {
+      input: {
+        main: path.resolve(__dirname, 'index.html'),
+        // Viewer page the terminal sharing server hands to browsers
+        share: path.resolve(__dirname, 'share.html'),
+      },
+    },
   },
 })
@@ -3,12 +3,50 @@
 import {app} from '../models';
 import {context} from '../models';
 
+export function AddAutomationScript(arg1:app.AutomationScript):Promise<app.AutomationScript>;
+
+export function AddBroadcastMember(arg1:string,arg2:string):Promise<void>;
+
+export function AddSnippet(arg1:app.Snippet):Promise<app.Snippet>;
+
 export function AddSyncRule(arg1:app.SyncRule):Promise<app.SyncRule>;
 
+export function AddTriggerRule(arg1:app.TriggerRule):Promise<app.TriggerRule>;
+
+export function BroadcastToGroup(arg1:string,arg2:string):Promise<app.BroadcastResult>;
+
+export function CancelAutomationRun(arg1:string):Promise<void>;
+
+export function CancelBatchRun(arg1:string):Promise<void>;
+
+export function CancelCommand(arg1:string):Promise<void>;
+
+export function CancelTransfer(arg1:string):Promise<void>;
+
+export function CancelZmodemTransfer(arg1:string):Promise<void>;
+
+export function CheckRemoteMultiplexer(arg1:string):Promise<app.MultiplexerStatus>;
+
 export function CheckRemoteSyncDeps(arg1:string):Promise<app.RemoteDepsStatus>;
 
+export function ChgrpLocalFile(arg1:string,arg2:string,arg3:boolean):Promise<app.FileChangeResult>;
+
+export function ChgrpRemoteFile(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<app.FileChangeResult>;
+
+export function ChmodLocalFile(arg1:string,arg2:string,arg3:boolean):Promise<app.FileChangeResult>;
+
+export function ChmodRemoteFile(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<app.FileChangeResult>;
+
+export function ChownLocalFile(arg1:string,arg2:string,arg3:boolean):Promise<app.FileChangeResult>;
+
+export function ChownRemoteFile(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<app.FileChangeResult>;
+
+export function ClearCommandHistory(arg1:string):Promise<void>;
+
 export function ClearDebugLog():Promise<void>;
 
+export function ClearTransferHistory():Promise<void>;
+
 export function CloseTerminalSession(arg1:string):Promise<void>;
 
 export function ConnectSSH(arg1:app.SSHConfigEntry):Promise<string>;
@@ -21,6 +59,8 @@ export function CopyLocalFile(arg1:string,arg2:string):Promise<void>;
 
 export function CopyRemoteFilesToSystemClipboard(arg1:string,arg2:Array<string>):Promise<void>;
 
+export function CreateBroadcastGroup(arg1:string,arg2:Array<string>):Promise<app.BroadcastGroup>;
+
 export function CreateLocalDirectory(arg1:string):Promise<void>;
 
 export function CreateLocalFile(arg1:string):Promise<void>;
@@ -29,6 +69,16 @@ export function CreateLocalTerminalSession():Promise<string>;
 
 export function CreatePTY(arg1:string):Promise<void>;
 
+export function DeleteAutomationScript(arg1:string):Promise<void>;
+
+export function DeleteBatchRun(arg1:string):Promise<void>;
+
+export function DeleteBroadcastGroup(arg1:string):Promise<void>;
+
+export function DeleteCommandHistoryEntry(arg1:string):Promise<void>;
+
+export function DeleteHostSettings(arg1:string):Promise<void>;
+
 export function DeleteLocalDirectory(arg1:string):Promise<void>;
 
 export function DeleteLocalFile(arg1:string):Promise<void>;
@@ -37,14 +87,36 @@ export function DeleteRemoteDirectory(arg1:string,arg2:string):Promise<void>;
 
 export function DeleteRemoteFile(arg1:string,arg2:string):Promise<void>;
 
+export function DeleteSnippet(arg1:string):Promise<void>;
+
+export function DeleteTerminalProfile(arg1:string):Promise<void>;
+
+export function DeleteTriggerRule(arg1:string):Promise<void>;
+
 export function DisconnectSSH(arg1:string):Promise<void>;
 
-export function DownloadDirectory(arg1:string,arg2:string,arg3:string):Promise<void>;
+export function DownloadDirectory(arg1:string,arg2:string,arg3:string):Promise<app.DirectoryTransferResult>;
 
 export function DownloadFile(arg1:string,arg2:string,arg3:string):Promise<string>;
 
+export function EnableShellIntegration(arg1:string):Promise<void>;
+
 export function ExecuteCommand(arg1:string,arg2:string):Promise<string>;
 
+export function ExportBatchRun(arg1:string,arg2:string):Promise<string>;
+
+export function ExportSnippets(arg1:Array<string>):Promise<string>;
+
+export function GetAllHostSettings():Promise<Array<app.HostSettings>>;
+
+export function GetAutomationScripts():Promise<Array<app.AutomationScript>>;
+
+export function GetBatchRuns():Promise<Array<app.BatchRun>>;
+
+export function GetBroadcastGroups():Promise<Array<app.BroadcastGroup>>;
+
+export function GetCommandHistory(arg1:app.CommandHistoryQuery):Promise<Array<app.CommandRecord>>;
+
 export function GetCurrentDirectory(arg1:string):Promise<string>;
 
 export function GetDebugLogPath():Promise<string>;
@@ -55,6 +127,10 @@ export function GetFileClipboard():Promise<app.ClipboardData>;
 
 export function GetHomeDirectory():Promise<string>;
 
+export function GetHostSettings(arg1:string):Promise<app.HostSettings>;
+
+export function GetHostTags():Promise<Array<string>>;
+
 export function GetNextUntitledFileName(arg1:string):Promise<string>;
 
 export function GetOpenEditorCount():Promise<number>;
@@ -65,15 +141,51 @@ export function GetRemoteHomeDir(arg1:string):Promise<string>;
 
 export function GetSSHConfig():Promise<Array<app.SSHConfigEntry>>;
 
+export function GetShareServerConfig():Promise<app.ShareServerConfig>;
+
+export function GetShellIntegrationScript():Promise<string>;
+
+export function GetSnippetVariables(arg1:string):Promise<Array<app.SnippetVariable>>;
+
+export function GetSnippets(arg1:app.SnippetQuery):Promise<Array<app.Snippet>>;
+
+export function GetSupportedEncodings():Promise<Array<app.TerminalEncoding>>;
+
 export function GetSyncRules():Promise<Array<app.SyncRule>>;
 
+export function GetTerminalCwd(arg1:string):Promise<string>;
+
+export function GetTerminalEncoding(arg1:string):Promise<string>;
+
+export function GetTerminalForegroundProcess(arg1:string):Promise<app.TerminalProcess>;
+
+export function GetTerminalProfiles():Promise<Array<app.TerminalProfile>>;
+
 export function GetTerminalSettings():Promise<string>;
 
+export function GetTerminalShares():Promise<Array<app.TerminalShare>>;
+
+export function GetTransferConcurrency():Promise<number>;
+
+export function GetTransferHistory():Promise<Array<app.TransferJob>>;
+
+export function GetTransferJobs():Promise<Array<app.TransferJob>>;
+
+export function GetTriggerRules():Promise<Array<app.TriggerRule>>;
+
+export function ImportSnippets():Promise<app.SnippetImportResult>;
+
 export function IsDirectory(arg1:string):Promise<boolean>;
 
+export function IsTerminalBusy(arg1:string):Promise<boolean>;
+
+export function IsTerminalRecording(arg1:string):Promise<boolean>;
+
 export function ListFiles(arg1:string,arg2:string):Promise<Array<app.FileInfo>>;
 
-export function ListLocalFiles(arg1:string):Promise<Array<app.LocalFileInfo>>;
+export function ListLocalFiles(arg1:string):Promise<Array<app.FileInfo>>;
+
+export function ListMultiplexerSessions(arg1:string):Promise<Array<app.MultiplexerSession>>;
 
 export function LoadEditorTabs():Promise<string>;
 
@@ -93,48 +205,118 @@ export function OpenTerminalAtPath(arg1:string):Promise<void>;
 
 export function PasteFiles(arg1:string):Promise<void>;
 
+export function PauseTransfer(arg1:string):Promise<void>;
+
+export function QueueDownload(arg1:string,arg2:string,arg3:string):Promise<app.TransferJob>;
+
+export function QueueRemoteCopy(arg1:string,arg2:string,arg3:string,arg4:string,arg5:boolean):Promise<app.TransferJob>;
+
+export function QueueUpload(arg1:string,arg2:string,arg3:string):Promise<app.TransferJob>;
+
 export function ReadLocalFile(arg1:string):Promise<string>;
 
 export function ReadRemoteFile(arg1:string,arg2:string):Promise<string>;
 
+export function RemoveBroadcastMember(arg1:string,arg2:string):Promise<void>;
+
 export function RemoveSyncRule(arg1:string):Promise<void>;
 
 export function RenameLocalFile(arg1:string,arg2:string):Promise<void>;
 
 export function RenameRemoteFile(arg1:string,arg2:string,arg3:string):Promise<void>;
 
+export function RenderSnippet(arg1:string,arg2:Record<string, string>):Promise<string>;
+
+export function RerunCommand(arg1:string,arg2:string):Promise<void>;
+
+export function ResetTriggerCounters(arg1:string):Promise<void>;
+
 export function ResizeTerminal(arg1:string,arg2:number,arg3:number):Promise<void>;
 
+export function RespondClipboardRequest(arg1:string,arg2:boolean,arg3:boolean):Promise<void>;
+
+export function ResumeTransfer(arg1:string):Promise<void>;
+
+export function RetryTransfer(arg1:string):Promise<app.TransferJob>;
+
+export function RevokeTerminalShare(arg1:string):Promise<void>;
+
+export function RunAutomationScript(arg1:string,arg2:string):Promise<string>;
+
+export function RunSnippet(arg1:string,arg2:string,arg3:Record<string, string>):Promise<string>;
+
 export function SaveEditorTabs(arg1:string):Promise<void>;
 
 export function SaveFilesTabs(arg1:string):Promise<void>;
 
+export function SaveTerminalProfile(arg1:app.TerminalProfile):Promise<app.TerminalProfile>;
+
 export function SaveTerminalSessions(arg1:string):Promise<void>;
 
+export function SendSnippetToTerminal(arg1:string,arg2:string,arg3:Record<string, string>,arg4:boolean):Promise<void>;
+
+export function SetBroadcastGroupActive(arg1:string,arg2:boolean):Promise<void>;
+
+export function SetBroadcastMemberEnabled(arg1:string,arg2:string,arg3:boolean):Promise<void>;
+
+export function SetDefaultTerminalProfile(arg1:string):Promise<void>;
+
 export function SetFileClipboard(arg1:Array<string>,arg2:string):Promise<void>;
 
+export function SetHostSettings(arg1:app.HostSettings):Promise<void>;
+
+export function SetShareServerConfig(arg1:app.ShareServerConfig):Promise<void>;
+
 export function SetSyncSource(arg1:string,arg2:string):Promise<void>;
 
+export function SetTerminalEncoding(arg1:string,arg2:string):Promise<void>;
+
 export function SetTerminalSettings(arg1:string):Promise<void>;
 
+export function SetTransferConcurrency(arg1:number):Promise<void>;
+
+export function ShareTerminal(arg1:string,arg2:app.ShareOptions):Promise<app.TerminalShare>;
+
 export function ShowAllEditorWindows():Promise<void>;
 
+export function SignalTerminalProcess(arg1:string,arg2:string):Promise<void>;
+
+export function StartBatchRun(arg1:app.BatchRunRequest):Promise<string>;
+
+export function StartCommand(arg1:string,arg2:string,arg3:app.CommandOptions):Promise<string>;
+
 export function StartEditorServer():Promise<void>;
 
 export function StartLocalTerminalSession(arg1:string,arg2:number,arg3:number,arg4:string):Promise<void>;
 
+export function StartLocalTerminalSessionWithProfile(arg1:string,arg2:number,arg3:number,arg4:string,arg5:string):Promise<void>;
+
+export function StartMultiplexedTerminalSession(arg1:string,arg2:number,arg3:number,arg4:string,arg5:string):Promise<void>;
+
 export function StartSync(arg1:string):Promise<void>;
 
+export function StartTerminalRecording(arg1:string):Promise<string>;
+
 export function StartTerminalSession(arg1:string,arg2:number,arg3:number):Promise<void>;
 
 export function Startup(arg1:context.Context):Promise<void>;
 
 export function StopSync(arg1:string):Promise<void>;
 
+export function StopTerminalRecording(arg1:string):Promise<string>;
+
 export function TestSyncConnection(arg1:string):Promise<void>;
 
+export function UpdateAutomationScript(arg1:app.AutomationScript):Promise<void>;
+
+export function UpdateSnippet(arg1:app.Snippet):Promise<void>;
+
 export function UpdateSyncRule(arg1:app.SyncRule):Promise<void>;
 
+export function UpdateTriggerRule(arg1:app.TriggerRule):Promise<void>;
+
+export function UploadDirectory(arg1:string,arg2:string,arg3:string):Promise<app.DirectoryTransferResult>;
+
 export function UploadFile(arg1:string,arg2:string,arg3:string):Promise<string>;
 
 export function WriteDebugLog(arg1:string):Promise<void>;
@@ -2,18 +2,94 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddAutomationScript(arg1) {
+  return window['go']['app']['App']['AddAutomationScript'](arg1);
+}
+
+export function AddBroadcastMember(arg1, arg2) {
+  return window['go']['app']['App']['AddBroadcastMember'](arg1, arg2);
+}
+
+export function AddSnippet(arg1) {
+  return window['go']['app']['App']['AddSnippet'](arg1);
+}
+
 export function AddSyncRule(arg1) {
   return window['go']['app']['App']['AddSyncRule'](arg1);
 }
 
+export function AddTriggerRule(arg1) {
+  return window['go']['app']['App']['AddTriggerRule'](arg1);
+}
+
+export function BroadcastToGroup(arg1, arg2) {
+  return window['go']['app']['App']['BroadcastToGroup'](arg1, arg2);
+}
+
+export function CancelAutomationRun(arg1) {
+  return window['go']['app']['App']['CancelAutomationRun'](arg1);
+}
+
+export function CancelBatchRun(arg1) {
+  return window['go']['app']['App']['CancelBatchRun'](arg1);
+}
+
+export function CancelCommand(arg1) {
+  return window['go']['app']['App']['CancelCommand'](arg1);
+}
+
+export function CancelTransfer(arg1) {
+  return window['go']['app']['App']['CancelTransfer'](arg1);
+}
+
+export function CancelZmodemTransfer(arg1) {
+  return window['go']['app']['App']['CancelZmodemTransfer'](arg1);
+}
+
+export function CheckRemoteMultiplexer(arg1) {
+  return window['go']['app']['App']['CheckRemoteMultiplexer'](arg1);
+}
+
 export function CheckRemoteSyncDeps(arg1) {
   return window['go']['app']['App']['CheckRemoteSyncDeps'](arg1);
 }
 
+export function ChgrpLocalFile(arg1, arg2, arg3) {
+  return window['go']['app']['App']['ChgrpLocalFile'](arg1, arg2, arg3);
+}
+
+export function ChgrpRemoteFile(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['ChgrpRemoteFile'](arg1, arg2, arg3, arg4);
+}
+
+export function ChmodLocalFile(arg1, arg2, arg3) {
+  return window['go']['app']['App']['ChmodLocalFile'](arg1, arg2, arg3);
+}
+
+export function ChmodRemoteFile(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['ChmodRemoteFile'](arg1, arg2, arg3, arg4);
+}
+
+export function ChownLocalFile(arg1, arg2, arg3) {
+  return window['go']['app']['App']['ChownLocalFile'](arg1, arg2, arg3);
+}
+
+export function ChownRemoteFile(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['ChownRemoteFile'](arg1, arg2, arg3, arg4);
+}
+
+export function ClearCommandHistory(arg1) {
+  return window['go']['app']['App']['ClearCommandHistory'](arg1);
+}
+
 export function ClearDebugLog() {
   return window['go']['app']['App']['ClearDebugLog']();
 }
 
+export function ClearTransferHistory() {
+  return window['go']['app']['App']['ClearTransferHistory']();
+}
+
 export function CloseTerminalSession(arg1) {
   return window['go']['app']['App']['CloseTerminalSession'](arg1);
 }
@@ -38,6 +114,10 @@ export function CopyRemoteFilesToSystemClipboard(arg1, arg2) {
   return window['go']['app']['App']['CopyRemoteFilesToSystemClipboard'](arg1, arg2);
 }
 
+export function CreateBroadcastGroup(arg1, arg2) {
+  return window['go']['app']['App']['CreateBroadcastGroup'](arg1, arg2);
+}
+
 export function CreateLocalDirectory(arg1) {
   return window['go']['app']['App']['CreateLocalDirectory'](arg1);
 }
@@ -54,6 +134,26 @@ export function CreatePTY(arg1) {
   return window['go']['app']['App']['CreatePTY'](arg1);
 }
 
+export function DeleteAutomationScript(arg1) {
+  return window['go']['app']['App']['DeleteAutomationScript'](arg1);
+}
+
+export function DeleteBatchRun(arg1) {
+  return window['go']['app']['App']['DeleteBatchRun'](arg1);
+}
+
+export function DeleteBroadcastGroup(arg1) {
+  return window['go']['app']['App']['DeleteBroadcastGroup'](arg1);
+}
+
+export function DeleteCommandHistoryEntry(arg1) {
+  return window['go']['app']['App']['DeleteCommandHistoryEntry'](arg1);
+}
+
+export function DeleteHostSettings(arg1) {
+  return window['go']['app']['App']['DeleteHostSettings'](arg1);
+}
+
 export function DeleteLocalDirectory(arg1) {
   return window['go']['app']['App']['DeleteLocalDirectory'](arg1);
 }
@@ -70,6 +170,18 @@ export function DeleteRemoteFile(arg1, arg2) {
   return window['go']['app']['App']['DeleteRemoteFile'](arg1, arg2);
 }
 
+export function DeleteSnippet(arg1) {
+  return window['go']['app']['App']['DeleteSnippet'](arg1);
+}
+
+export function DeleteTerminalProfile(arg1) {
+  return window['go']['app']['App']['DeleteTerminalProfile'](arg1);
+}
+
+export function DeleteTriggerRule(arg1) {
+  return window['go']['app']['App']['DeleteTriggerRule'](arg1);
+}
+
 export function DisconnectSSH(arg1) {
   return window['go']['app']['App']['DisconnectSSH'](arg1);
 }
@@ -82,10 +194,42 @@ export function DownloadFile(arg1, arg2, arg3) {
   return window['go']['app']['App']['DownloadFile'](arg1, arg2, arg3);
 }
 
+export function EnableShellIntegration(arg1) {
+  return window['go']['app']['App']['EnableShellIntegration'](arg1);
+}
+
 export function ExecuteCommand(arg1, arg2) {
   return window['go']['app']['App']['ExecuteCommand'](arg1, arg2);
 }
 
+export function ExportBatchRun(arg1, arg2) {
+  return window['go']['app']['App']['ExportBatchRun'](arg1, arg2);
+}
+
+export function ExportSnippets(arg1) {
+  return window['go']['app']['App']['ExportSnippets'](arg1);
+}
+
+export function GetAllHostSettings() {
+  return window['go']['app']['App']['GetAllHostSettings']();
+}
+
+export function GetAutomationScripts() {
+  return window['go']['app']['App']['GetAutomationScripts']();
+}
+
+export function GetBatchRuns() {
+  return window['go']['app']['App']['GetBatchRuns']();
+}
+
+export function GetBroadcastGroups() {
+  return window['go']['app']['App']['GetBroadcastGroups']();
+}
+
+export function GetCommandHistory(arg1) {
+  return window['go']['app']['App']['GetCommandHistory'](arg1);
+}
+
 export function GetCurrentDirectory(arg1) {
   return window['go']['app']['App']['GetCurrentDirectory'](arg1);
 }
@@ -106,6 +250,14 @@ export function GetHomeDirectory() {
   return window['go']['app']['App']['GetHomeDirectory']();
 }
 
+export function GetHostSettings(arg1) {
+  return window['go']['app']['App']['GetHostSettings'](arg1);
+}
+
+export function GetHostTags() {
+  return window['go']['app']['App']['GetHostTags']();
+}
+
 export function GetNextUntitledFileName(arg1) {
   return window['go']['app']['App']['GetNextUntitledFileName'](arg1);
 }
@@ -126,18 +278,86 @@ export function GetSSHConfig() {
   return window['go']['app']['App']['GetSSHConfig']();
 }
 
+export function GetShareServerConfig() {
+  return window['go']['app']['App']['GetShareServerConfig']();
+}
+
+export function GetShellIntegrationScript() {
+  return window['go']['app']['App']['GetShellIntegrationScript']();
+}
+
+export function GetSnippetVariables(arg1) {
+  return window['go']['app']['App']['GetSnippetVariables'](arg1);
+}
+
+export function GetSnippets(arg1) {
+  return window['go']['app']['App']['GetSnippets'](arg1);
+}
+
+export function GetSupportedEncodings() {
+  return window['go']['app']['App']['GetSupportedEncodings']();
+}
+
 export function GetSyncRules() {
   return window['go']['app']['App']['GetSyncRules']();
 }
 
+export function GetTerminalCwd(arg1) {
+  return window['go']['app']['App']['GetTerminalCwd'](arg1);
+}
+
+export function GetTerminalEncoding(arg1) {
+  return window['go']['app']['App']['GetTerminalEncoding'](arg1);
+}
+
+export function GetTerminalForegroundProcess(arg1) {
+  return window['go']['app']['App']['GetTerminalForegroundProcess'](arg1);
+}
+
+export function GetTerminalProfiles() {
+  return window['go']['app']['App']['GetTerminalProfiles']();
+}
+
 export function GetTerminalSettings() {
   return window['go']['app']['App']['GetTerminalSettings']();
 }
 
+export function GetTerminalShares() {
+  return window['go']['app']['App']['GetTerminalShares']();
+}
+
+export function GetTransferConcurrency() {
+  return window['go']['app']['App']['GetTransferConcurrency']();
+}
+
+export function GetTransferHistory() {
+  return window['go']['app']['App']['GetTransferHistory']();
+}
+
+export function GetTransferJobs() {
+  return window['go']['app']['App']['GetTransferJobs']();
+}
+
+export function GetTriggerRules() {
+  return window['go']['app']['App']['GetTriggerRules']();
+}
+
+export function ImportSnippets() {
+  return window['go']['app']['App']['ImportSnippets']();
+}
+
 export function IsDirectory(arg1) {
   return window['go']['app']['App']['IsDirectory'](arg1);
 }
 
+export function IsTerminalBusy(arg1) {
+  return window['go']['app']['App']['IsTerminalBusy'](arg1);
+}
+
+export function IsTerminalRecording(arg1) {
+  return window['go']['app']['App']['IsTerminalRecording'](arg1);
+}
+
 export function ListFiles(arg1, arg2) {
   return window['go']['app']['App']['ListFiles'](arg1, arg2);
 }
@@ -146,6 +366,10 @@ export function ListLocalFiles(arg1) {
   return window['go']['app']['App']['ListLocalFiles'](arg1);
 }
 
+export function ListMultiplexerSessions(arg1) {
+  return window['go']['app']['App']['ListMultiplexerSessions'](arg1);
+}
+
 export function LoadEditorTabs() {
   return window['go']['app']['App']['LoadEditorTabs']();
 }
@@ -182,6 +406,22 @@ export function PasteFiles(arg1) {
   return window['go']['app']['App']['PasteFiles'](arg1);
 }
 
+export function PauseTransfer(arg1) {
+  return window['go']['app']['App']['PauseTransfer'](arg1);
+}
+
+export function QueueDownload(arg1, arg2, arg3) {
+  return window['go']['app']['App']['QueueDownload'](arg1, arg2, arg3);
+}
+
+export function QueueRemoteCopy(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['app']['App']['QueueRemoteCopy'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function QueueUpload(arg1, arg2, arg3) {
+  return window['go']['app']['App']['QueueUpload'](arg1, arg2, arg3);
+}
+
 export function ReadLocalFile(arg1) {
   return window['go']['app']['App']['ReadLocalFile'](arg1);
 }
@@ -190,6 +430,10 @@ export function ReadRemoteFile(arg1, arg2) {
   return window['go']['app']['App']['ReadRemoteFile'](arg1, arg2);
 }
 
+export function RemoveBroadcastMember(arg1, arg2) {
+  return window['go']['app']['App']['RemoveBroadcastMember'](arg1, arg2);
+}
+
 export function RemoveSyncRule(arg1) {
   return window['go']['app']['App']['RemoveSyncRule'](arg1);
 }
@@ -202,10 +446,46 @@ export function RenameRemoteFile(arg1, arg2, arg3) {
   return window['go']['app']['App']['RenameRemoteFile'](arg1, arg2, arg3);
 }
 
+export function RenderSnippet(arg1, arg2) {
+  return window['go']['app']['App']['RenderSnippet'](arg1, arg2);
+}
+
+export function RerunCommand(arg1, arg2) {
+  return window['go']['app']['App']['RerunCommand'](arg1, arg2);
+}
+
+export function ResetTriggerCounters(arg1) {
+  return window['go']['app']['App']['ResetTriggerCounters'](arg1);
+}
+
 export function ResizeTerminal(arg1, arg2, arg3) {
   return window['go']['app']['App']['ResizeTerminal'](arg1, arg2, arg3);
 }
 
+export function RespondClipboardRequest(arg1, arg2, arg3) {
+  return window['go']['app']['App']['RespondClipboardRequest'](arg1, arg2, arg3);
+}
+
+export function ResumeTransfer(arg1) {
+  return window['go']['app']['App']['ResumeTransfer'](arg1);
+}
+
+export function RetryTransfer(arg1) {
+  return window['go']['app']['App']['RetryTransfer'](arg1);
+}
+
+export function RevokeTerminalShare(arg1) {
+  return window['go']['app']['App']['RevokeTerminalShare'](arg1);
+}
+
+export function RunAutomationScript(arg1, arg2) {
+  return window['go']['app']['App']['RunAutomationScript'](arg1, arg2);
+}
+
+export function RunSnippet(arg1, arg2, arg3) {
+  return window['go']['app']['App']['RunSnippet'](arg1, arg2, arg3);
+}
+
 export function SaveEditorTabs(arg1) {
   return window['go']['app']['App']['SaveEditorTabs'](arg1);
 }
@@ -214,26 +494,78 @@ export function SaveFilesTabs(arg1) {
   return window['go']['app']['App']['SaveFilesTabs'](arg1);
 }
 
+export function SaveTerminalProfile(arg1) {
+  return window['go']['app']['App']['SaveTerminalProfile'](arg1);
+}
+
 export function SaveTerminalSessions(arg1) {
   return window['go']['app']['App']['SaveTerminalSessions'](arg1);
 }
 
+export function SendSnippetToTerminal(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['SendSnippetToTerminal'](arg1, arg2, arg3, arg4);
+}
+
+export function SetBroadcastGroupActive(arg1, arg2) {
+  return window['go']['app']['App']['SetBroadcastGroupActive'](arg1, arg2);
+}
+
+export function SetBroadcastMemberEnabled(arg1, arg2, arg3) {
+  return window['go']['app']['App']['SetBroadcastMemberEnabled'](arg1, arg2, arg3);
+}
+
+export function SetDefaultTerminalProfile(arg1) {
+  return window['go']['app']['App']['SetDefaultTerminalProfile'](arg1);
+}
+
 export function SetFileClipboard(arg1, arg2) {
   return window['go']['app']['App']['SetFileClipboard'](arg1, arg2);
 }
 
+export function SetHostSettings(arg1) {
+  return window['go']['app']['App']['SetHostSettings'](arg1);
+}
+
+export function SetShareServerConfig(arg1) {
+  return window['go']['app']['App']['SetShareServerConfig'](arg1);
+}
+
 export function SetSyncSource(arg1, arg2) {
   return window['go']['app']['App']['SetSyncSource'](arg1, arg2);
 }
 
+export function SetTerminalEncoding(arg1, arg2) {
+  return window['go']['app']['App']['SetTerminalEncoding'](arg1, arg2);
+}
+
 export function SetTerminalSettings(arg1) {
   return window['go']['app']['App']['SetTerminalSettings'](arg1);
 }
 
+export function SetTransferConcurrency(arg1) {
+  return window['go']['app']['App']['SetTransferConcurrency'](arg1);
+}
+
+export function ShareTerminal(arg1, arg2) {
+  return window['go']['app']['App']['ShareTerminal'](arg1, arg2);
+}
+
 export function ShowAllEditorWindows() {
   return window['go']['app']['App']['ShowAllEditorWindows']();
 }
 
+export function SignalTerminalProcess(arg1, arg2) {
+  return window['go']['app']['App']['SignalTerminalProcess'](arg1, arg2);
+}
+
+export function StartBatchRun(arg1) {
+  return window['go']['app']['App']['StartBatchRun'](arg1);
+}
+
+export function StartCommand(arg1, arg2, arg3) {
+  return window['go']['app']['App']['StartCommand'](arg1, arg2, arg3);
+}
+
 export function StartEditorServer() {
   return window['go']['app']['App']['StartEditorServer']();
 }
@@ -242,10 +574,22 @@ export function StartLocalTerminalSession(arg1, arg2, arg3, arg4) {
   return window['go']['app']['App']['StartLocalTerminalSession'](arg1, arg2, arg3, arg4);
 }
 
+export function StartLocalTerminalSessionWithProfile(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['app']['App']['StartLocalTerminalSessionWithProfile'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function StartMultiplexedTerminalSession(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['app']['App']['StartMultiplexedTerminalSession'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function StartSync(arg1) {
   return window['go']['app']['App']['StartSync'](arg1);
 }
 
+export function StartTerminalRecording(arg1) {
+  return window['go']['app']['App']['StartTerminalRecording'](arg1);
+}
+
 export function StartTerminalSession(arg1, arg2, arg3) {
   return window['go']['app']['App']['StartTerminalSession'](arg1, arg2, arg3);
 }
@@ -258,14 +602,34 @@ export function StopSync(arg1) {
   return window['go']['app']['App']['StopSync'](arg1);
 }
 
+export function StopTerminalRecording(arg1) {
+  return window['go']['app']['App']['StopTerminalRecording'](arg1);
+}
+
 export function TestSyncConnection(arg1) {
   return window['go']['app']['App']['TestSyncConnection'](arg1);
 }
 
+export function UpdateAutomationScript(arg1) {
+  return window['go']['app']['App']['UpdateAutomationScript'](arg1);
+}
+
+export function UpdateSnippet(arg1) {
+  return window['go']['app']['App']['UpdateSnippet'](arg1);
+}
+
 export function UpdateSyncRule(arg1) {
   return window['go']['app']['App']['UpdateSyncRule'](arg1);
 }
 
+export function UpdateTriggerRule(arg1) {
+  return window['go']['app']['App']['UpdateTriggerRule'](arg1);
+}
+
+export function UploadDirectory(arg1, arg2, arg3) {
+  return window['go']['app']['App']['UploadDirectory'](arg1, arg2, arg3);
+}
+
 export function UploadFile(arg1, arg2, arg3) {
   return window['go']['app']['App']['UploadFile'](arg1, arg2, arg3);
 }
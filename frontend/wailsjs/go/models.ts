@@ -1,5 +1,321 @@
 export namespace app {
 	
+	export class AutomationBranch {
+	    pattern: string;
+	    goto: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutomationBranch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.goto = source["goto"];
+	    }
+	}
+	export class AutomationStep {
+	    type: string;
+	    label: string;
+	    text: string;
+	    enter: boolean;
+	    secret: boolean;
+	    pattern: string;
+	    branches: AutomationBranch[];
+	    timeoutSeconds: number;
+	    onTimeout: string;
+	    durationMs: number;
+	    goto: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutomationStep(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.label = source["label"];
+	        this.text = source["text"];
+	        this.enter = source["enter"];
+	        this.secret = source["secret"];
+	        this.pattern = source["pattern"];
+	        this.branches = this.convertValues(source["branches"], AutomationBranch);
+	        this.timeoutSeconds = source["timeoutSeconds"];
+	        this.onTimeout = source["onTimeout"];
+	        this.durationMs = source["durationMs"];
+	        this.goto = source["goto"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AutomationScript {
+	    id: string;
+	    name: string;
+	    description: string;
+	    steps: AutomationStep[];
+	    createdAt: string;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutomationScript(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.steps = this.convertValues(source["steps"], AutomationStep);
+	        this.createdAt = source["createdAt"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class BatchHostResult {
+	    host: string;
+	    stdout: string;
+	    stderr: string;
+	    exitCode: number;
+	    signal?: string;
+	    timedOut: boolean;
+	    truncated: boolean;
+	    durationMs: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchHostResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.stdout = source["stdout"];
+	        this.stderr = source["stderr"];
+	        this.exitCode = source["exitCode"];
+	        this.signal = source["signal"];
+	        this.timedOut = source["timedOut"];
+	        this.truncated = source["truncated"];
+	        this.durationMs = source["durationMs"];
+	        this.error = source["error"];
+	    }
+	}
+	export class BatchOutputGroup {
+	    stdout: string;
+	    stderr: string;
+	    exitCode: number;
+	    error?: string;
+	    hosts: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchOutputGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.stdout = source["stdout"];
+	        this.stderr = source["stderr"];
+	        this.exitCode = source["exitCode"];
+	        this.error = source["error"];
+	        this.hosts = source["hosts"];
+	    }
+	}
+	export class BatchRun {
+	    id: string;
+	    command: string;
+	    tag?: string;
+	    hosts: string[];
+	    status: string;
+	    startedAt: string;
+	    finishedAt?: string;
+	    results: BatchHostResult[];
+	    groups: BatchOutputGroup[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchRun(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.command = source["command"];
+	        this.tag = source["tag"];
+	        this.hosts = source["hosts"];
+	        this.status = source["status"];
+	        this.startedAt = source["startedAt"];
+	        this.finishedAt = source["finishedAt"];
+	        this.results = this.convertValues(source["results"], BatchHostResult);
+	        this.groups = this.convertValues(source["groups"], BatchOutputGroup);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BatchRunRequest {
+	    hosts: string[];
+	    tag: string;
+	    command: string;
+	    concurrency: number;
+	    timeoutSeconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchRunRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hosts = source["hosts"];
+	        this.tag = source["tag"];
+	        this.command = source["command"];
+	        this.concurrency = source["concurrency"];
+	        this.timeoutSeconds = source["timeoutSeconds"];
+	    }
+	}
+	export class BroadcastFailure {
+	    sessionId: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BroadcastFailure(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sessionId = source["sessionId"];
+	        this.error = source["error"];
+	    }
+	}
+	export class BroadcastMember {
+	    sessionId: string;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BroadcastMember(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sessionId = source["sessionId"];
+	        this.enabled = source["enabled"];
+	    }
+	}
+	export class BroadcastGroup {
+	    id: string;
+	    name: string;
+	    active: boolean;
+	    members: BroadcastMember[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BroadcastGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.active = source["active"];
+	        this.members = this.convertValues(source["members"], BroadcastMember);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class BroadcastResult {
+	    sent: string[];
+	    failed: BroadcastFailure[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BroadcastResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sent = source["sent"];
+	        this.failed = this.convertValues(source["failed"], BroadcastFailure);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class ClipboardData {
 	    files: string[];
 	    operation: string;
@@ -14,12 +330,163 @@ export namespace app {
 	        this.operation = source["operation"];
 	    }
 	}
+	export class CommandHistoryQuery {
+	    host: string;
+	    sessionId: string;
+	    search: string;
+	    failedOnly: boolean;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommandHistoryQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.sessionId = source["sessionId"];
+	        this.search = source["search"];
+	        this.failedOnly = source["failedOnly"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class CommandOptions {
+	    timeoutSeconds: number;
+	    stdin: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommandOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timeoutSeconds = source["timeoutSeconds"];
+	        this.stdin = source["stdin"];
+	    }
+	}
+	export class CommandRecord {
+	    id: string;
+	    sessionId: string;
+	    host: string;
+	    command: string;
+	    cwd: string;
+	    startedAt: string;
+	    finishedAt: string;
+	    durationMs: number;
+	    exitCode?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommandRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.sessionId = source["sessionId"];
+	        this.host = source["host"];
+	        this.command = source["command"];
+	        this.cwd = source["cwd"];
+	        this.startedAt = source["startedAt"];
+	        this.finishedAt = source["finishedAt"];
+	        this.durationMs = source["durationMs"];
+	        this.exitCode = source["exitCode"];
+	    }
+	}
+	export class TransferFileError {
+	    path: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TransferFileError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.error = source["error"];
+	    }
+	}
+	export class DirectoryTransferResult {
+	    target: string;
+	    files: number;
+	    failed: TransferFileError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DirectoryTransferResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.target = source["target"];
+	        this.files = source["files"];
+	        this.failed = this.convertValues(source["failed"], TransferFileError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FileChangeResult {
+	    changed: number;
+	    failed: TransferFileError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FileChangeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.changed = source["changed"];
+	        this.failed = this.convertValues(source["failed"], TransferFileError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class FileInfo {
 	    name: string;
+	    path: string;
 	    size: number;
 	    mode: string;
+	    octal: string;
 	    modTime: string;
 	    isDir: boolean;
+	    type: string;
+	    uid: number;
+	    gid: number;
+	    owner: string;
+	    group: string;
+	    linkTarget?: string;
+	    brokenLink?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new FileInfo(source);
@@ -28,30 +495,141 @@ export namespace app {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
+	        this.path = source["path"];
 	        this.size = source["size"];
 	        this.mode = source["mode"];
+	        this.octal = source["octal"];
 	        this.modTime = source["modTime"];
 	        this.isDir = source["isDir"];
+	        this.type = source["type"];
+	        this.uid = source["uid"];
+	        this.gid = source["gid"];
+	        this.owner = source["owner"];
+	        this.group = source["group"];
+	        this.linkTarget = source["linkTarget"];
+	        this.brokenLink = source["brokenLink"];
+	    }
+	}
+	export class StartupCommand {
+	    command: string;
+	    waitFor: string;
+	    delayMs: number;
+	    timeoutSeconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StartupCommand(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.command = source["command"];
+	        this.waitFor = source["waitFor"];
+	        this.delayMs = source["delayMs"];
+	        this.timeoutSeconds = source["timeoutSeconds"];
 	    }
 	}
-	export class LocalFileInfo {
+	export class HostSettings {
+	    host: string;
+	    multiplexer: string;
+	    multiplexerSession: string;
+	    termType: string;
+	    locale: string;
+	    terminalModes: Record<string, number>;
+	    env: Record<string, string>;
+	    encoding: string;
+	    clipboard: string;
+	    clipboardMaxBytes: number;
+	    tags: string[];
+	    startupCommands: StartupCommand[];
+	    resumeCheck: string;
+	    sftpRequestSize: number;
+	    sftpConcurrency: number;
+	    parallelFiles: number;
+	    preserveAttributes: boolean;
+	    symlinks: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HostSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.multiplexer = source["multiplexer"];
+	        this.multiplexerSession = source["multiplexerSession"];
+	        this.termType = source["termType"];
+	        this.locale = source["locale"];
+	        this.terminalModes = source["terminalModes"];
+	        this.env = source["env"];
+	        this.encoding = source["encoding"];
+	        this.clipboard = source["clipboard"];
+	        this.clipboardMaxBytes = source["clipboardMaxBytes"];
+	        this.tags = source["tags"];
+	        this.startupCommands = this.convertValues(source["startupCommands"], StartupCommand);
+	        this.resumeCheck = source["resumeCheck"];
+	        this.sftpRequestSize = source["sftpRequestSize"];
+	        this.sftpConcurrency = source["sftpConcurrency"];
+	        this.parallelFiles = source["parallelFiles"];
+	        this.preserveAttributes = source["preserveAttributes"];
+	        this.symlinks = source["symlinks"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MultiplexerSession {
 	    name: string;
-	    path: string;
-	    isDir: boolean;
-	    size: number;
-	    modTime: string;
+	    multiplexer: string;
+	    windows: number;
+	    attached: boolean;
+	    created: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new LocalFileInfo(source);
+	        return new MultiplexerSession(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
-	        this.path = source["path"];
-	        this.isDir = source["isDir"];
-	        this.size = source["size"];
-	        this.modTime = source["modTime"];
+	        this.multiplexer = source["multiplexer"];
+	        this.windows = source["windows"];
+	        this.attached = source["attached"];
+	        this.created = source["created"];
+	    }
+	}
+	export class MultiplexerStatus {
+	    hasTmux: boolean;
+	    tmuxVersion: string;
+	    hasScreen: boolean;
+	    screenVersion: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MultiplexerStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hasTmux = source["hasTmux"];
+	        this.tmuxVersion = source["tmuxVersion"];
+	        this.hasScreen = source["hasScreen"];
+	        this.screenVersion = source["screenVersion"];
+	        this.message = source["message"];
 	    }
 	}
 	export class RemoteDepsStatus {
@@ -79,6 +657,8 @@ export namespace app {
 	    user: string;
 	    port: number;
 	    identityFile: string;
+	    setEnv: Record<string, string>;
+	    sendEnv: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new SSHConfigEntry(source);
@@ -92,8 +672,111 @@ export namespace app {
 	        this.user = source["user"];
 	        this.port = source["port"];
 	        this.identityFile = source["identityFile"];
+	        this.setEnv = source["setEnv"];
+	        this.sendEnv = source["sendEnv"];
+	    }
+	}
+	export class ShareOptions {
+	    readWrite: boolean;
+	    includeScrollback: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ShareOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.readWrite = source["readWrite"];
+	        this.includeScrollback = source["includeScrollback"];
+	    }
+	}
+	export class ShareServerConfig {
+	    bindAddress: string;
+	    port: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ShareServerConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bindAddress = source["bindAddress"];
+	        this.port = source["port"];
+	    }
+	}
+	export class Snippet {
+	    id: string;
+	    name: string;
+	    command: string;
+	    description: string;
+	    tags: string[];
+	    hosts: string[];
+	    createdAt: string;
+	    updatedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Snippet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.command = source["command"];
+	        this.description = source["description"];
+	        this.tags = source["tags"];
+	        this.hosts = source["hosts"];
+	        this.createdAt = source["createdAt"];
+	        this.updatedAt = source["updatedAt"];
+	    }
+	}
+	export class SnippetImportResult {
+	    added: number;
+	    updated: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnippetImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.added = source["added"];
+	        this.updated = source["updated"];
+	    }
+	}
+	export class SnippetQuery {
+	    search: string;
+	    tag: string;
+	    host: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnippetQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.search = source["search"];
+	        this.tag = source["tag"];
+	        this.host = source["host"];
 	    }
 	}
+	export class SnippetVariable {
+	    name: string;
+	    default: string;
+	    hasDefault: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnippetVariable(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.default = source["default"];
+	        this.hasDefault = source["hasDefault"];
+	    }
+	}
+	
 	export class SyncRule {
 	    id: string;
 	    serverName: string;
@@ -124,6 +807,213 @@ export namespace app {
 	        this.error = source["error"];
 	    }
 	}
+	export class TerminalEncoding {
+	    name: string;
+	    label: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TerminalEncoding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.label = source["label"];
+	    }
+	}
+	export class TerminalProcess {
+	    pid: number;
+	    name: string;
+	    shellPid: number;
+	    isShell: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TerminalProcess(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pid = source["pid"];
+	        this.name = source["name"];
+	        this.shellPid = source["shellPid"];
+	        this.isShell = source["isShell"];
+	    }
+	}
+	export class TerminalProfile {
+	    id: string;
+	    name: string;
+	    shell: string;
+	    args: string[];
+	    loginShell: boolean;
+	    env: Record<string, string>;
+	    unsetEnv: string[];
+	    locale: string;
+	    term: string;
+	    workingDir: string;
+	    isDefault: boolean;
+	    startupCommands: StartupCommand[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TerminalProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.shell = source["shell"];
+	        this.args = source["args"];
+	        this.loginShell = source["loginShell"];
+	        this.env = source["env"];
+	        this.unsetEnv = source["unsetEnv"];
+	        this.locale = source["locale"];
+	        this.term = source["term"];
+	        this.workingDir = source["workingDir"];
+	        this.isDefault = source["isDefault"];
+	        this.startupCommands = this.convertValues(source["startupCommands"], StartupCommand);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TerminalShare {
+	    id: string;
+	    sessionId: string;
+	    url: string;
+	    readWrite: boolean;
+	    includeScrollback: boolean;
+	    viewers: number;
+	    createdAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TerminalShare(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.sessionId = source["sessionId"];
+	        this.url = source["url"];
+	        this.readWrite = source["readWrite"];
+	        this.includeScrollback = source["includeScrollback"];
+	        this.viewers = source["viewers"];
+	        this.createdAt = source["createdAt"];
+	    }
+	}
+	
+	export class TransferJob {
+	    id: string;
+	    sessionId: string;
+	    direction: string;
+	    source: string;
+	    targetDir: string;
+	    target: string;
+	    isDir: boolean;
+	    status: string;
+	    totalBytes: number;
+	    transferred: number;
+	    error?: string;
+	    createdAt: string;
+	    finishedAt?: string;
+	    targetSessionId?: string;
+	    direct?: boolean;
+	    failedFiles?: TransferFileError[];
+	    durationMs: number;
+	    bytesPerSecond: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TransferJob(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.sessionId = source["sessionId"];
+	        this.direction = source["direction"];
+	        this.source = source["source"];
+	        this.targetDir = source["targetDir"];
+	        this.target = source["target"];
+	        this.isDir = source["isDir"];
+	        this.status = source["status"];
+	        this.totalBytes = source["totalBytes"];
+	        this.transferred = source["transferred"];
+	        this.error = source["error"];
+	        this.createdAt = source["createdAt"];
+	        this.finishedAt = source["finishedAt"];
+	        this.targetSessionId = source["targetSessionId"];
+	        this.direct = source["direct"];
+	        this.failedFiles = this.convertValues(source["failedFiles"], TransferFileError);
+	        this.durationMs = source["durationMs"];
+	        this.bytesPerSecond = source["bytesPerSecond"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TriggerRule {
+	    id: string;
+	    name: string;
+	    pattern: string;
+	    caseInsensitive: boolean;
+	    sessionId: string;
+	    host: string;
+	    actions: string[];
+	    response: string;
+	    cooldownSeconds: number;
+	    enabled: boolean;
+	    matchCount: number;
+	    lastMatchAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TriggerRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.pattern = source["pattern"];
+	        this.caseInsensitive = source["caseInsensitive"];
+	        this.sessionId = source["sessionId"];
+	        this.host = source["host"];
+	        this.actions = source["actions"];
+	        this.response = source["response"];
+	        this.cooldownSeconds = source["cooldownSeconds"];
+	        this.enabled = source["enabled"];
+	        this.matchCount = source["matchCount"];
+	        this.lastMatchAt = source["lastMatchAt"];
+	    }
+	}
 
 }
 
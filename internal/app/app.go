@@ -49,6 +49,13 @@ func (a *App) Startup(ctx context.Context) {
 	initSyncManager(a, ctx)
 }
 
+// FlushPendingSaves writes state still waiting on a delayed save.
+// Called on app shutdown.
+func FlushPendingSaves() {
+	flushTriggers()
+	flushCommandHistory()
+}
+
 // GetSSHConfig is exposed to the frontend via Wails
 func (a *App) GetSSHConfig() []SSHConfigEntry {
 	return GetSSHConfig()
@@ -87,6 +94,22 @@ func getSettingsPath() (string, error) {
 	return filepath.Join(appConfigDir, "settings.json"), nil
 }
 
+// getAppConfigPath returns the path to a file in the app config directory,
+// creating the directory if needed
+func getAppConfigPath(fileName string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config dir: %v", err)
+	}
+
+	appConfigDir := filepath.Join(configDir, "xterm-file-manager")
+	if err := os.MkdirAll(appConfigDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %v", err)
+	}
+
+	return filepath.Join(appConfigDir, fileName), nil
+}
+
 // GetTerminalSettings returns the current terminal settings
 func (a *App) GetTerminalSettings() (string, error) {
 	settingsPath, err := getSettingsPath()
@@ -387,7 +410,7 @@ func (a *App) ReadRemoteFile(sessionID string, remotePath string) (string, error
 		}
 	}
 
-	return string(content), nil
+	return decodeFileContent(remoteFileEncoding(sessionID), content)
 }
 
 // WriteRemoteFile writes content to a remote file via SFTP
@@ -398,6 +421,11 @@ func (a *App) WriteRemoteFile(sessionID string, remotePath string, content strin
 	}
 	// SFTP client is managed by pool, do not close here
 
+	data, err := encodeFileContent(remoteFileEncoding(sessionID), content)
+	if err != nil {
+		return err
+	}
+
 	// Resolve ~ to home directory
 	originalPath := remotePath
 	remotePath = resolveRemotePath(sftpClient, remotePath)
@@ -410,7 +438,7 @@ func (a *App) WriteRemoteFile(sessionID string, remotePath string, content strin
 	defer file.Close()
 
 	// Write content
-	_, err = file.Write([]byte(content))
+	_, err = file.Write(data)
 	if err != nil {
 		return fmt.Errorf("failed to write to remote file: %v", err)
 	}
@@ -0,0 +1,519 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Batch runner constants
+const (
+	// DefaultBatchConcurrency is how many hosts a batch run works on at once
+	DefaultBatchConcurrency = 8
+	// MaxBatchConcurrency caps the concurrency a run may ask for
+	MaxBatchConcurrency = 64
+	// MaxBatchOutputBytes caps the stdout and stderr kept per host
+	MaxBatchOutputBytes = 64 * 1024
+	// MaxBatchRuns is the number of finished runs kept in batch-runs.json
+	MaxBatchRuns = 50
+)
+
+// BatchRunRequest selects hosts by name and/or tag and the command to run on them
+type BatchRunRequest struct {
+	Hosts          []string `json:"hosts"`
+	Tag            string   `json:"tag"` // Adds every host whose settings have this tag
+	Command        string   `json:"command"`
+	Concurrency    int      `json:"concurrency"`    // 0 means DefaultBatchConcurrency
+	TimeoutSeconds int      `json:"timeoutSeconds"` // Per host, 0 means no timeout
+}
+
+// BatchHostResult is the outcome of a batch command on one host
+type BatchHostResult struct {
+	Host       string `json:"host"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	ExitCode   int    `json:"exitCode"` // -1 if the command didn't run or was killed
+	Signal     string `json:"signal,omitempty"`
+	TimedOut   bool   `json:"timedOut"`
+	Truncated  bool   `json:"truncated"` // Output exceeded MaxBatchOutputBytes
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"` // Connection or execution failure
+}
+
+// BatchOutputGroup is a set of hosts that produced identical results
+type BatchOutputGroup struct {
+	Stdout   string   `json:"stdout"`
+	Stderr   string   `json:"stderr"`
+	ExitCode int      `json:"exitCode"`
+	Error    string   `json:"error,omitempty"`
+	Hosts    []string `json:"hosts"`
+}
+
+// BatchRun is a command run across several hosts
+type BatchRun struct {
+	ID         string             `json:"id"`
+	Command    string             `json:"command"`
+	Tag        string             `json:"tag,omitempty"`
+	Hosts      []string           `json:"hosts"`
+	Status     string             `json:"status"` // "running", "completed" or "cancelled"
+	StartedAt  string             `json:"startedAt"`
+	FinishedAt string             `json:"finishedAt,omitempty"`
+	Results    []BatchHostResult  `json:"results"`
+	Groups     []BatchOutputGroup `json:"groups"`
+}
+
+// batchRuns holds finished runs in memory, backed by batch-runs.json, and the
+// cancel functions of runs in progress
+var batchRuns = struct {
+	mu      sync.Mutex
+	loaded  bool
+	runs    []BatchRun
+	running map[string]context.CancelFunc
+}{
+	running: make(map[string]context.CancelFunc),
+}
+
+// loadBatchRunsLocked reads batch-runs.json once. Caller must hold the lock.
+func loadBatchRunsLocked() {
+	if batchRuns.loaded {
+		return
+	}
+	batchRuns.loaded = true
+
+	runsPath, err := getAppConfigPath("batch-runs.json")
+	if err != nil {
+		log.Printf("⚠️ [Batch] Failed to get runs path: %v", err)
+		return
+	}
+
+	data, err := os.ReadFile(runsPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ [Batch] Failed to read batch runs: %v", err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(data, &batchRuns.runs); err != nil {
+		log.Printf("⚠️ [Batch] Failed to parse batch runs: %v", err)
+		batchRuns.runs = nil
+	}
+}
+
+// saveBatchRunsLocked writes the finished runs to disk. Caller must hold the lock.
+func saveBatchRunsLocked() error {
+	runsPath, err := getAppConfigPath("batch-runs.json")
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(batchRuns.runs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch runs: %v", err)
+	}
+	// Host output can include secrets, so only the user may read the file,
+	// including one saved readable by everyone before
+	if err := os.Chmod(runsPath, 0600); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to restrict batch runs: %v", err)
+	}
+	if err := os.WriteFile(runsPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write batch runs: %v", err)
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first max bytes written to it
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// String returns the kept output, dropping a UTF-8 character cut at the limit
+func (b *limitedBuffer) String() string {
+	return strings.ToValidUTF8(b.buf.String(), "")
+}
+
+// resolveBatchHosts returns the SSH config entries a request targets, in the
+// order of ~/.ssh/config, and the selected hosts that aren't in the config.
+// tagged holds the hosts carrying the request's tag.
+func resolveBatchHosts(request BatchRunRequest, configs []SSHConfigEntry, tagged []string) ([]SSHConfigEntry, []string, error) {
+	wanted := make(map[string]bool)
+	for _, host := range request.Hosts {
+		wanted[host] = true
+	}
+	for _, host := range tagged {
+		wanted[host] = true
+	}
+	if len(wanted) == 0 {
+		if request.Tag != "" {
+			return nil, nil, fmt.Errorf("no hosts are tagged %q", request.Tag)
+		}
+		return nil, nil, fmt.Errorf("no hosts selected")
+	}
+
+	var result []SSHConfigEntry
+	for _, config := range configs {
+		if wanted[config.Host] {
+			result = append(result, config)
+			delete(wanted, config.Host)
+		}
+	}
+	var missing []string
+	for host := range wanted {
+		missing = append(missing, host)
+	}
+	sort.Strings(missing)
+	return result, missing, nil
+}
+
+// groupBatchResults groups hosts with identical output and exit status,
+// largest group first
+func groupBatchResults(results []BatchHostResult) []BatchOutputGroup {
+	groups := []BatchOutputGroup{}
+	index := make(map[string]int)
+	for _, r := range results {
+		key := strings.Join([]string{strconv.Itoa(r.ExitCode), r.Error, r.Stdout, r.Stderr}, "\x00")
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, BatchOutputGroup{
+				Stdout:   r.Stdout,
+				Stderr:   r.Stderr,
+				ExitCode: r.ExitCode,
+				Error:    r.Error,
+			})
+		}
+		groups[i].Hosts = append(groups[i].Hosts, r.Host)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return len(groups[i].Hosts) > len(groups[j].Hosts) })
+	return groups
+}
+
+// findHostSession returns a connected SSH session for a host, if one is open
+func findHostSession(host string) string {
+	sshManager.mu.RLock()
+	defer sshManager.mu.RUnlock()
+	for id, session := range sshManager.sessions {
+		if session.Config.Host == host && session.Connected {
+			return id
+		}
+	}
+	return ""
+}
+
+// runBatchHost runs the command on one host, reusing an open connection or
+// making a temporary one of the run's own
+func (a *App) runBatchHost(ctx context.Context, runID string, config SSHConfigEntry, command string, timeout time.Duration) (result BatchHostResult) {
+	result = BatchHostResult{Host: config.Host, ExitCode: -1}
+	start := time.Now()
+	// Named result, so the duration lands in what's returned
+	defer func() { result.DurationMs = time.Since(start).Milliseconds() }()
+
+	if ctx.Err() != nil {
+		result.Error = "cancelled"
+		return result
+	}
+
+	sessionID := findHostSession(config.Host)
+	if sessionID == "" {
+		// Unique to the run, so cleaning up can't close anyone else's session
+		sessionID = runID + "-" + config.Host
+		if err := connectSSHSession(sessionID, config); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		defer a.DisconnectSSH(sessionID)
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	stdout := &limitedBuffer{max: MaxBatchOutputBytes}
+	stderr := &limitedBuffer{max: MaxBatchOutputBytes}
+	exit, err := runRemoteCommand(ctx, sessionID, command, "", stdout, stderr)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.Truncated = stdout.truncated || stderr.truncated
+	result.ExitCode = exit.ExitCode
+	result.Signal = exit.Signal
+	result.TimedOut = exit.TimedOut
+	result.Error = exit.Error
+	if exit.Cancelled {
+		result.Error = "cancelled"
+	}
+	return result
+}
+
+// StartBatchRun runs a command on several hosts in the background and returns
+// the run ID. Each host's result arrives as a batch:host-result event and the
+// whole run, with grouped outputs, as batch:finished.
+func (a *App) StartBatchRun(request BatchRunRequest) (string, error) {
+	if strings.TrimSpace(request.Command) == "" {
+		return "", fmt.Errorf("command is required")
+	}
+	if request.TimeoutSeconds < 0 {
+		return "", fmt.Errorf("timeout must not be negative")
+	}
+	concurrency := request.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	if concurrency > MaxBatchConcurrency {
+		concurrency = MaxBatchConcurrency
+	}
+
+	var tagged []string
+	if request.Tag != "" {
+		tagged = hostsWithTag(request.Tag)
+	}
+	configs, missing, err := resolveBatchHosts(request, GetSSHConfig(), tagged)
+	if err != nil {
+		return "", err
+	}
+
+	run := BatchRun{
+		ID:        fmt.Sprintf("batch-%d", time.Now().UnixNano()),
+		Command:   request.Command,
+		Tag:       request.Tag,
+		Status:    "running",
+		StartedAt: time.Now().Format(time.RFC3339),
+	}
+	for _, config := range configs {
+		run.Hosts = append(run.Hosts, config.Host)
+	}
+	run.Hosts = append(run.Hosts, missing...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batchRuns.mu.Lock()
+	batchRuns.running[run.ID] = cancel
+	batchRuns.mu.Unlock()
+
+	log.Printf("🚀 [Batch] Run %s on %d host(s), concurrency %d: %s", run.ID, len(run.Hosts), concurrency, request.Command)
+
+	go func() {
+		results := make([]BatchHostResult, len(run.Hosts))
+		var completed int
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, concurrency)
+		timeout := time.Duration(request.TimeoutSeconds) * time.Second
+
+		report := func(i int, result BatchHostResult) {
+			results[i] = result
+
+			mu.Lock()
+			completed++
+			done := completed
+			mu.Unlock()
+
+			if a.ctx != nil {
+				wailsRuntime.EventsEmit(a.ctx, "batch:host-result", map[string]interface{}{
+					"runId":     run.ID,
+					"result":    result,
+					"completed": done,
+					"total":     len(run.Hosts),
+				})
+			}
+		}
+
+		// Hosts no longer in ~/.ssh/config fail on their own
+		for i, host := range missing {
+			report(len(configs)+i, BatchHostResult{Host: host, ExitCode: -1, Error: "SSH host not found in config"})
+		}
+		for i, config := range configs {
+			wg.Add(1)
+			go func(i int, config SSHConfigEntry) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				report(i, a.runBatchHost(ctx, run.ID, config, request.Command, timeout))
+			}(i, config)
+		}
+		wg.Wait()
+
+		run.Status = "completed"
+		if ctx.Err() != nil {
+			run.Status = "cancelled"
+		}
+		cancel()
+		run.FinishedAt = time.Now().Format(time.RFC3339)
+		run.Results = results
+		run.Groups = groupBatchResults(results)
+
+		batchRuns.mu.Lock()
+		delete(batchRuns.running, run.ID)
+		loadBatchRunsLocked()
+		batchRuns.runs = append(batchRuns.runs, run)
+		if over := len(batchRuns.runs) - MaxBatchRuns; over > 0 {
+			batchRuns.runs = append([]BatchRun(nil), batchRuns.runs[over:]...)
+		}
+		if err := saveBatchRunsLocked(); err != nil {
+			log.Printf("⚠️ [Batch] %v", err)
+		}
+		batchRuns.mu.Unlock()
+
+		log.Printf("✅ [Batch] Run %s %s: %d distinct result(s)", run.ID, run.Status, len(run.Groups))
+		if a.ctx != nil {
+			wailsRuntime.EventsEmit(a.ctx, "batch:finished", run)
+		}
+	}()
+
+	return run.ID, nil
+}
+
+// CancelBatchRun stops a batch run. Commands in progress are killed and hosts
+// not started yet are reported as cancelled.
+func (a *App) CancelBatchRun(runID string) error {
+	batchRuns.mu.Lock()
+	cancel, exists := batchRuns.running[runID]
+	batchRuns.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("batch run not running: %s", runID)
+	}
+	log.Printf("🛑 [Batch] Cancelling run %s", runID)
+	cancel()
+	return nil
+}
+
+// GetBatchRuns returns saved batch runs, newest first
+func (a *App) GetBatchRuns() []BatchRun {
+	batchRuns.mu.Lock()
+	defer batchRuns.mu.Unlock()
+	loadBatchRunsLocked()
+
+	result := make([]BatchRun, 0, len(batchRuns.runs))
+	for i := len(batchRuns.runs) - 1; i >= 0; i-- {
+		result = append(result, batchRuns.runs[i])
+	}
+	return result
+}
+
+// getBatchRun returns a saved run by ID
+func getBatchRun(runID string) (BatchRun, error) {
+	batchRuns.mu.Lock()
+	defer batchRuns.mu.Unlock()
+	loadBatchRunsLocked()
+
+	for _, run := range batchRuns.runs {
+		if run.ID == runID {
+			return run, nil
+		}
+	}
+	return BatchRun{}, fmt.Errorf("batch run not found: %s", runID)
+}
+
+// DeleteBatchRun removes a saved run
+func (a *App) DeleteBatchRun(runID string) error {
+	batchRuns.mu.Lock()
+	defer batchRuns.mu.Unlock()
+	loadBatchRunsLocked()
+
+	for i, run := range batchRuns.runs {
+		if run.ID == runID {
+			batchRuns.runs = append(batchRuns.runs[:i], batchRuns.runs[i+1:]...)
+			return saveBatchRunsLocked()
+		}
+	}
+	return fmt.Errorf("batch run not found: %s", runID)
+}
+
+// formatBatchRun renders a run as "json" (the whole run) or "csv" (one row per host)
+func formatBatchRun(run BatchRun, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(run, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal batch run: %v", err)
+		}
+		return data, nil
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"host", "exit_code", "signal", "timed_out", "duration_ms", "error", "stdout", "stderr"})
+		for _, r := range run.Results {
+			w.Write([]string{
+				r.Host,
+				strconv.Itoa(r.ExitCode),
+				r.Signal,
+				strconv.FormatBool(r.TimedOut),
+				strconv.FormatInt(r.DurationMs, 10),
+				r.Error,
+				r.Stdout,
+				r.Stderr,
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, fmt.Errorf("failed to write CSV: %v", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s (must be 'json' or 'csv')", format)
+	}
+}
+
+// ExportBatchRun saves a run as JSON or CSV to a file chosen in a save dialog.
+// Returns the file path, or "" if the dialog was cancelled.
+func (a *App) ExportBatchRun(runID string, format string) (string, error) {
+	run, err := getBatchRun(runID)
+	if err != nil {
+		return "", err
+	}
+	data, err := formatBatchRun(run, format)
+	if err != nil {
+		return "", err
+	}
+	if a.ctx == nil {
+		return "", fmt.Errorf("no window available for the save dialog")
+	}
+
+	path, err := wailsRuntime.SaveFileDialog(a.ctx, wailsRuntime.SaveDialogOptions{
+		Title:           "Export batch run",
+		DefaultFilename: fmt.Sprintf("%s.%s", run.ID, format),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %v", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write export: %v", err)
+	}
+	log.Printf("💾 [Batch] Exported run %s to %s", runID, path)
+	return path, nil
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/csv"
+	"net"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// newTestSSHSession registers sessionID as a connected session to an
+// in-process SSH server that runs exec requests locally with sh -c
+func newTestSSHSession(t *testing.T, sessionID string, host string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := &ssh.ServerConfig{NoClientAuth: true}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		if serverConn, err := listener.Accept(); err == nil {
+			serveTestSSH(serverConn, serverConfig)
+		}
+	}()
+
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, chans, reqs, err := ssh.NewClientConn(clientConn, listener.Addr().String(), &ssh.ClientConfig{
+		User:            "test",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := ssh.NewClient(conn, chans, reqs)
+
+	sshManager.mu.Lock()
+	sshManager.sessions[sessionID] = &SSHSession{ID: sessionID, Config: SSHConfigEntry{Host: host}, Client: client, Connected: true}
+	sshManager.mu.Unlock()
+	t.Cleanup(func() {
+		sshManager.mu.Lock()
+		delete(sshManager.sessions, sessionID)
+		sshManager.mu.Unlock()
+		client.Close()
+	})
+}
+
+// serveTestSSH runs each exec request with sh -c and reports its exit status
+// or signal. A signal request or a closed channel kills the command.
+func serveTestSSH(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go serveTestSSHChannel(channel, requests)
+	}
+}
+
+func serveTestSSHChannel(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	var cmd *exec.Cmd
+	done := make(chan struct{})
+	for {
+		select {
+		case req, ok := <-requests:
+			if !ok {
+				if cmd != nil {
+					cmd.Process.Kill()
+				}
+				return
+			}
+			switch {
+			case req.Type == "exec" && cmd == nil:
+				var payload struct{ Command string }
+				ssh.Unmarshal(req.Payload, &payload)
+				cmd = exec.Command("sh", "-c", payload.Command)
+				cmd.Stdin, cmd.Stdout, cmd.Stderr = channel, channel, channel.Stderr()
+				// Children may keep the output open after sh is killed
+				cmd.WaitDelay = 100 * time.Millisecond
+				if err := cmd.Start(); err != nil {
+					req.Reply(false, nil)
+					return
+				}
+				req.Reply(true, nil)
+				go func() {
+					cmd.Wait()
+					close(done)
+				}()
+			case req.Type == "signal" && cmd != nil:
+				cmd.Process.Kill()
+			default:
+				if req.WantReply {
+					req.Reply(false, nil)
+				}
+			}
+		case <-done:
+			if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				channel.SendRequest("exit-signal", false, ssh.Marshal(struct {
+					Signal     string
+					CoreDumped bool
+					Message    string
+					Lang       string
+				}{Signal: "KILL"}))
+			} else {
+				status := make([]byte, 4)
+				binary.BigEndian.PutUint32(status, uint32(cmd.ProcessState.ExitCode()))
+				channel.SendRequest("exit-status", false, status)
+			}
+			return
+		}
+	}
+}
+
+func TestResolveBatchHosts(t *testing.T) {
+	configs := []SSHConfigEntry{{Host: "web1"}, {Host: "web2"}, {Host: "db1"}}
+
+	got, missing, err := resolveBatchHosts(BatchRunRequest{Hosts: []string{"db1"}, Tag: "web"}, configs, []string{"web2", "web1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Host != "web1" || got[2].Host != "db1" || len(missing) != 0 {
+		t.Errorf("Expected hosts in config order, got %+v", got)
+	}
+
+	// A stale host doesn't stop the others
+	got, missing, err = resolveBatchHosts(BatchRunRequest{Tag: "web"}, configs, []string{"web1", "old-web", "web2"})
+	if err != nil || len(got) != 2 || len(missing) != 1 || missing[0] != "old-web" {
+		t.Errorf("Expected old-web to be reported missing, got %+v %v %v", got, missing, err)
+	}
+	if _, _, err := resolveBatchHosts(BatchRunRequest{Tag: "empty"}, configs, nil); err == nil {
+		t.Errorf("Expected an error for a tag without hosts")
+	}
+}
+
+func TestGroupBatchResultsAndCSV(t *testing.T) {
+	results := []BatchHostResult{
+		{Host: "a", Stdout: "active\n"},
+		{Host: "b", Stdout: "inactive\n", ExitCode: 3},
+		{Host: "c", Stdout: "active\n"},
+	}
+
+	groups := groupBatchResults(results)
+	if len(groups) != 2 || len(groups[0].Hosts) != 2 || groups[0].Hosts[1] != "c" || groups[1].ExitCode != 3 {
+		t.Errorf("Unexpected groups: %+v", groups)
+	}
+
+	data, err := formatBatchRun(BatchRun{Results: results}, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(rows) != 4 || rows[2][0] != "b" || rows[2][1] != "3" || rows[2][6] != "inactive\n" {
+		t.Errorf("Unexpected CSV: %q %v", rows, err)
+	}
+}
+
+func TestRunBatchHostDuration(t *testing.T) {
+	newTestSSHSession(t, "batch-duration-test", "sleepy")
+	a := &App{}
+
+	result := a.runBatchHost(context.Background(), "batch-test", SSHConfigEntry{Host: "sleepy"}, "sleep 0.05", 0)
+	if result.Error != "" || result.ExitCode != 0 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.DurationMs <= 0 {
+		t.Errorf("Expected a duration for a command that sleeps, got %d", result.DurationMs)
+	}
+}
@@ -44,6 +44,22 @@ int copyFilesToPasteboard(const char** paths, int count) {
         return ok ? 0 : -1;
     }
 }
+
+// copyTextToPasteboard writes a UTF-8 string to the macOS system pasteboard.
+// Returns 0 on success, -1 on error.
+int copyTextToPasteboard(const char* text) {
+    @autoreleasepool {
+        NSString *str = [NSString stringWithUTF8String:text];
+        if (str == nil) {
+            return -1;
+        }
+
+        NSPasteboard *pb = [NSPasteboard generalPasteboard];
+        [pb clearContents];
+        BOOL ok = [pb setString:str forType:NSPasteboardTypeString];
+        return ok ? 0 : -1;
+    }
+}
 */
 import "C"
 import (
@@ -77,3 +93,14 @@ func copyLocalFilesToSystemClipboard(paths []string) error {
 
 	return nil
 }
+
+// copyTextToSystemClipboard writes text to the macOS system pasteboard
+func copyTextToSystemClipboard(text string) error {
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	if C.copyTextToPasteboard(cText) != 0 {
+		return fmt.Errorf("failed to copy text to system clipboard")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+//go:build !darwin && !windows
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// unixClipboardCommand returns a command that copies its stdin to the
+// clipboard as text: wl-copy on Wayland, else xclip or xsel on X11
+func unixClipboardCommand() (*exec.Cmd, error) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if path, err := exec.LookPath("wl-copy"); err == nil {
+			return exec.Command(path), nil
+		}
+	}
+	if path, err := exec.LookPath("xclip"); err == nil {
+		return exec.Command(path, "-selection", "clipboard"), nil
+	}
+	if path, err := exec.LookPath("xsel"); err == nil {
+		return exec.Command(path, "--clipboard", "--input"), nil
+	}
+	return nil, fmt.Errorf("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+}
+
+// copyTextToSystemClipboard writes text to the clipboard.
+// xclip and wl-copy fork to keep owning the selection, so their output is not
+// captured: waiting on inherited pipes would block until the selection changes.
+func copyTextToSystemClipboard(text string) error {
+	cmd, err := unixClipboardCommand()
+	if err != nil {
+		return fmt.Errorf("failed to copy text to clipboard: %v", err)
+	}
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to copy text to clipboard: %s failed: %v", cmd.Path, err)
+	}
+	return nil
+}
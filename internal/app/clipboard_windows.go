@@ -38,3 +38,18 @@ func copyLocalFilesToSystemClipboard(paths []string) error {
 
 	return nil
 }
+
+// copyTextToSystemClipboard writes text to the Windows system clipboard.
+// The text is piped through stdin as UTF-8 so it needs no quoting.
+func copyTextToSystemClipboard(text string) error {
+	psCmd := "[Console]::InputEncoding = [Text.Encoding]::UTF8; Set-Clipboard -Value ([Console]::In.ReadToEnd())"
+
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", psCmd)
+	cmd.Stdin = strings.NewReader(text)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to copy text to clipboard: %v (output: %s)", err, string(output))
+	}
+
+	return nil
+}
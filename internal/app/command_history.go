@@ -0,0 +1,464 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Command history constants
+const (
+	// MaxCommandHistory is the number of command records kept across all hosts
+	MaxCommandHistory = 5000
+	// MaxCommandInputCapture caps the echoed input buffered between prompt
+	// end and command start (used when the shell doesn't send OSC 633;E)
+	MaxCommandInputCapture = 4 * 1024
+	// LongCommandThreshold is the duration after which a finished command is
+	// reported via terminal:command-finished even if it succeeded
+	LongCommandThreshold = 10 * time.Second
+	// CommandHistorySaveDelay batches history writes during bursts of commands
+	CommandHistorySaveDelay = 2 * time.Second
+)
+
+// CommandRecord is a single command run in a terminal, as reported by
+// OSC 133 semantic prompt markers
+type CommandRecord struct {
+	ID         string `json:"id"`
+	SessionID  string `json:"sessionId"`
+	Host       string `json:"host"` // SSH host alias, or "local"
+	Command    string `json:"command"`
+	Cwd        string `json:"cwd"`
+	StartedAt  string `json:"startedAt"`
+	FinishedAt string `json:"finishedAt"`
+	DurationMs int64  `json:"durationMs"`
+	ExitCode   *int   `json:"exitCode"` // nil if the shell didn't report one
+}
+
+// CommandHistoryQuery filters GetCommandHistory results. Empty fields match everything.
+type CommandHistoryQuery struct {
+	Host       string `json:"host"`
+	SessionID  string `json:"sessionId"`
+	Search     string `json:"search"` // case-insensitive substring of command or cwd
+	FailedOnly bool   `json:"failedOnly"`
+	Limit      int    `json:"limit"` // 0 means no limit
+}
+
+// Semantic prompt states tracked per terminal session
+const (
+	promptStateIdle    = iota // no markers seen yet, or output of a command without D
+	promptStatePrompt         // between 133;A and 133;B (prompt being drawn)
+	promptStateInput          // between 133;B and 133;C (user typing)
+	promptStateRunning        // between 133;C and 133;D
+)
+
+// commandTracker holds the semantic prompt state of one terminal session.
+// Guarded by TerminalSession.shellMu.
+type commandTracker struct {
+	state    int
+	input    strings.Builder // echoed input captured in promptStateInput
+	explicit string          // command text from OSC 633;E
+	current  *CommandRecord
+	started  time.Time
+}
+
+// commandHistory stores finished commands in memory, backed by command-history.json
+var commandHistory = struct {
+	mu        sync.Mutex
+	loaded    bool
+	records   []CommandRecord
+	saveTimer *time.Timer
+}{}
+
+// loadCommandHistoryLocked reads command-history.json once. Caller must hold the lock.
+func loadCommandHistoryLocked() {
+	if commandHistory.loaded {
+		return
+	}
+	commandHistory.loaded = true
+
+	historyPath, err := getAppConfigPath("command-history.json")
+	if err != nil {
+		log.Printf("⚠️ [History] Failed to get history path: %v", err)
+		return
+	}
+
+	data, err := os.ReadFile(historyPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ [History] Failed to read command history: %v", err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(data, &commandHistory.records); err != nil {
+		log.Printf("⚠️ [History] Failed to parse command history: %v", err)
+		commandHistory.records = nil
+	}
+}
+
+// saveCommandHistoryLocked writes the history to command-history.json. Caller must hold the lock.
+func saveCommandHistoryLocked() error {
+	data, err := json.Marshal(commandHistory.records)
+	if err != nil {
+		return fmt.Errorf("failed to marshal command history: %v", err)
+	}
+	historyPath, err := getAppConfigPath("command-history.json")
+	if err != nil {
+		return err
+	}
+	// Command lines can carry passwords and tokens, so only the user may
+	// read the file, including one saved readable by everyone before
+	if err := os.Chmod(historyPath, 0600); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to restrict command history: %v", err)
+	}
+	if err := os.WriteFile(historyPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write command history: %v", err)
+	}
+	return nil
+}
+
+// scheduleCommandHistorySaveLocked writes the history to disk after
+// CommandHistorySaveDelay. Caller must hold the lock.
+func scheduleCommandHistorySaveLocked() {
+	if commandHistory.saveTimer != nil {
+		return
+	}
+	commandHistory.saveTimer = time.AfterFunc(CommandHistorySaveDelay, func() {
+		commandHistory.mu.Lock()
+		defer commandHistory.mu.Unlock()
+		commandHistory.saveTimer = nil
+		if err := saveCommandHistoryLocked(); err != nil {
+			log.Printf("⚠️ [History] Failed to save command history: %v", err)
+		}
+	})
+}
+
+// flushCommandHistory writes commands still waiting on a scheduled save
+func flushCommandHistory() {
+	commandHistory.mu.Lock()
+	defer commandHistory.mu.Unlock()
+	if commandHistory.saveTimer == nil {
+		return
+	}
+	commandHistory.saveTimer.Stop()
+	commandHistory.saveTimer = nil
+	if err := saveCommandHistoryLocked(); err != nil {
+		log.Printf("⚠️ [History] Failed to save command history: %v", err)
+	}
+}
+
+// addCommandRecord appends a finished command to the history, dropping the oldest beyond the cap
+func addCommandRecord(record CommandRecord) {
+	commandHistory.mu.Lock()
+	defer commandHistory.mu.Unlock()
+	loadCommandHistoryLocked()
+
+	commandHistory.records = append(commandHistory.records, record)
+	if over := len(commandHistory.records) - MaxCommandHistory; over > 0 {
+		commandHistory.records = append([]CommandRecord(nil), commandHistory.records[over:]...)
+	}
+	scheduleCommandHistorySaveLocked()
+}
+
+// handleMarker advances the semantic prompt state for an OSC 133 (or VS Code
+// 633) marker. Returns the command that finished, if any.
+// Caller must hold termSession.shellMu.
+func (t *commandTracker) handleMarker(termSession *TerminalSession, marker string, seq oscSequence) *CommandRecord {
+	switch marker {
+	case "A":
+		// A new prompt without D means the shell doesn't report exit codes
+		t.state = promptStatePrompt
+		return t.finish(nil)
+	case "B":
+		t.state = promptStateInput
+		t.input.Reset()
+		t.explicit = ""
+	case "C":
+		command := t.explicit
+		if command == "" {
+			command = cleanEchoedCommand(t.input.String())
+		}
+		t.input.Reset()
+		t.explicit = ""
+		t.started = time.Now()
+		t.current = &CommandRecord{
+			ID:        fmt.Sprintf("cmd-%d", t.started.UnixNano()),
+			SessionID: termSession.SessionID,
+			Host:      termSession.host,
+			Command:   command,
+			Cwd:       termSession.cwd,
+			StartedAt: t.started.Format(time.RFC3339),
+		}
+		t.state = promptStateRunning
+	case "D":
+		var exitCode *int
+		if _, code, found := strings.Cut(seq.Payload, ";"); found {
+			if n, err := strconv.Atoi(strings.TrimSpace(code)); err == nil {
+				exitCode = &n
+			}
+		}
+		t.state = promptStateIdle
+		return t.finish(exitCode)
+	case "E":
+		t.explicit = unescapeVSCodeCommand(strings.TrimPrefix(seq.Payload, "E;"))
+	}
+	return nil
+}
+
+// captureInput buffers echoed input while the user is typing at the prompt,
+// up to MaxCommandInputCapture bytes
+func (t *commandTracker) captureInput(text string) {
+	if t.state != promptStateInput {
+		return
+	}
+	if room := MaxCommandInputCapture - t.input.Len(); room > 0 {
+		if len(text) > room {
+			// Don't keep half of a UTF-8 character
+			for room > 0 && !utf8.RuneStart(text[room]) {
+				room--
+			}
+			text = text[:room]
+		}
+		t.input.WriteString(text)
+	}
+}
+
+// isCapturingInput reports whether the user is typing at a marked prompt,
+// in which case plain output (the echo) must go through the tracker too
+func (ts *TerminalSession) isCapturingInput() bool {
+	ts.shellMu.Lock()
+	defer ts.shellMu.Unlock()
+	return ts.commands.state == promptStateInput
+}
+
+// finish completes the running command, if any
+func (t *commandTracker) finish(exitCode *int) *CommandRecord {
+	record := t.current
+	t.current = nil
+	if record == nil {
+		return nil
+	}
+
+	now := time.Now()
+	record.FinishedAt = now.Format(time.RFC3339)
+	record.DurationMs = now.Sub(t.started).Milliseconds()
+	record.ExitCode = exitCode
+	return record
+}
+
+// semanticPromptMarker returns the marker letter (A, B, C, D, E) of an
+// OSC 133 or OSC 633 sequence
+func semanticPromptMarker(seq oscSequence) (string, bool) {
+	if seq.Code != "133" && seq.Code != "633" {
+		return "", false
+	}
+	marker, _, _ := strings.Cut(seq.Payload, ";")
+	switch marker {
+	case "A", "B", "C", "D":
+		return marker, true
+	case "E":
+		return marker, seq.Code == "633"
+	}
+	return "", false
+}
+
+// cleanEchoedCommand recovers the command line from the shell's echo of the
+// user's typing: escape sequences are dropped and backspaces applied
+func cleanEchoedCommand(echo string) string {
+	echo = stripANSI(echo)
+	var buf []rune
+	for _, r := range echo {
+		switch {
+		case r == '\b' || r == 0x7f:
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+			}
+		case r == '\r' || r == '\n':
+			buf = append(buf, ' ')
+		case r < 0x20:
+			// Other control characters (bell, etc.) aren't part of the command
+		default:
+			buf = append(buf, r)
+		}
+	}
+	return strings.TrimSpace(string(buf))
+}
+
+// unescapeVSCodeCommand decodes the \xNN and \\ escapes VS Code uses in OSC 633;E
+func unescapeVSCodeCommand(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			if s[i+1] == '\\' {
+				b.WriteByte('\\')
+				i++
+				continue
+			}
+			if s[i+1] == 'x' && i+3 < len(s) {
+				if n, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+					b.WriteByte(byte(n))
+					i += 3
+					continue
+				}
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// stripANSI removes escape sequences (CSI, OSC, charset designations) from
+// terminal output, leaving printable text and control characters like \n and \b
+func stripANSI(s string) string {
+	if !strings.ContainsRune(s, '\x1b') {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\x1b' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			break
+		}
+		switch s[i+1] {
+		case '[':
+			// CSI: parameters then a final byte in 0x40-0x7E
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			i = j
+		case ']':
+			end, termLen := findOSCTerminator(s[i+2:])
+			if end < 0 {
+				return b.String()
+			}
+			i += 2 + end + termLen - 1
+		case '(', ')', '*', '+':
+			i += 2
+		default:
+			i++
+		}
+	}
+	return b.String()
+}
+
+// recordFinishedCommand stores a finished command and notifies the frontend
+// when it failed or ran longer than LongCommandThreshold
+func (a *App) recordFinishedCommand(record CommandRecord) {
+	if record.Command == "" || !utf8.ValidString(record.Command) {
+		return
+	}
+	addCommandRecord(record)
+
+	failed := record.ExitCode != nil && *record.ExitCode != 0
+	long := time.Duration(record.DurationMs)*time.Millisecond >= LongCommandThreshold
+	if (failed || long) && a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "terminal:command-finished", map[string]interface{}{
+			"record": record,
+			"failed": failed,
+			"long":   long,
+		})
+	}
+}
+
+// GetCommandHistory returns recorded commands matching the query, newest first
+func (a *App) GetCommandHistory(query CommandHistoryQuery) []CommandRecord {
+	commandHistory.mu.Lock()
+	defer commandHistory.mu.Unlock()
+	loadCommandHistoryLocked()
+
+	search := strings.ToLower(query.Search)
+	result := []CommandRecord{}
+	for i := len(commandHistory.records) - 1; i >= 0; i-- {
+		record := commandHistory.records[i]
+		if query.Host != "" && record.Host != query.Host {
+			continue
+		}
+		if query.SessionID != "" && record.SessionID != query.SessionID {
+			continue
+		}
+		if query.FailedOnly && (record.ExitCode == nil || *record.ExitCode == 0) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(record.Command), search) &&
+			!strings.Contains(strings.ToLower(record.Cwd), search) {
+			continue
+		}
+		result = append(result, record)
+		if query.Limit > 0 && len(result) >= query.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// RerunCommand types a recorded command into a terminal and presses Enter
+func (a *App) RerunCommand(sessionID string, recordID string) error {
+	commandHistory.mu.Lock()
+	loadCommandHistoryLocked()
+	var command string
+	for _, record := range commandHistory.records {
+		if record.ID == recordID {
+			command = record.Command
+			break
+		}
+	}
+	commandHistory.mu.Unlock()
+
+	if command == "" {
+		return fmt.Errorf("command history entry not found: %s", recordID)
+	}
+
+	return writeToTerminalSession(sessionID, command+"\r")
+}
+
+// DeleteCommandHistoryEntry removes a single command from the history
+func (a *App) DeleteCommandHistoryEntry(recordID string) error {
+	commandHistory.mu.Lock()
+	defer commandHistory.mu.Unlock()
+	loadCommandHistoryLocked()
+
+	for i, record := range commandHistory.records {
+		if record.ID == recordID {
+			commandHistory.records = append(commandHistory.records[:i], commandHistory.records[i+1:]...)
+			scheduleCommandHistorySaveLocked()
+			return nil
+		}
+	}
+	return fmt.Errorf("command history entry not found: %s", recordID)
+}
+
+// ClearCommandHistory removes all recorded commands for a host, or for all hosts if host is empty
+func (a *App) ClearCommandHistory(host string) error {
+	commandHistory.mu.Lock()
+	defer commandHistory.mu.Unlock()
+	loadCommandHistoryLocked()
+
+	kept := commandHistory.records[:0]
+	if host != "" {
+		for _, record := range commandHistory.records {
+			if record.Host != host {
+				kept = append(kept, record)
+			}
+		}
+	}
+	commandHistory.records = kept
+	scheduleCommandHistorySaveLocked()
+
+	log.Printf("🧹 [History] Cleared command history (host: %q)", host)
+	return nil
+}
@@ -0,0 +1,275 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/crypto/ssh"
+)
+
+// probeCommandTimeout bounds the short commands the app runs internally
+// (dependency checks, pwd), so a hung server can't block the UI forever
+const probeCommandTimeout = 30 * time.Second
+
+// CommandOptions controls how a streaming command runs
+type CommandOptions struct {
+	TimeoutSeconds int    `json:"timeoutSeconds"` // 0 means no timeout
+	Stdin          string `json:"stdin"`          // Data written to the command's stdin, then closed
+}
+
+// CommandExit describes how a remote command ended
+type CommandExit struct {
+	JobID      string `json:"jobId,omitempty"`
+	SessionID  string `json:"sessionId"`
+	ExitCode   int    `json:"exitCode"`         // -1 if killed by a signal or no status was reported
+	Signal     string `json:"signal,omitempty"` // e.g. "SIGKILL"
+	TimedOut   bool   `json:"timedOut"`
+	Cancelled  bool   `json:"cancelled"`
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"` // The command couldn't be run or its status was lost
+}
+
+// commandJob is a streaming command started from the UI
+type commandJob struct {
+	id        string
+	sessionID string
+	command   string
+	cancel    context.CancelFunc
+}
+
+// commandJobs holds running jobs so they can be cancelled
+var commandJobs = struct {
+	mu   sync.Mutex
+	jobs map[string]*commandJob
+}{
+	jobs: make(map[string]*commandJob),
+}
+
+// runRemoteCommand runs a command in a new SSH channel, streaming its output to
+// stdout and stderr (which may be the same writer). It stops the command when
+// ctx ends. Only failures to start return an error; the exit status, however
+// the command ended, is in CommandExit.
+func runRemoteCommand(ctx context.Context, sessionID string, command string, stdin string, stdout, stderr io.Writer) (CommandExit, error) {
+	exit := CommandExit{SessionID: sessionID, ExitCode: -1}
+
+	session, err := getConnectedSSHSession(sessionID)
+	if err != nil {
+		return exit, err
+	}
+
+	sshSession, err := session.Client.NewSession()
+	if err != nil {
+		return exit, fmt.Errorf("failed to create SSH session: %v", err)
+	}
+	defer sshSession.Close()
+
+	if stdout == stderr {
+		stdout = &lockedWriter{w: stdout}
+		stderr = stdout
+	}
+	sshSession.Stdout = stdout
+	sshSession.Stderr = stderr
+	if stdin != "" {
+		sshSession.Stdin = strings.NewReader(stdin)
+	}
+
+	start := time.Now()
+	if err := sshSession.Start(command); err != nil {
+		return exit, fmt.Errorf("failed to start command: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- sshSession.Wait() }()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// OpenSSH 7.9+ delivers the signal; older servers only see the channel close
+		sshSession.Signal(ssh.SIGKILL)
+		sshSession.Close()
+		select {
+		case err = <-done:
+		case <-time.After(5 * time.Second):
+			err = fmt.Errorf("command did not stop")
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			exit.TimedOut = true
+		} else {
+			exit.Cancelled = true
+		}
+	}
+	exit.DurationMs = time.Since(start).Milliseconds()
+
+	var exitErr *ssh.ExitError
+	switch {
+	case err == nil:
+		exit.ExitCode = 0
+	case errors.As(err, &exitErr):
+		exit.ExitCode = exitErr.ExitStatus()
+		if exitErr.Signal() != "" {
+			exit.ExitCode = -1
+			exit.Signal = "SIG" + exitErr.Signal()
+		}
+	case !exit.TimedOut && !exit.Cancelled:
+		exit.Error = err.Error()
+	}
+
+	session.mu.Lock()
+	session.LastActive = time.Now()
+	session.mu.Unlock()
+
+	return exit, nil
+}
+
+// lockedWriter serializes writes from the stdout and stderr copy goroutines
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// failure describes why a command didn't succeed, or returns nil if it exited 0
+func (e CommandExit) failure() error {
+	switch {
+	case e.TimedOut:
+		return fmt.Errorf("timed out after %dms", e.DurationMs)
+	case e.Cancelled:
+		return fmt.Errorf("cancelled")
+	case e.Error != "":
+		return fmt.Errorf("%s", e.Error)
+	case e.Signal != "":
+		return fmt.Errorf("killed by %s", e.Signal)
+	case e.ExitCode != 0:
+		return fmt.Errorf("exit code %d", e.ExitCode)
+	}
+	return nil
+}
+
+// runProbeCommand runs a short internal command and returns its stdout. A
+// non-zero exit is an error that includes the exit code and stderr.
+func runProbeCommand(sessionID string, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	exit, err := runRemoteCommand(ctx, sessionID, command, "", &stdout, &stderr)
+	if err != nil {
+		return "", err
+	}
+	if err := exit.failure(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("command failed (%v): %s", err, msg)
+		}
+		return stdout.String(), fmt.Errorf("command failed (%v)", err)
+	}
+	return stdout.String(), nil
+}
+
+// commandStreamWriter emits a job's output stream as events, without
+// splitting UTF-8 characters across chunks
+type commandStreamWriter struct {
+	a     *App
+	event string
+	jobID string
+	buf   UTF8SafeBuffer
+}
+
+func (w *commandStreamWriter) Write(p []byte) (int, error) {
+	w.emit(w.buf.AppendAndFlush(p))
+	return len(p), nil
+}
+
+func (w *commandStreamWriter) emit(data string) {
+	if data == "" || w.a.ctx == nil {
+		return
+	}
+	wailsRuntime.EventsEmit(w.a.ctx, w.event, map[string]interface{}{
+		"jobId": w.jobID,
+		"data":  data,
+	})
+}
+
+// StartCommand runs a command on the remote server in the background and
+// returns its job ID. Output arrives as command:stdout and command:stderr
+// events; command:exit reports the exit code, signal, timeout or cancellation.
+func (a *App) StartCommand(sessionID string, command string, options CommandOptions) (string, error) {
+	if _, err := getConnectedSSHSession(sessionID); err != nil {
+		return "", err
+	}
+	if options.TimeoutSeconds < 0 {
+		return "", fmt.Errorf("timeout must not be negative")
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if options.TimeoutSeconds > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(options.TimeoutSeconds)*time.Second)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	job := &commandJob{
+		id:        fmt.Sprintf("job-%d", time.Now().UnixNano()),
+		sessionID: sessionID,
+		command:   command,
+		cancel:    cancel,
+	}
+	commandJobs.mu.Lock()
+	commandJobs.jobs[job.id] = job
+	commandJobs.mu.Unlock()
+
+	log.Printf("▶️ [Command] Job %s on %s: %s", job.id, sessionID, command)
+
+	go func() {
+		defer func() {
+			cancel()
+			commandJobs.mu.Lock()
+			delete(commandJobs.jobs, job.id)
+			commandJobs.mu.Unlock()
+		}()
+
+		stdout := &commandStreamWriter{a: a, event: "command:stdout", jobID: job.id}
+		stderr := &commandStreamWriter{a: a, event: "command:stderr", jobID: job.id}
+		exit, err := runRemoteCommand(ctx, sessionID, command, options.Stdin, stdout, stderr)
+		if err != nil {
+			exit.Error = err.Error()
+		}
+		stdout.emit(stdout.buf.Flush())
+		stderr.emit(stderr.buf.Flush())
+
+		exit.JobID = job.id
+		log.Printf("⏹️ [Command] Job %s finished: code %d %s", job.id, exit.ExitCode, exit.Signal)
+		if a.ctx != nil {
+			wailsRuntime.EventsEmit(a.ctx, "command:exit", exit)
+		}
+	}()
+
+	return job.id, nil
+}
+
+// CancelCommand stops a running command job
+func (a *App) CancelCommand(jobID string) error {
+	commandJobs.mu.Lock()
+	job, exists := commandJobs.jobs[jobID]
+	commandJobs.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("command job not found: %s", jobID)
+	}
+	log.Printf("🛑 [Command] Cancelling job %s", jobID)
+	job.cancel()
+	return nil
+}
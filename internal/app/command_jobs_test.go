@@ -0,0 +1,101 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunRemoteCommandExitStatus(t *testing.T) {
+	newTestSSHSession(t, "command-test", "web")
+
+	var stdout, stderr bytes.Buffer
+	exit, err := runRemoteCommand(context.Background(), "command-test", "read line; echo out $line; echo err >&2; exit 3", "in\n", &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exit.ExitCode != 3 || exit.TimedOut || exit.Cancelled || exit.Error != "" {
+		t.Errorf("Unexpected exit: %+v", exit)
+	}
+	if stdout.String() != "out in\n" || stderr.String() != "err\n" {
+		t.Errorf("Unexpected output: %q %q", stdout.String(), stderr.String())
+	}
+	exit, err = runRemoteCommand(context.Background(), "command-test", "kill -KILL $$", "", &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exit.ExitCode != -1 || exit.Signal != "SIGKILL" {
+		t.Errorf("Expected the kill to be reported, got %+v", exit)
+	}
+}
+
+func TestRunRemoteCommandTimeoutAndCancel(t *testing.T) {
+	newTestSSHSession(t, "command-test", "web")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	exit, err := runRemoteCommand(ctx, "command-test", "sleep 10", "", &bytes.Buffer{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exit.TimedOut || exit.Cancelled || exit.ExitCode != -1 || exit.DurationMs >= 5000 {
+		t.Errorf("Expected a timeout, got %+v", exit)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	exit, err = runRemoteCommand(ctx, "command-test", "sleep 10", "", &bytes.Buffer{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exit.Cancelled || exit.TimedOut || exit.DurationMs >= 5000 {
+		t.Errorf("Expected a cancellation, got %+v", exit)
+	}
+}
+
+// waitForCommandJob waits until a job is no longer running
+func waitForCommandJob(t *testing.T, jobID string, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		commandJobs.mu.Lock()
+		_, running := commandJobs.jobs[jobID]
+		commandJobs.mu.Unlock()
+		if !running {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %s still running after %v", jobID, timeout)
+}
+
+func TestStartCommandCancelAndTimeout(t *testing.T) {
+	newTestSSHSession(t, "command-test", "web")
+	a := &App{}
+
+	jobID, err := a.StartCommand("command-test", "sleep 10", CommandOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CancelCommand(jobID); err != nil {
+		t.Fatal(err)
+	}
+	waitForCommandJob(t, jobID, 3*time.Second)
+	if err := a.CancelCommand(jobID); err == nil {
+		t.Errorf("Expected a finished job to be unknown")
+	}
+
+	jobID, err = a.StartCommand("command-test", "sleep 10", CommandOptions{TimeoutSeconds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForCommandJob(t, jobID, 4*time.Second)
+
+	if _, err := a.StartCommand("command-test", "true", CommandOptions{TimeoutSeconds: -1}); err == nil {
+		t.Errorf("Expected a negative timeout to be rejected")
+	}
+	if _, err := a.StartCommand("no-such-session", "true", CommandOptions{}); err == nil {
+		t.Errorf("Expected an unknown session to be rejected")
+	}
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// FileInfo describes a local or remote file in a directory listing
+type FileInfo struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Mode    string `json:"mode"`  // e.g. "-rwxr-xr-x"
+	Octal   string `json:"octal"` // Permission and special bits, e.g. "0755" or "4755"
+	ModTime string `json:"modTime"`
+	IsDir   bool   `json:"isDir"`
+	Type    string `json:"type"` // "file", "dir", "symlink", "socket", "fifo", "block", "char" or "other"
+
+	// Owner and group IDs are -1 and the names empty when unknown
+	UID   int    `json:"uid"`
+	GID   int    `json:"gid"`
+	Owner string `json:"owner"`
+	Group string `json:"group"`
+
+	// Set for symlinks; a broken link's target doesn't exist
+	LinkTarget string `json:"linkTarget,omitempty"`
+	BrokenLink bool   `json:"brokenLink,omitempty"`
+}
+
+// remoteLinkWorkers is how many symlinks of a remote listing are resolved at once
+const remoteLinkWorkers = 16
+
+// ownerNames maps user and group IDs to names
+type ownerNames struct {
+	users  map[int]string
+	groups map[int]string
+}
+
+// localOwnerNames caches local user and group name lookups, which can be slow
+// with network directories
+var localOwnerNames = struct {
+	mu sync.Mutex
+	ownerNames
+}{
+	ownerNames: ownerNames{users: make(map[int]string), groups: make(map[int]string)},
+}
+
+// fileType names the type of file a mode describes
+func fileType(mode os.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "file"
+	case mode.IsDir():
+		return "dir"
+	case mode&os.ModeSymlink != 0:
+		return "symlink"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	case mode&os.ModeNamedPipe != 0:
+		return "fifo"
+	case mode&os.ModeCharDevice != 0:
+		return "char"
+	case mode&os.ModeDevice != 0:
+		return "block"
+	}
+	return "other"
+}
+
+// newFileInfo describes a file from its Lstat info, without owner or link details
+func newFileInfo(fullPath string, info os.FileInfo) FileInfo {
+	return FileInfo{
+		Name:    info.Name(),
+		Path:    fullPath,
+		Size:    info.Size(),
+		Mode:    info.Mode().String(),
+		Octal:   fmt.Sprintf("%04o", fileModeToUnix(info.Mode())),
+		ModTime: info.ModTime().Format(time.RFC3339),
+		IsDir:   info.IsDir(),
+		Type:    fileType(info.Mode()),
+		UID:     -1,
+		GID:     -1,
+	}
+}
+
+// remoteOwnerNames returns the user and group names from a session's
+// /etc/passwd and /etc/group, read once per SFTP connection
+func remoteOwnerNames(sessionID string, sftpClient *sftp.Client) *ownerNames {
+	sftpPool.mu.Lock()
+	names, ok := sftpPool.owners[sessionID]
+	sftpPool.mu.Unlock()
+	if ok {
+		return names
+	}
+
+	// Missing files leave IDs unnamed, as for accounts only in LDAP and the like
+	names = &ownerNames{users: map[int]string{}, groups: map[int]string{}}
+	if data, err := readRemoteFile(sftpClient, "/etc/passwd"); err == nil {
+		names.users = parseIDNames(data)
+	}
+	if data, err := readRemoteFile(sftpClient, "/etc/group"); err == nil {
+		names.groups = parseIDNames(data)
+	}
+
+	sftpPool.mu.Lock()
+	if _, connected := sftpPool.clients[sessionID]; connected {
+		sftpPool.owners[sessionID] = names
+	}
+	sftpPool.mu.Unlock()
+	return names
+}
+
+// describeRemoteFile describes a remote directory entry from its Lstat info.
+// Link targets are filled in by resolveRemoteLinks.
+func describeRemoteFile(names *ownerNames, dir string, info os.FileInfo) FileInfo {
+	fullPath := strings.TrimSuffix(dir, "/") + "/" + info.Name()
+	file := newFileInfo(fullPath, info)
+	if stat, ok := info.Sys().(*sftp.FileStat); ok {
+		file.UID, file.GID = int(stat.UID), int(stat.GID)
+		file.Owner, file.Group = names.users[file.UID], names.groups[file.GID]
+	}
+	return file
+}
+
+// resolveRemoteLinks fills in the targets of the symlinks in a listing. Each
+// link takes two round trips, so directories full of them (/usr/lib,
+// /etc/alternatives) resolve them concurrently.
+func resolveRemoteLinks(sftpClient *sftp.Client, files []FileInfo) {
+	work := make(chan *FileInfo)
+	var wg sync.WaitGroup
+	for i := 0; i < remoteLinkWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range work {
+				file.LinkTarget, _ = sftpClient.ReadLink(file.Path)
+				_, err := sftpClient.Stat(file.Path)
+				file.BrokenLink = err != nil
+			}
+		}()
+	}
+	for i := range files {
+		if files[i].Type == "symlink" {
+			work <- &files[i]
+		}
+	}
+	close(work)
+	wg.Wait()
+}
+
+// describeLocalFile describes a local directory entry from its Lstat info
+func describeLocalFile(dir string, info os.FileInfo) FileInfo {
+	fullPath := filepath.Join(dir, info.Name())
+	file := newFileInfo(fullPath, info)
+	if uid, gid, ok := localOwner(info); ok {
+		file.UID, file.GID = uid, gid
+		file.Owner, file.Group = localOwnerName(uid, false), localOwnerName(gid, true)
+	}
+	if file.Type == "symlink" {
+		file.LinkTarget, _ = os.Readlink(fullPath)
+		_, err := os.Stat(fullPath)
+		file.BrokenLink = err != nil
+	}
+	return file
+}
+
+// localOwnerName returns the name of a local user or group ID, or "" if it has none
+func localOwnerName(id int, group bool) string {
+	localOwnerNames.mu.Lock()
+	defer localOwnerNames.mu.Unlock()
+
+	cache := localOwnerNames.users
+	if group {
+		cache = localOwnerNames.groups
+	}
+	if name, ok := cache[id]; ok {
+		return name
+	}
+
+	var name string
+	if group {
+		if g, err := user.LookupGroupId(strconv.Itoa(id)); err == nil {
+			name = g.Name
+		}
+	} else if u, err := user.LookupId(strconv.Itoa(id)); err == nil {
+		name = u.Username
+	}
+	cache[id] = name
+	return name
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileType(t *testing.T) {
+	tests := map[os.FileMode]string{
+		0644:                                  "file",
+		os.ModeDir | 0755:                     "dir",
+		os.ModeSymlink | 0777:                 "symlink",
+		os.ModeSocket | 0755:                  "socket",
+		os.ModeNamedPipe | 0644:               "fifo",
+		os.ModeDevice | os.ModeCharDevice | 0: "char",
+		os.ModeDevice | 0660:                  "block",
+	}
+	for mode, want := range tests {
+		if got := fileType(mode); got != want {
+			t.Errorf("%v: got %s, want %s", mode, got, want)
+		}
+	}
+}
+
+func TestListingsMatch(t *testing.T) {
+	newTestSFTPClient(t, "listing-test")
+	a := &App{}
+
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "sub"), 0750)
+	os.WriteFile(filepath.Join(dir, "run.sh"), nil, 0755)
+	os.Chmod(filepath.Join(dir, "run.sh"), 0755|os.ModeSetuid)
+	os.Symlink("run.sh", filepath.Join(dir, "link"))
+	os.Symlink("missing", filepath.Join(dir, "broken"))
+	// More links than remote listings resolve at once
+	for i := 0; i < 40; i++ {
+		os.Symlink("sub", filepath.Join(dir, fmt.Sprintf("alt%d", i)))
+	}
+
+	remote, err := a.ListFiles("listing-test", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := a.ListLocalFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]FileInfo)
+	for _, file := range local {
+		byName[file.Name] = file
+	}
+	if byName["run.sh"].Octal != "4755" || byName["sub"].Type != "dir" || byName["sub"].Octal != "0750" {
+		t.Errorf("Unexpected modes %+v", byName)
+	}
+	if link := byName["link"]; link.Type != "symlink" || link.LinkTarget != "run.sh" || link.BrokenLink {
+		t.Errorf("Unexpected link %+v", link)
+	}
+	if broken := byName["broken"]; broken.LinkTarget != "missing" || !broken.BrokenLink {
+		t.Errorf("Unexpected broken link %+v", broken)
+	}
+
+	// Names come from different sources, so compare everything else
+	if len(remote) != len(local) {
+		t.Fatalf("Expected %d remote entries, got %d", len(local), len(remote))
+	}
+	for _, file := range remote {
+		want := byName[file.Name]
+		file.Owner, file.Group, want.Owner, want.Group = "", "", "", ""
+		if !reflect.DeepEqual(file, want) {
+			t.Errorf("Remote %+v differs from local %+v", file, want)
+		}
+	}
+}
@@ -431,12 +431,13 @@ func (a *App) CopyRemoteFilesToSystemClipboard(sessionID string, remotePaths []s
 
 		if info.IsDir() {
 			// Directory: use DownloadDirectory for recursive download
-			if err := a.DownloadDirectory(sessionID, remotePath, tempDir); err != nil {
+			// Files that fail are logged by DownloadDirectory; copy what arrived
+			result, err := a.DownloadDirectory(sessionID, remotePath, tempDir)
+			if err != nil {
 				log.Printf("⚠️ Failed to download directory %s: %v", remotePath, err)
 				continue
 			}
-			// DownloadDirectory creates tempDir/<dirname>, build the local path
-			localPaths = append(localPaths, filepath.Join(tempDir, filepath.Base(resolved)))
+			localPaths = append(localPaths, result.Target)
 		} else {
 			// File: use DownloadFile
 			localPath, err := a.DownloadFile(sessionID, remotePath, tempDir)
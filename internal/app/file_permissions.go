@@ -0,0 +1,509 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// FileChangeResult is the outcome of a chmod, chown or chgrp
+type FileChangeResult struct {
+	Changed int                 `json:"changed"`
+	Failed  []TransferFileError `json:"failed"` // Paths that couldn't be changed
+}
+
+// Permission bits of each class, including its special bit, as used by chmod
+const (
+	modeUser  = 04700
+	modeGroup = 02070
+	modeOther = 01007
+	modeAll   = 07777
+)
+
+var (
+	octalModePattern  = regexp.MustCompile(`^[0-7]{1,4}$`)
+	modeClausePattern = regexp.MustCompile(`^([ugoa]*)((?:[-+=](?:[ugo]|[rwxXst]*))+)$`)
+	modeActionPattern = regexp.MustCompile(`([-+=])([ugo]|[rwxXst]*)`)
+)
+
+// modeChange is a parsed chmod mode: an octal mode, or symbolic clauses
+// applied to each file's current mode
+type modeChange struct {
+	absolute bool
+	bits     uint32
+	clauses  []modeClause
+}
+
+type modeClause struct {
+	who     uint32
+	actions []modeAction
+}
+
+type modeAction struct {
+	op    byte   // '+', '-' or '='
+	perms string // Letters from "rwxXst", or a single class to copy from "ugo"
+}
+
+// parseFileMode parses a chmod mode, either octal ("755", "4750") or
+// symbolic ("u+x,go-w", "a=rX", "g=u"). Unlike chmod, a clause without a
+// class ("+x") applies to all classes regardless of umask.
+func parseFileMode(spec string) (*modeChange, error) {
+	spec = strings.TrimSpace(spec)
+	if octalModePattern.MatchString(spec) {
+		bits, _ := strconv.ParseUint(spec, 8, 32)
+		return &modeChange{absolute: true, bits: uint32(bits)}, nil
+	}
+
+	change := &modeChange{}
+	for _, clause := range strings.Split(spec, ",") {
+		match := modeClausePattern.FindStringSubmatch(clause)
+		if match == nil {
+			return nil, fmt.Errorf("invalid mode: %s", spec)
+		}
+		parsed := modeClause{}
+		for _, class := range match[1] {
+			switch class {
+			case 'u':
+				parsed.who |= modeUser
+			case 'g':
+				parsed.who |= modeGroup
+			case 'o':
+				parsed.who |= modeOther
+			case 'a':
+				parsed.who |= modeAll
+			}
+		}
+		if parsed.who == 0 {
+			parsed.who = modeAll
+		}
+		for _, action := range modeActionPattern.FindAllStringSubmatch(match[2], -1) {
+			parsed.actions = append(parsed.actions, modeAction{op: action[1][0], perms: action[2]})
+		}
+		change.clauses = append(change.clauses, parsed)
+	}
+	return change, nil
+}
+
+// apply returns the mode a file with the current mode gets
+func (c *modeChange) apply(current os.FileMode, isDir bool) os.FileMode {
+	if c.absolute {
+		return unixToFileMode(c.bits)
+	}
+	bits := fileModeToUnix(current)
+	for _, clause := range c.clauses {
+		for _, action := range clause.actions {
+			perms := action.permBits(bits, isDir) & clause.who
+			switch action.op {
+			case '+':
+				bits |= perms
+			case '-':
+				bits &^= perms
+			case '=':
+				bits = bits&^clause.who | perms
+			}
+		}
+	}
+	return unixToFileMode(bits)
+}
+
+// permBits returns the bits an action names, for every class
+func (a modeAction) permBits(current uint32, isDir bool) uint32 {
+	switch a.perms {
+	case "u":
+		return (current >> 6 & 7) * 0111
+	case "g":
+		return (current >> 3 & 7) * 0111
+	case "o":
+		return (current & 7) * 0111
+	}
+	var bits uint32
+	for _, perm := range a.perms {
+		switch perm {
+		case 'r':
+			bits |= 0444
+		case 'w':
+			bits |= 0222
+		case 'x':
+			bits |= 0111
+		case 'X':
+			// Execute only for directories and files someone can already execute
+			if isDir || current&0111 != 0 {
+				bits |= 0111
+			}
+		case 's':
+			bits |= 06000
+		case 't':
+			bits |= 01000
+		}
+	}
+	return bits
+}
+
+// fileModeToUnix converts permission and special bits to their chmod values
+func fileModeToUnix(mode os.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		bits |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		bits |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		bits |= 01000
+	}
+	return bits
+}
+
+// unixToFileMode converts chmod values to permission and special bits
+func unixToFileMode(bits uint32) os.FileMode {
+	mode := os.FileMode(bits & 0777)
+	if bits&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if bits&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if bits&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// parseOwnerSpec splits a chown owner "user", "user:group" or ":group"
+func parseOwnerSpec(spec string) (owner string, group string, err error) {
+	owner, group, _ = strings.Cut(strings.TrimSpace(spec), ":")
+	if owner == "" && group == "" {
+		return "", "", fmt.Errorf("invalid owner: %q", spec)
+	}
+	return owner, group, nil
+}
+
+// idFileEntries calls fn with the name and ID of each entry in a file
+// formatted like /etc/passwd or /etc/group, where the ID is the third field
+func idFileEntries(data []byte, fn func(name string, id int)) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 {
+			continue
+		}
+		if id, err := strconv.Atoi(fields[2]); err == nil {
+			fn(fields[0], id)
+		}
+	}
+}
+
+// parseIDFile maps names to IDs in an /etc/passwd or /etc/group formatted file
+func parseIDFile(data []byte) map[string]int {
+	ids := make(map[string]int)
+	idFileEntries(data, func(name string, id int) { ids[name] = id })
+	return ids
+}
+
+// parseIDNames maps IDs to names in an /etc/passwd or /etc/group formatted
+// file. The first name listed for an ID wins, as with ls.
+func parseIDNames(data []byte) map[int]string {
+	names := make(map[int]string)
+	idFileEntries(data, func(name string, id int) {
+		if _, ok := names[id]; !ok {
+			names[id] = name
+		}
+	})
+	return names
+}
+
+// readRemoteFile reads a small remote file, like /etc/passwd
+func readRemoteFile(sftpClient *sftp.Client, file string) ([]byte, error) {
+	remoteFile, err := sftpClient.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", file, err)
+	}
+	defer remoteFile.Close()
+
+	var data bytes.Buffer
+	if _, err := data.ReadFrom(remoteFile); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", file, err)
+	}
+	return data.Bytes(), nil
+}
+
+// remoteID resolves a user or group name to its ID from the remote /etc/passwd
+// or /etc/group. Numeric names are used as they are, which also covers
+// accounts from LDAP and the like that aren't in those files.
+func remoteID(sftpClient *sftp.Client, name string, file string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil && id >= 0 {
+		return id, nil
+	}
+	data, err := readRemoteFile(sftpClient, file)
+	if err != nil {
+		return 0, err
+	}
+	id, ok := parseIDFile(data)[name]
+	if !ok {
+		return 0, fmt.Errorf("%s has no entry for %s, use a numeric ID", file, name)
+	}
+	return id, nil
+}
+
+// localID resolves a local user or group name to its ID
+func localID(name string, group bool) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil && id >= 0 {
+		return id, nil
+	}
+	var id string
+	if group {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return 0, err
+		}
+		id = g.Gid
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return 0, err
+		}
+		id = u.Uid
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("%s has a non-numeric ID: %s", name, id)
+	}
+	return n, nil
+}
+
+// changeRemoteTree calls change for remotePath and, if recursive, everything
+// below it. Symlinks below it are left alone, as SFTP would change their targets.
+func changeRemoteTree(sftpClient *sftp.Client, remotePath string, recursive bool, change func(path string, info os.FileInfo) error) (FileChangeResult, error) {
+	result := FileChangeResult{Failed: []TransferFileError{}}
+	info, err := sftpClient.Stat(remotePath)
+	if err != nil {
+		return result, fmt.Errorf("failed to stat remote path: %v", err)
+	}
+	record := func(path string, err error) {
+		if err != nil {
+			result.Failed = append(result.Failed, TransferFileError{Path: path, Error: err.Error()})
+		} else {
+			result.Changed++
+		}
+	}
+
+	if !recursive || !info.IsDir() {
+		record(remotePath, change(remotePath, info))
+		return result, nil
+	}
+	walker := sftpClient.Walk(strings.TrimSuffix(remotePath, "/") + "/")
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			record(walker.Path(), err)
+			continue
+		}
+		entryInfo := walker.Stat()
+		if entryInfo.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		record(walker.Path(), change(walker.Path(), entryInfo))
+	}
+	return result, nil
+}
+
+// changeLocalTree is the local counterpart of changeRemoteTree
+func changeLocalTree(localPath string, recursive bool, change func(path string, info os.FileInfo) error) (FileChangeResult, error) {
+	result := FileChangeResult{Failed: []TransferFileError{}}
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to stat local path: %v", err)
+	}
+	record := func(path string, err error) {
+		if err != nil {
+			result.Failed = append(result.Failed, TransferFileError{Path: path, Error: err.Error()})
+		} else {
+			result.Changed++
+		}
+	}
+
+	if !recursive || !info.IsDir() {
+		record(localPath, change(localPath, info))
+		return result, nil
+	}
+	if resolved, err := filepath.EvalSymlinks(localPath); err == nil {
+		localPath = resolved
+	}
+	filepath.WalkDir(localPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			record(path, err)
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		entryInfo, err := d.Info()
+		if err != nil {
+			record(path, err)
+			return nil
+		}
+		record(path, change(path, entryInfo))
+		return nil
+	})
+	return result, nil
+}
+
+// logFileChange logs the outcome of a chmod, chown or chgrp
+func logFileChange(action string, path string, result FileChangeResult) {
+	for _, failed := range result.Failed {
+		log.Printf("⚠️ Failed to %s %s: %s", action, failed.Path, failed.Error)
+	}
+	log.Printf("✅ %s %s: %d changed, %d failed", action, path, result.Changed, len(result.Failed))
+}
+
+// ChmodRemoteFile changes the mode of a remote file or directory, and with
+// recursive everything in it. mode is octal ("755") or symbolic ("u+x,go-w").
+func (a *App) ChmodRemoteFile(sessionID string, remotePath string, mode string, recursive bool) (FileChangeResult, error) {
+	change, err := parseFileMode(mode)
+	if err != nil {
+		return FileChangeResult{}, err
+	}
+	sftpClient, err := getSFTPClient(sessionID)
+	if err != nil {
+		return FileChangeResult{}, err
+	}
+	// SFTP client is managed by pool, do not close here
+
+	// Resolve ~ to actual home directory
+	remotePath = resolveRemotePath(sftpClient, remotePath)
+
+	result, err := changeRemoteTree(sftpClient, remotePath, recursive, func(path string, info os.FileInfo) error {
+		return sftpClient.Chmod(path, change.apply(info.Mode(), info.IsDir()))
+	})
+	if err != nil {
+		return result, err
+	}
+	logFileChange("chmod", remotePath, result)
+	return result, nil
+}
+
+// ChownRemoteFile changes the owner and optionally the group of a remote file
+// or directory. owner is "user", "user:group" or ":group", by name or numeric ID.
+func (a *App) ChownRemoteFile(sessionID string, remotePath string, owner string, recursive bool) (FileChangeResult, error) {
+	ownerName, groupName, err := parseOwnerSpec(owner)
+	if err != nil {
+		return FileChangeResult{}, err
+	}
+	sftpClient, err := getSFTPClient(sessionID)
+	if err != nil {
+		return FileChangeResult{}, err
+	}
+	// SFTP client is managed by pool, do not close here
+
+	// -1 keeps the current ID
+	uid, gid := -1, -1
+	if ownerName != "" {
+		if uid, err = remoteID(sftpClient, ownerName, "/etc/passwd"); err != nil {
+			return FileChangeResult{}, err
+		}
+	}
+	if groupName != "" {
+		if gid, err = remoteID(sftpClient, groupName, "/etc/group"); err != nil {
+			return FileChangeResult{}, err
+		}
+	}
+
+	// Resolve ~ to actual home directory
+	remotePath = resolveRemotePath(sftpClient, remotePath)
+
+	result, err := changeRemoteTree(sftpClient, remotePath, recursive, func(path string, info os.FileInfo) error {
+		// SFTP sets both IDs at once
+		newUID, newGID := uid, gid
+		if stat, ok := info.Sys().(*sftp.FileStat); ok {
+			if newUID < 0 {
+				newUID = int(stat.UID)
+			}
+			if newGID < 0 {
+				newGID = int(stat.GID)
+			}
+		}
+		if newUID < 0 || newGID < 0 {
+			return fmt.Errorf("server didn't report the current owner")
+		}
+		return sftpClient.Chown(path, newUID, newGID)
+	})
+	if err != nil {
+		return result, err
+	}
+	logFileChange("chown", remotePath, result)
+	return result, nil
+}
+
+// ChgrpRemoteFile changes the group of a remote file or directory
+func (a *App) ChgrpRemoteFile(sessionID string, remotePath string, group string, recursive bool) (FileChangeResult, error) {
+	if strings.TrimSpace(group) == "" {
+		return FileChangeResult{}, fmt.Errorf("group is required")
+	}
+	return a.ChownRemoteFile(sessionID, remotePath, ":"+strings.TrimSpace(group), recursive)
+}
+
+// ChmodLocalFile changes the mode of a local file or directory, like ChmodRemoteFile
+func (a *App) ChmodLocalFile(localPath string, mode string, recursive bool) (FileChangeResult, error) {
+	change, err := parseFileMode(mode)
+	if err != nil {
+		return FileChangeResult{}, err
+	}
+	result, err := changeLocalTree(localPath, recursive, func(path string, info os.FileInfo) error {
+		return os.Chmod(path, change.apply(info.Mode(), info.IsDir()))
+	})
+	if err != nil {
+		return result, err
+	}
+	logFileChange("chmod", localPath, result)
+	return result, nil
+}
+
+// ChownLocalFile changes the owner and optionally the group of a local file or
+// directory, like ChownRemoteFile. Changing the owner usually needs root.
+func (a *App) ChownLocalFile(localPath string, owner string, recursive bool) (FileChangeResult, error) {
+	ownerName, groupName, err := parseOwnerSpec(owner)
+	if err != nil {
+		return FileChangeResult{}, err
+	}
+	uid, gid := -1, -1
+	if ownerName != "" {
+		if uid, err = localID(ownerName, false); err != nil {
+			return FileChangeResult{}, fmt.Errorf("unknown user %s: %v", ownerName, err)
+		}
+	}
+	if groupName != "" {
+		if gid, err = localID(groupName, true); err != nil {
+			return FileChangeResult{}, fmt.Errorf("unknown group %s: %v", groupName, err)
+		}
+	}
+
+	result, err := changeLocalTree(localPath, recursive, func(path string, info os.FileInfo) error {
+		return os.Chown(path, uid, gid)
+	})
+	if err != nil {
+		return result, err
+	}
+	logFileChange("chown", localPath, result)
+	return result, nil
+}
+
+// ChgrpLocalFile changes the group of a local file or directory
+func (a *App) ChgrpLocalFile(localPath string, group string, recursive bool) (FileChangeResult, error) {
+	if strings.TrimSpace(group) == "" {
+		return FileChangeResult{}, fmt.Errorf("group is required")
+	}
+	return a.ChownLocalFile(localPath, ":"+strings.TrimSpace(group), recursive)
+}
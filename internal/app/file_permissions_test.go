@@ -0,0 +1,77 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFileMode(t *testing.T) {
+	tests := []struct {
+		spec    string
+		current os.FileMode
+		isDir   bool
+		want    os.FileMode
+	}{
+		{"755", 0600, false, 0755},
+		{"4750", 0600, false, 0750 | os.ModeSetuid},
+		{"u+x", 0644, false, 0744},
+		{"go-w", 0666, false, 0644},
+		{"+x", 0600, false, 0711},
+		{"a=rX", 0700, true, 0555},
+		{"a=rX", 0640, false, 0444},
+		{"u=rw,g=u,o=", 0751, false, 0660},
+		{"g+s,o+t", 0755, true, 0755 | os.ModeSetgid | os.ModeSticky},
+		{"u-x+s", 0755, false, 0655 | os.ModeSetuid},
+	}
+	for _, tt := range tests {
+		change, err := parseFileMode(tt.spec)
+		if err != nil {
+			t.Errorf("%s: %v", tt.spec, err)
+			continue
+		}
+		if got := change.apply(tt.current, tt.isDir); got != tt.want {
+			t.Errorf("%s on %v: got %v, want %v", tt.spec, tt.current, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", "8", "77777", "u+q", "x+r", "u=ux", "u+x,"} {
+		if _, err := parseFileMode(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestParseIDFile(t *testing.T) {
+	ids := parseIDFile([]byte("# users\nroot:x:0:0:root:/root:/bin/bash\ndeploy:x:1001:1001::/home/deploy:/bin/sh\nbroken\n"))
+	if len(ids) != 2 || ids["root"] != 0 || ids["deploy"] != 1001 {
+		t.Errorf("Unexpected IDs %v", ids)
+	}
+}
+
+func TestChmodRemoteFileRecursive(t *testing.T) {
+	newTestSFTPClient(t, "chmod-test")
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "bin"), 0755)
+	os.WriteFile(filepath.Join(dir, "bin", "run"), nil, 0744)
+	os.WriteFile(filepath.Join(dir, "notes"), nil, 0644)
+	os.Symlink("notes", filepath.Join(dir, "link"))
+
+	result, err := (&App{}).ChmodRemoteFile("chmod-test", dir, "go-rwx,u+X", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Changed != 4 || len(result.Failed) != 0 {
+		t.Errorf("Expected 4 paths changed, got %+v", result)
+	}
+	for name, want := range map[string]os.FileMode{"": 0700, "bin": 0700, "bin/run": 0700, "notes": 0600} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%s: expected %v, got %v", name, want, info.Mode().Perm())
+		}
+	}
+}
@@ -0,0 +1,25 @@
+//go:build linux
+
+package app
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// localAccessTime returns a local file's access time, or its modification time if unknown
+func localAccessTime(info os.FileInfo) time.Time {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Atim.Unix())
+	}
+	return info.ModTime()
+}
+
+// localOwner returns a local file's user and group IDs
+func localOwner(info os.FileInfo) (uid int, gid int, ok bool) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int(stat.Uid), int(stat.Gid), true
+	}
+	return -1, -1, false
+}
@@ -0,0 +1,18 @@
+//go:build !darwin && !windows && !linux
+
+package app
+
+import (
+	"os"
+	"time"
+)
+
+// localAccessTime returns a local file's modification time, as the access time isn't read on this platform
+func localAccessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
+
+// localOwner reports no IDs, as they aren't read on this platform
+func localOwner(info os.FileInfo) (uid int, gid int, ok bool) {
+	return -1, -1, false
+}
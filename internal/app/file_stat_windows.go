@@ -0,0 +1,22 @@
+//go:build windows
+
+package app
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// localAccessTime returns a local file's access time, or its modification time if unknown
+func localAccessTime(info os.FileInfo) time.Time {
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, data.LastAccessTime.Nanoseconds())
+	}
+	return info.ModTime()
+}
+
+// localOwner reports no IDs, as Windows files have security descriptors instead
+func localOwner(info os.FileInfo) (uid int, gid int, ok bool) {
+	return -1, -1, false
+}
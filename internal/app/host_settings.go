@@ -0,0 +1,315 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HostSettings holds per-host options that cannot be expressed in ~/.ssh/config.
+// Settings are keyed by the Host alias from the SSH config.
+type HostSettings struct {
+	Host string `json:"host"`
+
+	// Multiplexer runs the remote shell inside a named tmux or screen session
+	// so long-running jobs survive disconnects: "" (off), "tmux", "screen" or
+	// "auto" (tmux, falling back to screen)
+	Multiplexer string `json:"multiplexer"`
+	// MultiplexerSession is the session name to create or reattach to.
+	// Empty means "xfm-<host>".
+	MultiplexerSession string `json:"multiplexerSession"`
+
+	// TermType is the terminal type requested for the PTY. Empty means "xterm-256color".
+	TermType string `json:"termType"`
+	// Locale is sent as LANG and LC_ALL. Empty means "en_US.UTF-8"; "none" sends nothing.
+	Locale string `json:"locale"`
+	// TerminalModes overrides PTY modes by RFC 4254 name, e.g. {"VERASE": 8, "IUTF8": 1}
+	TerminalModes map[string]uint32 `json:"terminalModes"`
+	// Env holds extra variables sent with Setenv, overriding SetEnv from ~/.ssh/config
+	Env map[string]string `json:"env"`
+
+	// Encoding is the character encoding of the host's terminals and text files,
+	// e.g. "gbk" or "shift_jis". Empty means UTF-8.
+	Encoding string `json:"encoding"`
+
+	// Clipboard decides whether programs on the host may set the local clipboard
+	// via OSC 52: "ask" (default when empty), "allow" or "deny"
+	Clipboard string `json:"clipboard"`
+	// ClipboardMaxBytes limits the size of an OSC 52 clipboard write. 0 means 1 MiB.
+	ClipboardMaxBytes int `json:"clipboardMaxBytes"`
+
+	// Tags group hosts for batch commands, e.g. ["prod", "web"]
+	Tags []string `json:"tags"`
+
+	// StartupCommands are typed into every new terminal once the shell is ready.
+	// With a multiplexer they run in a newly created session, and are reported
+	// skipped when reattaching to one that already exists.
+	StartupCommands []StartupCommand `json:"startupCommands"`
+
+	// ResumeCheck decides when an interrupted transfer continues from its .part
+	// file: "tail" (default when empty) compares a hash of the last 64 KiB with
+	// the source, "size" only checks the partial file isn't larger than the source
+	ResumeCheck string `json:"resumeCheck"`
+
+	// SFTP tuning for high-latency links, applied to new SFTP connections.
+	// SFTPRequestSize is the bytes per read or write request (0 means 32 KiB;
+	// larger sizes need a server that accepts them). SFTPConcurrency is the
+	// requests kept in flight per file (0 means 64). ParallelFiles is how many
+	// files of a directory transfer at once (0 means 4).
+	SFTPRequestSize int `json:"sftpRequestSize"`
+	SFTPConcurrency int `json:"sftpConcurrency"`
+	ParallelFiles   int `json:"parallelFiles"`
+
+	// PreserveAttributes carries mode bits and access/modification times over
+	// to copies, like scp -p
+	PreserveAttributes bool `json:"preserveAttributes"`
+	// Symlinks decides how directory transfers and SFTP syncs treat symbolic
+	// links: "follow" (default when empty) copies what they point to, "skip"
+	// leaves them out, "copy" recreates them as links. rsync syncs keep links
+	// unless this is "follow" or "skip".
+	Symlinks string `json:"symlinks"`
+}
+
+// hostSettingsStore keeps per-host settings in memory, backed by host-settings.json
+var hostSettingsStore = struct {
+	mu       sync.RWMutex
+	loaded   bool
+	settings map[string]*HostSettings
+}{
+	settings: make(map[string]*HostSettings),
+}
+
+// loadHostSettingsLocked reads host-settings.json once. Caller must hold the write lock.
+func loadHostSettingsLocked() {
+	if hostSettingsStore.loaded {
+		return
+	}
+	hostSettingsStore.loaded = true
+
+	configPath, err := getAppConfigPath("host-settings.json")
+	if err != nil {
+		log.Printf("⚠️ [HostSettings] Failed to get config path: %v", err)
+		return
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ [HostSettings] Failed to read host settings: %v", err)
+		}
+		return
+	}
+
+	var list []*HostSettings
+	if err := json.Unmarshal(data, &list); err != nil {
+		log.Printf("⚠️ [HostSettings] Failed to parse host settings: %v", err)
+		return
+	}
+
+	for _, s := range list {
+		if s.Host != "" {
+			hostSettingsStore.settings[s.Host] = s
+		}
+	}
+}
+
+// saveHostSettingsLocked writes all host settings to disk. Caller must hold the lock.
+func saveHostSettingsLocked() error {
+	configPath, err := getAppConfigPath("host-settings.json")
+	if err != nil {
+		return err
+	}
+
+	list := make([]*HostSettings, 0, len(hostSettingsStore.settings))
+	for _, s := range hostSettingsStore.settings {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Host < list[j].Host })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal host settings: %v", err)
+	}
+
+	// Environment values and startup commands can carry tokens, so only the
+	// user may read the file, including one saved readable by everyone before
+	if err := os.Chmod(configPath, 0600); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to restrict host settings: %v", err)
+	}
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write host settings: %v", err)
+	}
+	return nil
+}
+
+// getHostSettings returns a copy of the settings for a host, or defaults if none are saved
+func getHostSettings(host string) HostSettings {
+	hostSettingsStore.mu.Lock()
+	defer hostSettingsStore.mu.Unlock()
+	loadHostSettingsLocked()
+
+	if s, ok := hostSettingsStore.settings[host]; ok {
+		return *s
+	}
+	return HostSettings{Host: host}
+}
+
+// sessionHostSettings returns the settings for the host of an SSH session, or
+// defaults if the session is unknown
+func sessionHostSettings(sessionID string) HostSettings {
+	sshManager.mu.RLock()
+	session, exists := sshManager.sessions[sessionID]
+	sshManager.mu.RUnlock()
+	if !exists {
+		return HostSettings{}
+	}
+	return getHostSettings(session.Config.Host)
+}
+
+// GetHostSettings returns the settings for a single host
+func (a *App) GetHostSettings(host string) HostSettings {
+	return getHostSettings(host)
+}
+
+// GetAllHostSettings returns the settings of every host that has been customized
+func (a *App) GetAllHostSettings() []HostSettings {
+	hostSettingsStore.mu.Lock()
+	defer hostSettingsStore.mu.Unlock()
+	loadHostSettingsLocked()
+
+	result := make([]HostSettings, 0, len(hostSettingsStore.settings))
+	for _, s := range hostSettingsStore.settings {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Host < result[j].Host })
+	return result
+}
+
+// SetHostSettings creates or replaces the settings for a host
+func (a *App) SetHostSettings(settings HostSettings) error {
+	if settings.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	switch settings.Multiplexer {
+	case "", "tmux", "screen", "auto":
+	default:
+		return fmt.Errorf("invalid multiplexer: %s (must be 'tmux', 'screen' or 'auto')", settings.Multiplexer)
+	}
+	if _, err := buildTerminalModes(settings.TerminalModes); err != nil {
+		return err
+	}
+	if settings.Encoding != "" {
+		name, _, err := lookupEncoding(settings.Encoding)
+		if err != nil {
+			return err
+		}
+		settings.Encoding = name
+	}
+	switch settings.Clipboard {
+	case "", ClipboardAsk, ClipboardAllow, ClipboardDeny:
+	default:
+		return fmt.Errorf("invalid clipboard policy: %s (must be 'ask', 'allow' or 'deny')", settings.Clipboard)
+	}
+	if settings.ClipboardMaxBytes < 0 || settings.ClipboardMaxBytes > MaxClipboardBytes {
+		return fmt.Errorf("clipboard size limit must be between 0 and %d bytes", MaxClipboardBytes)
+	}
+	if _, err := validateStartupCommands(settings.StartupCommands); err != nil {
+		return err
+	}
+	switch settings.ResumeCheck {
+	case "", ResumeCheckTail, ResumeCheckSize:
+	default:
+		return fmt.Errorf("invalid resume check: %s (must be 'tail' or 'size')", settings.ResumeCheck)
+	}
+	if settings.SFTPRequestSize != 0 && (settings.SFTPRequestSize < 1024 || settings.SFTPRequestSize > MaxSFTPRequestSize) {
+		return fmt.Errorf("SFTP request size must be between 1024 and %d bytes", MaxSFTPRequestSize)
+	}
+	if settings.SFTPConcurrency < 0 || settings.SFTPConcurrency > MaxSFTPConcurrency {
+		return fmt.Errorf("SFTP concurrency must be between 0 and %d", MaxSFTPConcurrency)
+	}
+	if settings.ParallelFiles < 0 || settings.ParallelFiles > MaxParallelFiles {
+		return fmt.Errorf("parallel files must be between 0 and %d", MaxParallelFiles)
+	}
+	switch settings.Symlinks {
+	case "", SymlinkFollow, SymlinkSkip, SymlinkCopy:
+	default:
+		return fmt.Errorf("invalid symlink policy: %s (must be 'follow', 'skip' or 'copy')", settings.Symlinks)
+	}
+	tags := make([]string, 0, len(settings.Tags))
+	for _, tag := range settings.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	settings.Tags = tags
+	for name := range settings.Env {
+		if name == "" || strings.ContainsAny(name, "= \t\x00") {
+			return fmt.Errorf("invalid environment variable name: %q", name)
+		}
+	}
+
+	hostSettingsStore.mu.Lock()
+	defer hostSettingsStore.mu.Unlock()
+	loadHostSettingsLocked()
+
+	hostSettingsStore.settings[settings.Host] = &settings
+	if err := saveHostSettingsLocked(); err != nil {
+		return err
+	}
+
+	log.Printf("💾 [HostSettings] Saved settings for host: %s", settings.Host)
+	return nil
+}
+
+// DeleteHostSettings resets a host back to default settings
+func (a *App) DeleteHostSettings(host string) error {
+	hostSettingsStore.mu.Lock()
+	defer hostSettingsStore.mu.Unlock()
+	loadHostSettingsLocked()
+
+	if _, ok := hostSettingsStore.settings[host]; !ok {
+		return nil
+	}
+	delete(hostSettingsStore.settings, host)
+	return saveHostSettingsLocked()
+}
+
+// hostsWithTag returns the hosts whose settings carry a tag
+func hostsWithTag(tag string) []string {
+	hostSettingsStore.mu.Lock()
+	defer hostSettingsStore.mu.Unlock()
+	loadHostSettingsLocked()
+
+	var hosts []string
+	for host, s := range hostSettingsStore.settings {
+		if slices.Contains(s.Tags, tag) {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// GetHostTags returns every tag used in host settings, sorted
+func (a *App) GetHostTags() []string {
+	hostSettingsStore.mu.Lock()
+	defer hostSettingsStore.mu.Unlock()
+	loadHostSettingsLocked()
+
+	tags := []string{}
+	for _, s := range hostSettingsStore.settings {
+		for _, tag := range s.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
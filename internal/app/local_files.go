@@ -1,36 +1,31 @@
 package app
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"os/user"
 	"path/filepath"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/pkg/sftp"
 )
 
-// LocalFileInfo represents a local file or directory
-type LocalFileInfo struct {
-	Name    string `json:"name"`
-	Path    string `json:"path"`
-	IsDir   bool   `json:"isDir"`
-	Size    int64  `json:"size"`
-	ModTime string `json:"modTime"`
-}
-
 // TransferProgress represents file transfer progress
 type TransferProgress struct {
-	FileName    string  `json:"fileName"`
-	TotalBytes  int64   `json:"totalBytes"`
-	Transferred int64   `json:"transferred"`
-	Percent     float64 `json:"percent"`
-	Status      string  `json:"status"` // "transferring", "completed", "error"
-	Error       string  `json:"error,omitempty"`
+	ID             string  `json:"id,omitempty"`        // Transfer queue job, empty for ZMODEM transfers
+	SessionID      string  `json:"sessionId,omitempty"` // SSH session, or terminal session for ZMODEM transfers
+	Direction      string  `json:"direction,omitempty"` // "upload" or "download"
+	FileName       string  `json:"fileName"`
+	TotalBytes     int64   `json:"totalBytes"`
+	Transferred    int64   `json:"transferred"`
+	Percent        float64 `json:"percent"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+	EtaSeconds     int64   `json:"etaSeconds"` // 0 if unknown
+	Status         string  `json:"status"`     // "queued", "transferring", "paused", "completed", "error", "cancelled"
+	Error          string  `json:"error,omitempty"`
 }
 
 // GetHomeDirectory returns the current user's home directory
@@ -43,7 +38,7 @@ func (a *App) GetHomeDirectory() (string, error) {
 }
 
 // ListLocalFiles lists files in a local directory
-func (a *App) ListLocalFiles(path string) ([]LocalFileInfo, error) {
+func (a *App) ListLocalFiles(path string) ([]FileInfo, error) {
 	// Expand ~ to home directory
 	if path == "~" || path == "" {
 		homeDir, err := a.GetHomeDirectory()
@@ -62,23 +57,13 @@ func (a *App) ListLocalFiles(path string) ([]LocalFileInfo, error) {
 		return nil, fmt.Errorf("failed to read directory: %v", err)
 	}
 
-	var files []LocalFileInfo
+	var files []FileInfo
 	for _, entry := range entries {
 		info, err := entry.Info()
 		if err != nil {
 			continue
 		}
-
-		fullPath := filepath.Join(path, entry.Name())
-
-		fileInfo := LocalFileInfo{
-			Name:    entry.Name(),
-			Path:    fullPath,
-			IsDir:   entry.IsDir(),
-			Size:    info.Size(),
-			ModTime: info.ModTime().Format(time.RFC3339),
-		}
-		files = append(files, fileInfo)
+		files = append(files, describeLocalFile(path, info))
 	}
 
 	return files, nil
@@ -89,8 +74,10 @@ func (a *App) ListLocalFiles(path string) ([]LocalFileInfo, error) {
 var sftpPool = struct {
 	mu      sync.Mutex
 	clients map[string]*sftp.Client
+	owners  map[string]*ownerNames // Remote user and group names, see remoteOwnerNames
 }{
 	clients: make(map[string]*sftp.Client),
+	owners:  make(map[string]*ownerNames),
 }
 
 // getSFTPClient returns a cached or new SFTP client for the given session.
@@ -124,7 +111,7 @@ func getSFTPClient(sessionID string) (*sftp.Client, error) {
 		return nil, fmt.Errorf("session not connected")
 	}
 
-	sftpClient, err := sftp.NewClient(session.Client)
+	sftpClient, err := sftp.NewClient(session.Client, sftpClientOptions(getHostSettings(session.Config.Host))...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SFTP client: %v", err)
 	}
@@ -143,6 +130,7 @@ func closeSFTPClient(sessionID string) {
 		client.Close()
 		delete(sftpPool.clients, sessionID)
 	}
+	delete(sftpPool.owners, sessionID)
 }
 
 // resolveRemotePath resolves ~ in remote paths to the actual home directory via SFTP
@@ -190,15 +178,8 @@ func (a *App) DownloadFile(sessionID string, remotePath string, localDir string)
 
 	log.Printf("📥 Resolved remote path: %s", remotePath)
 
-	// Open remote file
-	remoteFile, err := sftpClient.Open(remotePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open remote file: %v", err)
-	}
-	defer remoteFile.Close()
-
 	// Get remote file info
-	remoteInfo, err := remoteFile.Stat()
+	remoteInfo, err := sftpClient.Stat(remotePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to stat remote file: %v", err)
 	}
@@ -209,17 +190,9 @@ func (a *App) DownloadFile(sessionID string, remotePath string, localDir string)
 
 	log.Printf("📥 Downloading: %s -> %s (size: %d bytes)", remotePath, localPath, remoteInfo.Size())
 
-	// Create local file
-	localFile, err := os.Create(localPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create local file: %v", err)
-	}
-	defer localFile.Close()
-
-	// Copy data
-	written, err := io.Copy(localFile, remoteFile)
+	// A failed download leaves localPath.part behind for the next attempt to resume
+	written, err := resumableDownload(context.Background(), sftpClient, remotePath, localPath, fileCopyFor(sessionID))
 	if err != nil {
-		os.Remove(localPath) // cleanup on error
 		return "", fmt.Errorf("failed to download file: %v", err)
 	}
 
@@ -240,15 +213,8 @@ func (a *App) UploadFile(sessionID string, localPath string, remoteDir string) (
 
 	log.Printf("📤 Resolved remote dir: %s", remoteDir)
 
-	// Open local file
-	localFile, err := os.Open(localPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open local file: %v", err)
-	}
-	defer localFile.Close()
-
 	// Get local file info
-	localInfo, err := localFile.Stat()
+	localInfo, err := os.Stat(localPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to stat local file: %v", err)
 	}
@@ -259,17 +225,9 @@ func (a *App) UploadFile(sessionID string, localPath string, remoteDir string) (
 
 	log.Printf("📤 Uploading: %s -> %s (size: %d bytes)", localPath, remotePath, localInfo.Size())
 
-	// Create remote file
-	remoteFile, err := sftpClient.Create(remotePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create remote file: %v", err)
-	}
-	defer remoteFile.Close()
-
-	// Copy data
-	written, err := io.Copy(remoteFile, localFile)
+	// A failed upload leaves remotePath.part behind for the next attempt to resume
+	written, err := resumableUpload(context.Background(), sftpClient, localPath, remotePath, fileCopyFor(sessionID))
 	if err != nil {
-		sftpClient.Remove(remotePath) // cleanup on error
 		return "", fmt.Errorf("failed to upload file: %v", err)
 	}
 
@@ -277,11 +235,19 @@ func (a *App) UploadFile(sessionID string, localPath string, remoteDir string) (
 	return remotePath, nil
 }
 
-// DownloadDirectory recursively downloads a directory from remote to local
-func (a *App) DownloadDirectory(sessionID string, remotePath string, localDir string) error {
+// DirectoryTransferResult is the outcome of a directory download or upload
+type DirectoryTransferResult struct {
+	Target string              `json:"target"` // Path of the copied directory
+	Files  int                 `json:"files"`  // Files in the source directory
+	Failed []TransferFileError `json:"failed"` // Files or directories that couldn't be copied
+}
+
+// DownloadDirectory recursively downloads a directory from remote to local.
+// Files that fail are listed in the result; the rest are still copied.
+func (a *App) DownloadDirectory(sessionID string, remotePath string, localDir string) (DirectoryTransferResult, error) {
 	sftpClient, err := getSFTPClient(sessionID)
 	if err != nil {
-		return err
+		return DirectoryTransferResult{}, err
 	}
 	// SFTP client is managed by pool, do not close here
 
@@ -294,48 +260,61 @@ func (a *App) DownloadDirectory(sessionID string, remotePath string, localDir st
 
 	// Create local directory
 	if err := os.MkdirAll(localPath, 0755); err != nil {
-		return fmt.Errorf("failed to create local directory: %v", err)
+		return DirectoryTransferResult{}, fmt.Errorf("failed to create local directory: %v", err)
 	}
 
-	// Walk remote directory
-	walker := sftpClient.Walk(remotePath)
-	for walker.Step() {
-		if err := walker.Err(); err != nil {
-			log.Printf("⚠️ Walk error: %v", err)
-			continue
-		}
+	opts := treeTransferFor(sessionID)
+	entries, errs := listRemoteTree(sftpClient, remotePath, opts.symlinks)
+	errs = append(errs, downloadRemoteTree(context.Background(), sftpClient, entries, localPath, opts)...)
+	return directoryTransferResult(localPath, entries, errs), nil
+}
 
-		relPath, err := filepath.Rel(remotePath, walker.Path())
-		if err != nil {
-			continue
-		}
+// UploadDirectory recursively uploads a local directory into remoteDir.
+// Files that fail are listed in the result; the rest are still copied.
+func (a *App) UploadDirectory(sessionID string, localPath string, remoteDir string) (DirectoryTransferResult, error) {
+	sftpClient, err := getSFTPClient(sessionID)
+	if err != nil {
+		return DirectoryTransferResult{}, err
+	}
+	// SFTP client is managed by pool, do not close here
+
+	localInfo, err := os.Stat(localPath)
+	if err != nil {
+		return DirectoryTransferResult{}, fmt.Errorf("failed to stat local directory: %v", err)
+	}
+	if !localInfo.IsDir() {
+		return DirectoryTransferResult{}, fmt.Errorf("not a directory: %s", localPath)
+	}
 
-		targetPath := filepath.Join(localPath, relPath)
+	// Resolve ~ to actual home directory
+	remotePath := resolveRemotePath(sftpClient, strings.TrimSuffix(remoteDir, "/")) + "/" + filepath.Base(localPath)
+	if err := sftpClient.MkdirAll(remotePath); err != nil {
+		return DirectoryTransferResult{}, fmt.Errorf("failed to create remote directory: %v", err)
+	}
 
-		if walker.Stat().IsDir() {
-			os.MkdirAll(targetPath, 0755)
-		} else {
-			// Download individual file
-			remoteFile, err := sftpClient.Open(walker.Path())
-			if err != nil {
-				log.Printf("⚠️ Skip file %s: %v", walker.Path(), err)
-				continue
-			}
-
-			localFile, err := os.Create(targetPath)
-			if err != nil {
-				remoteFile.Close()
-				log.Printf("⚠️ Skip file %s: %v", targetPath, err)
-				continue
-			}
-
-			io.Copy(localFile, remoteFile)
-			localFile.Close()
-			remoteFile.Close()
+	log.Printf("📤 Uploading directory: %s -> %s", localPath, remotePath)
+	opts := treeTransferFor(sessionID)
+	entries, errs := listLocalTree(localPath, opts.symlinks)
+	errs = append(errs, uploadTree(context.Background(), sftpClient, entries, remotePath, opts)...)
+	return directoryTransferResult(remotePath, entries, errs), nil
+}
+
+// directoryTransferResult summarizes a directory transfer and logs its failures
+func directoryTransferResult(target string, entries []treeEntry, errs []error) DirectoryTransferResult {
+	result := DirectoryTransferResult{Target: target, Failed: transferFileErrors(errs)}
+	if result.Failed == nil {
+		result.Failed = []TransferFileError{}
+	}
+	for _, entry := range entries {
+		if !entry.IsDir {
+			result.Files++
 		}
 	}
-
-	return nil
+	for _, failed := range result.Failed {
+		log.Printf("⚠️ Failed to copy %s: %s", failed.Path, failed.Error)
+	}
+	log.Printf("✅ Directory transfer complete: %s (%d files, %d failed)", target, result.Files, len(result.Failed))
+	return result
 }
 
 // DeleteRemoteFile deletes a remote file via SFTP
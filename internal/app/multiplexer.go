@@ -0,0 +1,230 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MultiplexerStatus reports which terminal multiplexers are available on a remote server
+type MultiplexerStatus struct {
+	HasTmux       bool   `json:"hasTmux"`
+	TmuxVersion   string `json:"tmuxVersion"`
+	HasScreen     bool   `json:"hasScreen"`
+	ScreenVersion string `json:"screenVersion"`
+	Message       string `json:"message"`
+}
+
+// MultiplexerSession describes an existing tmux or screen session on a remote server
+type MultiplexerSession struct {
+	Name        string `json:"name"`
+	Multiplexer string `json:"multiplexer"` // "tmux" or "screen"
+	Windows     int    `json:"windows"`     // tmux only
+	Attached    bool   `json:"attached"`
+	Created     string `json:"created"`
+}
+
+// invalidMuxNameChars matches characters that tmux or screen reject (or that
+// would need quoting) in session names
+var invalidMuxNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// defaultMultiplexerSessionName derives a session name from the SSH host alias
+func defaultMultiplexerSessionName(host string) string {
+	return "xfm-" + sanitizeMultiplexerSessionName(host)
+}
+
+// sanitizeMultiplexerSessionName replaces characters tmux and screen don't accept.
+// tmux silently rewrites '.' and ':' in names, which breaks reattaching by name.
+func sanitizeMultiplexerSessionName(name string) string {
+	name = invalidMuxNameChars.ReplaceAllString(strings.TrimSpace(name), "-")
+	name = strings.Trim(name, "-")
+	if name == "" {
+		name = "default"
+	}
+	return name
+}
+
+// shellQuote quotes a string for safe use as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// multiplexerCommand builds the remote command that attaches to the named
+// session, creating it if it doesn't exist. If the multiplexer is missing
+// at runtime, the user's login shell is started instead so the tab still works.
+func multiplexerCommand(multiplexer string, name string) string {
+	quoted := shellQuote(name)
+	switch multiplexer {
+	case "screen":
+		return fmt.Sprintf("command -v screen >/dev/null 2>&1 && exec screen -D -R -S %s || exec \"${SHELL:-/bin/sh}\" -l", quoted)
+	default:
+		return fmt.Sprintf("command -v tmux >/dev/null 2>&1 && exec tmux new-session -A -s %s || exec \"${SHELL:-/bin/sh}\" -l", quoted)
+	}
+}
+
+// CheckRemoteMultiplexer checks if tmux and screen are available on the remote server
+func (a *App) CheckRemoteMultiplexer(sessionID string) MultiplexerStatus {
+	result := MultiplexerStatus{}
+
+	output, err := runProbeCommand(sessionID, "command -v tmux >/dev/null 2>&1 && tmux -V")
+	if err == nil && strings.TrimSpace(output) != "" {
+		result.HasTmux = true
+		result.TmuxVersion = strings.TrimSpace(output)
+	}
+
+	// screen -v exits non-zero on some versions even though it prints the version
+	output2, _ := runProbeCommand(sessionID, "command -v screen >/dev/null 2>&1 && screen -v")
+	if strings.Contains(output2, "Screen version") {
+		result.HasScreen = true
+		result.ScreenVersion = strings.TrimSpace(strings.SplitN(output2, "\n", 2)[0])
+	}
+
+	switch {
+	case result.HasTmux && result.HasScreen:
+		result.Message = "tmux and screen available"
+	case result.HasTmux:
+		result.Message = "tmux available"
+	case result.HasScreen:
+		result.Message = "tmux not found (will use screen)"
+	default:
+		result.Message = "neither tmux nor screen found (persistent sessions unavailable)"
+	}
+
+	return result
+}
+
+// ListMultiplexerSessions lists existing tmux and screen sessions on the remote server
+func (a *App) ListMultiplexerSessions(sessionID string) ([]MultiplexerSession, error) {
+	if _, err := getConnectedSSHSession(sessionID); err != nil {
+		return nil, err
+	}
+
+	sessions := []MultiplexerSession{}
+
+	// tmux exits non-zero when no server is running; treat that as "no sessions"
+	output, err := runProbeCommand(sessionID, "tmux list-sessions -F '#{session_name}:#{session_windows}:#{session_attached}:#{session_created}' 2>/dev/null")
+	if err == nil {
+		sessions = append(sessions, parseTmuxSessions(output)...)
+	}
+
+	// screen -ls exits non-zero even when sessions exist
+	output2, _ := runProbeCommand(sessionID, "command -v screen >/dev/null 2>&1 && screen -ls 2>/dev/null")
+	sessions = append(sessions, parseScreenSessions(output2)...)
+
+	return sessions, nil
+}
+
+// parseTmuxSessions parses `tmux list-sessions` output in the
+// name:windows:attached:created format used by ListMultiplexerSessions.
+// tmux does not allow ':' in session names, so splitting on it is safe.
+func parseTmuxSessions(output string) []MultiplexerSession {
+	var sessions []MultiplexerSession
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), ":")
+		if len(parts) != 4 || parts[0] == "" {
+			continue
+		}
+		s := MultiplexerSession{Name: parts[0], Multiplexer: "tmux"}
+		s.Windows, _ = strconv.Atoi(parts[1])
+		attached, _ := strconv.Atoi(parts[2])
+		s.Attached = attached > 0
+		if created, err := strconv.ParseInt(parts[3], 10, 64); err == nil {
+			s.Created = time.Unix(created, 0).Format(time.RFC3339)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// parseScreenSessions parses `screen -ls` output, e.g.
+//
+//	There are screens on:
+//		12345.xfm-web	(Detached)
+//		2345.pts-0.web	(10/18/2026 12:00:00 PM)	(Attached)
+func parseScreenSessions(output string) []MultiplexerSession {
+	var sessions []MultiplexerSession
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "\t") {
+			continue
+		}
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		pidName := strings.SplitN(fields[0], ".", 2)
+		if len(pidName) != 2 {
+			continue
+		}
+		s := MultiplexerSession{Name: pidName[1], Multiplexer: "screen"}
+		status := fields[len(fields)-1]
+		s.Attached = strings.Contains(status, "Attached")
+		if len(fields) > 2 {
+			s.Created = strings.Trim(fields[1], "()")
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// multiplexerSessionExists reports whether the named tmux or screen session
+// is already running on the remote server
+func multiplexerSessionExists(sessionID string, multiplexer string, name string) bool {
+	var sessions []MultiplexerSession
+	if multiplexer == "tmux" {
+		output, err := runProbeCommand(sessionID, "tmux list-sessions -F '#{session_name}:#{session_windows}:#{session_attached}:#{session_created}' 2>/dev/null")
+		if err == nil {
+			sessions = parseTmuxSessions(output)
+		}
+	} else {
+		output, _ := runProbeCommand(sessionID, "command -v screen >/dev/null 2>&1 && screen -ls 2>/dev/null")
+		sessions = parseScreenSessions(output)
+	}
+	for _, s := range sessions {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveMultiplexer picks the multiplexer to use for a host setting.
+// "auto" prefers tmux and falls back to screen; returns "" if none is installed.
+func (a *App) resolveMultiplexer(sessionID string, multiplexer string) string {
+	if multiplexer != "auto" {
+		return multiplexer
+	}
+	status := a.CheckRemoteMultiplexer(sessionID)
+	if status.HasTmux {
+		return "tmux"
+	}
+	if status.HasScreen {
+		return "screen"
+	}
+	return ""
+}
+
+// StartMultiplexedTerminalSession starts a terminal that attaches to (or creates)
+// a specific tmux or screen session, regardless of the host's default setting.
+// Used to reattach to a session picked from ListMultiplexerSessions.
+func (a *App) StartMultiplexedTerminalSession(sessionID string, rows int, cols int, multiplexer string, name string) error {
+	if multiplexer != "tmux" && multiplexer != "screen" && multiplexer != "auto" {
+		return fmt.Errorf("invalid multiplexer: %s (must be 'tmux', 'screen' or 'auto')", multiplexer)
+	}
+
+	multiplexer = a.resolveMultiplexer(sessionID, multiplexer)
+	if multiplexer == "" {
+		return fmt.Errorf("neither tmux nor screen is installed on the remote server")
+	}
+
+	if name == "" {
+		sshSession, err := getConnectedSSHSession(sessionID)
+		if err != nil {
+			return err
+		}
+		name = defaultMultiplexerSessionName(sshSession.Config.Host)
+	}
+
+	return a.startSSHTerminal(sessionID, rows, cols, multiplexer, name)
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestParseTmuxSessions(t *testing.T) {
+	output := "xfm-web:3:1:1760788800\nbuild:1:0:1760788900\n"
+
+	sessions := parseTmuxSessions(output)
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].Name != "xfm-web" || sessions[0].Windows != 3 || !sessions[0].Attached {
+		t.Errorf("Unexpected first session: %+v", sessions[0])
+	}
+	if sessions[1].Name != "build" || sessions[1].Attached {
+		t.Errorf("Unexpected second session: %+v", sessions[1])
+	}
+}
+
+func TestParseScreenSessions(t *testing.T) {
+	output := "There are screens on:\n" +
+		"\t12345.xfm-web\t(Detached)\n" +
+		"\t2345.pts-0.web\t(10/18/2026 12:00:00 PM)\t(Attached)\n" +
+		"2 Sockets in /run/screen/S-user.\n"
+
+	sessions := parseScreenSessions(output)
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].Name != "xfm-web" || sessions[0].Attached {
+		t.Errorf("Unexpected first session: %+v", sessions[0])
+	}
+	if sessions[1].Name != "pts-0.web" || !sessions[1].Attached {
+		t.Errorf("Unexpected second session: %+v", sessions[1])
+	}
+}
+
+func TestSanitizeMultiplexerSessionName(t *testing.T) {
+	cases := map[string]string{
+		"web-01":          "web-01",
+		"prod.example":    "prod-example",
+		"  user@host:22 ": "user-host-22",
+		"...":             "default",
+	}
+	for input, expected := range cases {
+		if got := sanitizeMultiplexerSessionName(input); got != expected {
+			t.Errorf("sanitizeMultiplexerSessionName(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
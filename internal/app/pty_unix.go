@@ -8,14 +8,18 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"github.com/creack/pty"
-	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/sys/unix"
 )
 
-// StartLocalTerminalSession starts a local PTY session using Unix PTY
-func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, initialDir string) error {
+// startLocalTerminal starts a local PTY session using Unix PTY
+func (a *App) startLocalTerminal(sessionID string, rows int, cols int, initialDir string, profile TerminalProfile) error {
 	// Guard: atomic check-and-mark with WRITE lock to prevent TOCTOU race condition
 	termSessionMu.Lock()
 	if _, exists := terminalSessions[sessionID]; exists {
@@ -37,8 +41,11 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 		}
 	}()
 
-	// Determine shell based on OS
-	shell := os.Getenv("SHELL")
+	// Determine shell: the profile's, else the user's login shell
+	shell := profile.Shell
+	if shell == "" {
+		shell = os.Getenv("SHELL")
+	}
 	if shell == "" {
 		// Default to zsh on macOS, bash on Linux
 		if runtime.GOOS == "darwin" {
@@ -48,41 +55,17 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 		}
 	}
 
-	// Create command
-	cmd := exec.Command(shell)
-
-	// Set initial working directory if provided and valid
-	if initialDir != "" {
-		if stat, err := os.Stat(initialDir); err == nil && stat.IsDir() {
-			cmd.Dir = initialDir
-		} else {
-			log.Printf("⚠️ Invalid initial directory %s, using default", initialDir)
-		}
+	args := profile.Args
+	if profile.LoginShell {
+		args = append([]string{"-l"}, args...)
 	}
 
-	// Set environment variables, filtering out Python venv variables
-	// that may have been inherited from the parent process.
-	// A child shell won't have the 'deactivate' function defined,
-	// so inheriting VIRTUAL_ENV causes errors in shell hooks.
-	cleanEnv := make([]string, 0, len(os.Environ()))
-	termSet := false
-	for _, env := range os.Environ() {
-		if len(env) >= 11 && env[:11] == "VIRTUAL_ENV" {
-			continue // skip VIRTUAL_ENV and VIRTUAL_ENV_PROMPT
-		}
-		if len(env) >= 5 && env[:5] == "TERM=" {
-			termSet = true
-		}
-		cleanEnv = append(cleanEnv, env)
-	}
-	// Set TERM to xterm-256color if not already set (fixes Chinese input and delete key)
-	if !termSet {
-		cleanEnv = append(cleanEnv, "TERM=xterm-256color")
-	}
-	// Set LANG to UTF-8 locale for proper Chinese character support
-	cleanEnv = append(cleanEnv, "LANG=en_US.UTF-8")
-	cleanEnv = append(cleanEnv, "LC_ALL=en_US.UTF-8")
-	cmd.Env = cleanEnv
+	// Create command
+	cmd := exec.Command(shell, args...)
+	cmd.Dir = profileWorkingDir(initialDir, profile)
+	cmd.Env = buildProfileEnv(os.Environ(), profile)
+
+	log.Printf("🖥️ Starting local terminal with profile %q: %s %v", profile.Name, shell, args)
 
 	// Create PTY using creack/pty (Unix-specific)
 	ptmx, err := pty.Start(cmd)
@@ -102,15 +85,18 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 
 	// Create terminal session with UTF-8 safe buffer
 	termSession := &TerminalSession{
-		SessionID:   sessionID,
-		LocalCmd:    cmd,
-		LocalPTY:    ptmx,
-		LocalStdin:  ptmx,
-		stopChan:    make(chan struct{}),
-		isConnected: true,
-		isLocal:     true,
-		utf8Buffer:  &UTF8SafeBuffer{}, // Prevent UTF-8 truncation in local terminal output
+		SessionID:  sessionID,
+		LocalCmd:   cmd,
+		LocalPTY:   ptmx,
+		LocalStdin: ptmx,
+		stopChan:   make(chan struct{}),
+		isLocal:    true,
+		host:       "local",
+		rows:       rows,
+		cols:       cols,
+		utf8Buffer: &UTF8SafeBuffer{}, // Prevent UTF-8 truncation in local terminal output
 	}
+	termSession.isConnected.Store(true)
 
 	// Store session (overwrite placeholder with fully initialized session)
 	termSessionMu.Lock()
@@ -118,20 +104,26 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 	termSessionMu.Unlock()
 	sessionReady = true
 
+	a.runStartupCommands(termSession, profile.StartupCommands)
+
 	// Start output reader
 	go func() {
+		osc := &oscParser{}
 		defer func() {
 			if r := recover(); r != nil {
 				log.Printf("❌ PANIC RECOVERED in local terminal reader goroutine for session %s: %v", sessionID, r)
 			}
 			termSessionMu.Lock()
 			if ts, ok := terminalSessions[sessionID]; ok {
-				ts.isConnected = false
+				ts.isConnected.Store(false)
 			}
 			termSessionMu.Unlock()
 
 			// Flush any remaining bytes when session ends
-			if remaining := termSession.utf8Buffer.Flush(); remaining != "" {
+			if remaining := termSession.flushDecoder(&termSession.utf8Buffer); remaining != "" {
+				a.handleTerminalOutput(termSession, osc, remaining)
+			}
+			if remaining := osc.Flush(); remaining != "" {
 				a.emitTerminalOutput(sessionID, remaining)
 			}
 		}()
@@ -152,9 +144,9 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 				if n > 0 {
 					// Use UTF-8 safe buffer to prevent character truncation
 					// This is critical when window resizing triggers large output bursts
-					completeUTF8 := termSession.utf8Buffer.AppendAndFlush(buffer[:n])
+					completeUTF8 := termSession.decode(&termSession.utf8Buffer, buffer[:n])
 					if completeUTF8 != "" {
-						a.emitTerminalOutput(sessionID, completeUTF8)
+						a.handleTerminalOutput(termSession, osc, completeUTF8)
 					}
 				}
 			}
@@ -170,25 +162,29 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 		}()
 
 		cmd.Wait()
-		termSession.mu.Lock()
-		termSession.isConnected = false
-		termSession.mu.Unlock()
+		termSession.isConnected.Store(false)
 		termSession.stopOnce.Do(func() { close(termSession.stopChan) })
 
-		// Emit disconnection event to frontend
-		if a.ctx != nil {
-			wailsRuntime.EventsEmit(a.ctx, "terminal:disconnected", map[string]interface{}{
-				"sessionId": sessionID,
-				"reason":    "Process exited",
-			})
-		}
-
-		log.Printf("Local terminal session ended: %s", sessionID)
+		// Emit disconnection event to frontend with the shell's exit status
+		exitCode, signal := localExitStatus(cmd.ProcessState)
+		a.emitLocalTerminalExit(sessionID, exitCode, signal)
 	}()
 
 	return nil
 }
 
+// localExitStatus returns a finished shell's exit code and the name of the
+// signal that killed it, or an empty name if it exited normally
+func localExitStatus(state *os.ProcessState) (int, string) {
+	if state == nil {
+		return -1, ""
+	}
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return state.ExitCode(), unix.SignalName(status.Signal())
+	}
+	return state.ExitCode(), ""
+}
+
 // ResizeLocalTerminal resizes the Unix PTY
 func resizeLocalTerminal(termSession *TerminalSession, rows, cols int) error {
 	log.Printf("🔧 [ResizeLocalTerminal] Attempting to resize local terminal to %dx%d (rows x cols)", rows, cols)
@@ -218,3 +214,67 @@ func closeLocalTerminal(termSession *TerminalSession) {
 		termSession.LocalCmd.Process.Kill()
 	}
 }
+
+// foregroundPgrp returns the terminal's foreground process group (tcgetpgrp).
+// The ioctl goes through SyscallConn because Fd() would switch the PTY to
+// blocking mode and stop Close from interrupting the reader.
+func foregroundPgrp(ptmx *os.File) (int, error) {
+	conn, err := ptmx.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var pgrp int
+	var ioctlErr error
+	if err := conn.Control(func(fd uintptr) {
+		pgrp, ioctlErr = unix.IoctlGetInt(int(fd), unix.TIOCGPGRP)
+	}); err != nil {
+		return 0, err
+	}
+	return pgrp, ioctlErr
+}
+
+// processName returns a process's command name from /proc, falling back to ps
+func processName(pid int) string {
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid)); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	output, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(strings.TrimSpace(string(output)))
+}
+
+// foregroundProcess returns the process group leader in the foreground of a local PTY
+func foregroundProcess(termSession *TerminalSession) (TerminalProcess, error) {
+	if termSession.LocalPTY == nil || termSession.LocalCmd == nil || termSession.LocalCmd.Process == nil {
+		return TerminalProcess{}, fmt.Errorf("local terminal has no PTY")
+	}
+
+	pgrp, err := foregroundPgrp(termSession.LocalPTY)
+	if err != nil {
+		return TerminalProcess{}, fmt.Errorf("failed to get foreground process group: %v", err)
+	}
+
+	shellPID := termSession.LocalCmd.Process.Pid
+	return TerminalProcess{
+		PID:      pgrp,
+		Name:     processName(pgrp),
+		ShellPID: shellPID,
+		IsShell:  pgrp == shellPID,
+	}, nil
+}
+
+// signalForegroundProcess sends a signal to the foreground process group of a local PTY
+func signalForegroundProcess(termSession *TerminalSession, signal string) error {
+	sig := unix.SignalNum(signal)
+	if sig == 0 {
+		return fmt.Errorf("unknown signal: %s", signal)
+	}
+
+	process, err := foregroundProcess(termSession)
+	if err != nil {
+		return err
+	}
+	return unix.Kill(-process.PID, sig)
+}
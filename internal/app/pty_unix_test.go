@@ -0,0 +1,84 @@
+//go:build !windows
+
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// waitForForeground polls a local terminal until check accepts its foreground process
+func waitForForeground(t *testing.T, a *App, sessionID string, check func(TerminalProcess) bool) TerminalProcess {
+	t.Helper()
+	var process TerminalProcess
+	var err error
+	for i := 0; i < 300; i++ {
+		if process, err = a.GetTerminalForegroundProcess(sessionID); err == nil && check(process) {
+			return process
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Unexpected foreground process %+v (%v)", process, err)
+	return process
+}
+
+func TestSignalTerminalForegroundCommand(t *testing.T) {
+	a := &App{}
+	sessionID := fmt.Sprintf("local-%d", time.Now().UnixNano())
+	if err := a.startLocalTerminal(sessionID, 24, 80, "", TerminalProfile{Shell: "/bin/sh", Args: []string{"-i"}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.CloseTerminalSession(sessionID) })
+
+	waitForForeground(t, a, sessionID, func(p TerminalProcess) bool { return p.IsShell })
+	if err := writeToTerminalSession(sessionID, "sleep 30\n"); err != nil {
+		t.Fatal(err)
+	}
+	process := waitForForeground(t, a, sessionID, func(p TerminalProcess) bool { return !p.IsShell })
+	if process.Name != "sleep" || process.PID == process.ShellPID {
+		t.Errorf("Expected sleep in the foreground, got %+v", process)
+	}
+	if busy, err := a.IsTerminalBusy(sessionID); err != nil || !busy {
+		t.Errorf("Expected the terminal to be busy (%v)", err)
+	}
+
+	// The interrupt ends sleep and hands the terminal back to the shell
+	if err := a.SignalTerminalProcess(sessionID, "int"); err != nil {
+		t.Fatal(err)
+	}
+	waitForForeground(t, a, sessionID, func(p TerminalProcess) bool { return p.IsShell })
+}
+
+func TestSignalTerminalReportsExitSignal(t *testing.T) {
+	a := &App{}
+	sessionID := fmt.Sprintf("local-%d", time.Now().UnixNano())
+	if err := a.startLocalTerminal(sessionID, 24, 80, "", TerminalProfile{Shell: "sleep", Args: []string{"30"}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.CloseTerminalSession(sessionID) })
+
+	termSessionMu.RLock()
+	termSession := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+
+	process := waitForForeground(t, a, sessionID, func(p TerminalProcess) bool { return p.IsShell })
+	if process.Name != "sleep" {
+		t.Errorf("Expected sleep in the foreground, got %+v", process)
+	}
+	if err := a.SignalTerminalProcess(sessionID, "SIGTERM"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-termSession.stopChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the process to end")
+	}
+	if exitCode, signal := localExitStatus(termSession.LocalCmd.ProcessState); exitCode != -1 || signal != "SIGTERM" {
+		t.Errorf("Expected the process to be reported killed by SIGTERM, got %d %q", exitCode, signal)
+	}
+	if err := a.SignalTerminalProcess(sessionID, "SIGHUP"); err == nil {
+		t.Errorf("Expected an unsupported signal to be rejected")
+	}
+}
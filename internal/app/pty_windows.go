@@ -3,15 +3,18 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
 	"sync"
+	"syscall"
+	"unsafe"
 
 	"github.com/UserExistsError/conpty"
-	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/sys/windows"
 )
 
 // TerminalSessionWindows extends TerminalSession with Windows-specific fields
@@ -25,8 +28,8 @@ var (
 	windowsSessionsMu sync.RWMutex
 )
 
-// StartLocalTerminalSession starts a local PTY session using Windows ConPTY
-func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, initialDir string) error {
+// startLocalTerminal starts a local PTY session using Windows ConPTY
+func (a *App) startLocalTerminal(sessionID string, rows int, cols int, initialDir string, profile TerminalProfile) error {
 	// Guard: atomic check-and-mark with WRITE lock to prevent TOCTOU race condition
 	termSessionMu.Lock()
 	if _, exists := terminalSessions[sessionID]; exists {
@@ -48,8 +51,11 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 		}
 	}()
 
-	// Determine shell based on Windows environment
-	shell := os.Getenv("COMSPEC") // Usually C:\Windows\system32\cmd.exe
+	// Determine shell: the profile's, else based on Windows environment
+	shell := profile.Shell
+	if shell == "" {
+		shell = os.Getenv("COMSPEC") // Usually C:\Windows\system32\cmd.exe
+	}
 	if shell == "" {
 		// Try PowerShell first (more capable), fall back to cmd.exe
 		if _, err := exec.LookPath("pwsh.exe"); err == nil {
@@ -61,31 +67,22 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 		}
 	}
 
-	// Create ConPTY with initial size
-	cpty, err := conpty.Start(shell)
-	if err != nil {
-		return fmt.Errorf("failed to create ConPTY: %v", err)
+	// ConPTY takes a single command line
+	commandLine := syscall.EscapeArg(shell)
+	for _, arg := range profile.Args {
+		commandLine += " " + syscall.EscapeArg(arg)
 	}
 
-	// Create command
-	cmd := exec.Command(shell)
-
-	// Set initial working directory if provided and valid
-	if initialDir != "" {
-		if stat, err := os.Stat(initialDir); err == nil && stat.IsDir() {
-			cmd.Dir = initialDir
-		} else {
-			log.Printf("⚠️ Invalid initial directory %s, using default", initialDir)
-		}
-	}
+	// Create command (tracks the shell for the session; ConPTY runs the process)
+	cmd := exec.Command(shell, profile.Args...)
+	cmd.Dir = profileWorkingDir(initialDir, profile)
+	cmd.Env = buildProfileEnv(os.Environ(), profile)
 
-	// Set environment variables
-	// Note: Windows doesn't have VIRTUAL_ENV issues like Unix, but we keep the pattern
-	cleanEnv := make([]string, 0, len(os.Environ()))
-	for _, env := range os.Environ() {
-		cleanEnv = append(cleanEnv, env)
+	// Create ConPTY running the shell
+	cpty, err := conpty.Start(commandLine, conpty.ConPtyWorkDir(cmd.Dir), conpty.ConPtyEnv(cmd.Env))
+	if err != nil {
+		return fmt.Errorf("failed to create ConPTY: %v", err)
 	}
-	cmd.Env = cleanEnv
 
 	// Set initial ConPTY size
 	err = cpty.Resize(cols, rows)
@@ -98,15 +95,18 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 
 	// Create terminal session with UTF-8 safe buffer
 	termSession := &TerminalSession{
-		SessionID:   sessionID,
-		LocalCmd:    cmd,
-		LocalPTY:    nil, // Not used on Windows
-		LocalStdin:  cpty,
-		stopChan:    make(chan struct{}),
-		isConnected: true,
-		isLocal:     true,
-		utf8Buffer:  &UTF8SafeBuffer{}, // Prevent UTF-8 truncation in Windows terminal output
+		SessionID:  sessionID,
+		LocalCmd:   cmd,
+		LocalPTY:   nil, // Not used on Windows
+		LocalStdin: cpty,
+		stopChan:   make(chan struct{}),
+		isLocal:    true,
+		host:       "local",
+		rows:       rows,
+		cols:       cols,
+		utf8Buffer: &UTF8SafeBuffer{}, // Prevent UTF-8 truncation in Windows terminal output
 	}
+	termSession.isConnected.Store(true)
 
 	// Store Windows-specific session
 	winSession := &TerminalSessionWindows{
@@ -124,20 +124,26 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 	termSessionMu.Unlock()
 	sessionReady = true
 
+	a.runStartupCommands(termSession, profile.StartupCommands)
+
 	// Start output reader
 	go func() {
+		osc := &oscParser{}
 		defer func() {
 			if r := recover(); r != nil {
 				log.Printf("❌ PANIC RECOVERED in local terminal reader goroutine for session %s: %v", sessionID, r)
 			}
 			termSessionMu.Lock()
 			if ts, ok := terminalSessions[sessionID]; ok {
-				ts.isConnected = false
+				ts.isConnected.Store(false)
 			}
 			termSessionMu.Unlock()
 
 			// Flush any remaining bytes when session ends
-			if remaining := termSession.utf8Buffer.Flush(); remaining != "" {
+			if remaining := termSession.flushDecoder(&termSession.utf8Buffer); remaining != "" {
+				a.handleTerminalOutput(termSession, osc, remaining)
+			}
+			if remaining := osc.Flush(); remaining != "" {
 				a.emitTerminalOutput(sessionID, remaining)
 			}
 		}()
@@ -158,9 +164,9 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 				if n > 0 {
 					// Use UTF-8 safe buffer to prevent character truncation
 					// This is critical for Chinese/CJK characters that may be split across reads
-					completeUTF8 := termSession.utf8Buffer.AppendAndFlush(buffer[:n])
+					completeUTF8 := termSession.decode(&termSession.utf8Buffer, buffer[:n])
 					if completeUTF8 != "" {
-						a.emitTerminalOutput(sessionID, completeUTF8)
+						a.handleTerminalOutput(termSession, osc, completeUTF8)
 					}
 				}
 			}
@@ -175,24 +181,19 @@ func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, in
 			}
 		}()
 
-		// Wait for process to exit (ConPTY doesn't have cmd.Wait(), use pid monitoring)
-		// For now, we rely on the read loop detecting EOF
-		// TODO: Implement proper process monitoring via Windows API if needed
+		// Wait for the shell to exit (ConPTY runs the process, not cmd)
+		exitCode := -1
+		if code, err := cpty.Wait(context.Background()); err != nil {
+			log.Printf("⚠️ Failed to wait for ConPTY process: %v", err)
+		} else {
+			exitCode = int(code)
+		}
 
-		termSession.mu.Lock()
-		termSession.isConnected = false
-		termSession.mu.Unlock()
+		termSession.isConnected.Store(false)
 		termSession.stopOnce.Do(func() { close(termSession.stopChan) })
 
-		// Emit disconnection event to frontend
-		if a.ctx != nil {
-			wailsRuntime.EventsEmit(a.ctx, "terminal:disconnected", map[string]interface{}{
-				"sessionId": sessionID,
-				"reason":    "Process exited",
-			})
-		}
-
-		log.Printf("Local terminal session ended: %s", sessionID)
+		// Emit disconnection event to frontend with the shell's exit code
+		a.emitLocalTerminalExit(sessionID, exitCode, "")
 	}()
 
 	return nil
@@ -228,3 +229,88 @@ func closeLocalTerminal(termSession *TerminalSession) {
 		winSession.ConPTY.Close()
 	}
 }
+
+// getConPTY returns the ConPTY of a local Windows terminal
+func getConPTY(termSession *TerminalSession) (*conpty.ConPty, error) {
+	windowsSessionsMu.RLock()
+	winSession, exists := windowsSessions[termSession.SessionID]
+	windowsSessionsMu.RUnlock()
+
+	if !exists || winSession.ConPTY == nil {
+		return nil, fmt.Errorf("Windows ConPTY session not found")
+	}
+	return winSession.ConPTY, nil
+}
+
+// childProcesses returns a snapshot of running processes as parent PID -> children
+func childProcesses() (map[uint32][]windows.ProcessEntry32, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	children := make(map[uint32][]windows.ProcessEntry32)
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		children[entry.ParentProcessID] = append(children[entry.ParentProcessID], entry)
+	}
+	return children, nil
+}
+
+// foregroundProcess returns the newest descendant of the ConPTY shell.
+// Windows consoles have no foreground process group, so the most recently
+// started child (followed down to its own children) stands in for it.
+func foregroundProcess(termSession *TerminalSession) (TerminalProcess, error) {
+	cpty, err := getConPTY(termSession)
+	if err != nil {
+		return TerminalProcess{}, err
+	}
+	shellPID := cpty.Pid()
+
+	children, err := childProcesses()
+	if err != nil {
+		return TerminalProcess{}, fmt.Errorf("failed to list processes: %v", err)
+	}
+
+	process := TerminalProcess{PID: shellPID, ShellPID: shellPID, IsShell: true}
+	for pid := uint32(shellPID); len(children[pid]) > 0; {
+		entries := children[pid]
+		newest := entries[len(entries)-1]
+		process.PID = int(newest.ProcessID)
+		process.Name = windows.UTF16ToString(newest.ExeFile[:])
+		process.IsShell = false
+		pid = newest.ProcessID
+	}
+	return process, nil
+}
+
+// signalForegroundProcess emulates signals on Windows: SIGINT sends Ctrl+C
+// through the console, SIGTERM and SIGKILL terminate the foreground process
+func signalForegroundProcess(termSession *TerminalSession, signal string) error {
+	switch signal {
+	case "SIGINT":
+		cpty, err := getConPTY(termSession)
+		if err != nil {
+			return err
+		}
+		termSession.mu.Lock()
+		defer termSession.mu.Unlock()
+		_, err = cpty.Write([]byte{0x03})
+		return err
+	case "SIGTERM", "SIGKILL":
+		process, err := foregroundProcess(termSession)
+		if err != nil {
+			return err
+		}
+		handle, err := windows.OpenProcess(windows.PROCESS_TERMINATE, false, uint32(process.PID))
+		if err != nil {
+			return err
+		}
+		defer windows.CloseHandle(handle)
+		return windows.TerminateProcess(handle, 1)
+	default:
+		return fmt.Errorf("%s is not supported on Windows", signal)
+	}
+}
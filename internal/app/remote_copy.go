@@ -0,0 +1,292 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// errDirectUnreachable means the source server couldn't log in to the target for a direct push
+var errDirectUnreachable = errors.New("source server can't connect to the target")
+
+// errDirectIncomplete means a direct copy arrived with different files than the source has
+var errDirectIncomplete = errors.New("direct copy doesn't match the source")
+
+// directProgressInterval is how often a direct copy's staged size is checked
+const directProgressInterval = time.Second
+
+// QueueRemoteCopy queues a copy of a remote file or directory from one SSH
+// session into targetDir on another. The data streams through the app between
+// the two SFTP connections without a local copy. With direct set, the source
+// server first tries to push to the target itself over ssh, which needs it to
+// reach and log in to the target non-interactively (e.g. with agent forwarding).
+func (a *App) QueueRemoteCopy(sourceSessionID string, sourcePath string, targetSessionID string, targetDir string, direct bool) (TransferJob, error) {
+	if sourceSessionID == targetSessionID {
+		return TransferJob{}, fmt.Errorf("source and target are the same session")
+	}
+	if _, err := getConnectedSSHSession(sourceSessionID); err != nil {
+		return TransferJob{}, err
+	}
+	if _, err := getConnectedSSHSession(targetSessionID); err != nil {
+		return TransferJob{}, err
+	}
+	return a.enqueueTransfer(TransferJob{
+		SessionID:       sourceSessionID,
+		Direction:       "copy",
+		Source:          sourcePath,
+		TargetDir:       strings.TrimSuffix(targetDir, "/"),
+		TargetSessionID: targetSessionID,
+		Direct:          direct,
+	}), nil
+}
+
+// runCopyJob copies a remote file or directory to the job's target session,
+// pushing directly if asked and possible, otherwise streaming it through the app
+func (a *App) runCopyJob(ctx context.Context, job *transferJob, info TransferJob, resumed bool) error {
+	sourceClient, err := getSFTPClient(info.SessionID)
+	if err != nil {
+		return err
+	}
+	targetClient, err := getSFTPClient(info.TargetSessionID)
+	if err != nil {
+		return err
+	}
+	sourcePath := resolveRemotePath(sourceClient, info.Source)
+	sourceInfo, err := sourceClient.Stat(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat source path: %v", err)
+	}
+	targetPath := resolveRemotePath(targetClient, info.TargetDir) + "/" + path.Base(sourcePath)
+
+	// Settings of the host written to apply, as for uploads
+	opts := a.jobTreeTransfer(job, info.TargetSessionID, resumed)
+	opts.source = remoteSource{client: sourceClient}
+
+	if info.Direct {
+		if opts.symlinks == SymlinkSkip {
+			log.Printf("⚠️ [Transfers] Direct copy can't skip symlinks, streaming %s instead", sourcePath)
+		} else {
+			err := a.pushDirect(ctx, job, info, sourceClient, sourcePath, sourceInfo, targetClient, targetPath, opts.symlinks)
+			if !errors.Is(err, errDirectUnreachable) && !errors.Is(err, errDirectIncomplete) {
+				return err
+			}
+			log.Printf("⚠️ [Transfers] %v, streaming %s instead", err, sourcePath)
+		}
+	}
+
+	if !sourceInfo.IsDir() {
+		fc := opts.fileCopy()
+		fc.start = func(offset, total int64) {
+			transferQueue.mu.Lock()
+			startJobLocked(job, targetPath, total, offset)
+			transferQueue.mu.Unlock()
+		}
+		_, err := resumableUpload(ctx, targetClient, sourcePath, targetPath, fc)
+		return err
+	}
+
+	if err := targetClient.MkdirAll(targetPath); err != nil {
+		return fmt.Errorf("failed to create target directory: %v", err)
+	}
+	entries, errs := listRemoteTree(sourceClient, sourcePath, opts.symlinks)
+	startTreeJob(job, targetPath, entries)
+
+	errs = append(errs, uploadTree(ctx, targetClient, entries, targetPath, opts)...)
+	return finishTreeJob(job, errs)
+}
+
+// pushDirect has the source server send sourcePath to the target with tar
+// over ssh. The copy is extracted into a staging directory next to targetPath,
+// checked against the source tree and only then moved into place. It returns
+// errDirectUnreachable if ssh couldn't connect or log in, and
+// errDirectIncomplete if the staged copy doesn't match the source, so the
+// caller can stream the copy instead.
+func (a *App) pushDirect(ctx context.Context, job *transferJob, info TransferJob, sourceClient *sftp.Client, sourcePath string, sourceInfo os.FileInfo, targetClient *sftp.Client, targetPath string, symlinks string) error {
+	target, err := getConnectedSSHSession(info.TargetSessionID)
+	if err != nil {
+		return err
+	}
+
+	var entries []treeEntry
+	if sourceInfo.IsDir() {
+		entries, _ = listRemoteTree(sourceClient, sourcePath, symlinks)
+	} else {
+		entries = []treeEntry{{Path: sourcePath, Rel: ".", Size: sourceInfo.Size()}}
+	}
+	files, total := treeTotals(entries)
+	transferQueue.mu.Lock()
+	job.info.IsDir = sourceInfo.IsDir()
+	startJobLocked(job, targetPath, total, 0)
+	transferQueue.mu.Unlock()
+
+	// Staged where the target server's SFTP sees it, so a push that reached
+	// another machine can't pass the check below
+	staging := targetPath + ".direct" + partSuffix
+	targetClient.RemoveAll(staging)
+	if err := targetClient.Mkdir(staging); err != nil {
+		return fmt.Errorf("failed to create staging directory: %v", err)
+	}
+	defer targetClient.RemoveAll(staging)
+	staged := staging + "/" + path.Base(sourcePath)
+
+	command := directPushCommand(sourcePath, staging, target.Config, symlinks)
+	log.Printf("📤 [Transfers] Pushing %s directly from the source server", sourcePath)
+	var status, output bytes.Buffer
+	stopProgress := a.pollStagedProgress(job, targetClient, staged, total)
+	exit, err := runRemoteCommand(ctx, info.SessionID, command, "", &status, &output)
+	reported := stopProgress()
+	if err != nil {
+		return err
+	}
+	message := strings.TrimSpace(output.String())
+	switch {
+	case exit.Cancelled:
+		return ctx.Err()
+	case exit.ExitCode == 255:
+		return fmt.Errorf("%w: %s", errDirectUnreachable, message)
+	case exit.ExitCode != 0:
+		return fmt.Errorf("direct copy failed (exit %d): %s", exit.ExitCode, message)
+	}
+
+	// ssh only reports the extracting side, so the source's tar status comes on stdout
+	if tarStatus := strings.TrimSpace(status.String()); tarStatus != "0" {
+		return fmt.Errorf("direct copy failed (tar exit %s): %s", tarStatus, message)
+	}
+	var copiedFiles int
+	var copiedBytes int64
+	if sourceInfo.IsDir() {
+		copied, errs := listRemoteTree(targetClient, staged, SymlinkCopy)
+		if len(errs) > 0 {
+			return fmt.Errorf("%w: %v", errDirectIncomplete, errs[0])
+		}
+		copiedFiles, copiedBytes = treeTotals(copied)
+	} else if copied, err := targetClient.Lstat(staged); err == nil && copied.Mode().IsRegular() {
+		copiedFiles, copiedBytes = 1, copied.Size()
+	}
+	if copiedFiles != files || copiedBytes != total {
+		return fmt.Errorf("%w: %d files, %d bytes instead of %d files, %d bytes", errDirectIncomplete, copiedFiles, copiedBytes, files, total)
+	}
+
+	if err := moveStagedCopy(targetClient, staged, targetPath, sourceInfo.IsDir()); err != nil {
+		return err
+	}
+	a.jobProgress(job)(total - reported)
+	return nil
+}
+
+// treeTotals returns the number of files and symlinks in a tree and the size
+// of its files
+func treeTotals(entries []treeEntry) (int, int64) {
+	var files int
+	var size int64
+	for _, entry := range entries {
+		if entry.IsDir {
+			continue
+		}
+		files++
+		if entry.Link == "" {
+			size += entry.Size
+		}
+	}
+	return files, size
+}
+
+// pollStagedProgress reports the growing size of a direct copy's staged files
+// until the returned function is called, which returns the bytes reported
+func (a *App) pollStagedProgress(job *transferJob, targetClient *sftp.Client, staged string, total int64) func() int64 {
+	progress := a.jobProgress(job)
+	stop := make(chan struct{})
+	done := make(chan int64)
+	go func() {
+		var reported int64
+		ticker := time.NewTicker(directProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				entries, _ := listRemoteTree(targetClient, staged, SymlinkCopy)
+				if _, size := treeTotals(entries); size > reported && size <= total {
+					progress(size - reported)
+					reported = size
+				}
+			case <-stop:
+				done <- reported
+				return
+			}
+		}
+	}()
+	return func() int64 {
+		close(stop)
+		return <-done
+	}
+}
+
+// moveStagedCopy moves a verified direct copy into place. A directory that
+// already exists gets the staged files moved into it, replacing files of the
+// same name, as a streamed copy would.
+func moveStagedCopy(targetClient *sftp.Client, staged string, targetPath string, isDir bool) error {
+	if !isDir {
+		return replaceRemoteFile(targetClient, staged, targetPath)
+	}
+	if _, err := targetClient.Lstat(targetPath); os.IsNotExist(err) {
+		if err := targetClient.Rename(staged, targetPath); err != nil {
+			return fmt.Errorf("failed to move %s into place: %v", targetPath, err)
+		}
+		return nil
+	}
+	entries, errs := listRemoteTree(targetClient, staged, SymlinkCopy)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	for _, entry := range entries {
+		if entry.Rel == "." {
+			continue
+		}
+		dest := targetPath + "/" + filepath.ToSlash(entry.Rel)
+		if entry.IsDir {
+			if err := targetClient.MkdirAll(dest); err != nil {
+				return fmt.Errorf("failed to create %s: %v", dest, err)
+			}
+			continue
+		}
+		if err := replaceRemoteFile(targetClient, entry.Path, dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// directPushCommand builds the shell command that makes the source server tar
+// sourcePath into targetDir on the target host, following symlinks unless the
+// policy is to copy them. It runs under sh and prints tar's exit status on
+// stdout, with everything else on stderr.
+func directPushCommand(sourcePath string, targetDir string, target SSHConfigEntry, symlinks string) string {
+	host := target.Hostname
+	if host == "" {
+		host = target.Host
+	}
+	if target.User != "" {
+		host = target.User + "@" + host
+	}
+	port := target.Port
+	if port == 0 {
+		port = 22
+	}
+	create := "-chf"
+	if symlinks == SymlinkCopy {
+		create = "-cf"
+	}
+	extract := "tar -xf - -C " + shellQuote(targetDir)
+	pipeline := fmt.Sprintf("{ { tar %s - -C %s %s; echo $? >&3; } | ssh -o BatchMode=yes -p %d -- %s %s >&2; } 3>&1",
+		create, shellQuote(path.Dir(sourcePath)), shellQuote(path.Base(sourcePath)), port, shellQuote(host), shellQuote(extract))
+	return "sh -c " + shellQuote(pipeline)
+}
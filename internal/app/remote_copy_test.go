@@ -0,0 +1,196 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRemoteCopyStreamsDirectory(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	newTestSFTPClient(t, "copy-source")
+	newTestSFTPClient(t, "copy-target")
+	a := &App{}
+
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(srcDir, "site", "assets"), 0755)
+	files := map[string][]byte{
+		"index.html":    []byte("<html></html>"),
+		"assets/app.js": bytes.Repeat([]byte("x"), 300000),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(srcDir, "site", name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job := a.enqueueTransfer(TransferJob{
+		SessionID:       "copy-source",
+		Direction:       "copy",
+		Source:          filepath.Join(srcDir, "site"),
+		TargetDir:       dstDir,
+		TargetSessionID: "copy-target",
+	})
+	done := waitForTransfer(t, a, job.ID, "completed")
+
+	if done.Target != filepath.Join(dstDir, "site") || done.TotalBytes != 300013 || done.Transferred != 300013 {
+		t.Errorf("Unexpected job %+v", done)
+	}
+	for name, want := range files {
+		if got, err := os.ReadFile(filepath.Join(dstDir, "site", name)); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s differs from the source (%v)", name, err)
+		}
+	}
+}
+
+func TestRetryRemoteCopy(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	newTestSSHSession(t, "retry-source", "source")
+	newTestSSHSession(t, "retry-target", "target")
+	newTestSFTPClient(t, "retry-source")
+	newTestSFTPClient(t, "retry-target")
+	a := &App{}
+
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	job := a.enqueueTransfer(TransferJob{
+		SessionID:       "retry-source",
+		Direction:       "copy",
+		Source:          filepath.Join(srcDir, "notes.txt"),
+		TargetDir:       dstDir,
+		TargetSessionID: "retry-target",
+	})
+	waitForTransfer(t, a, job.ID, "completed")
+	os.Remove(filepath.Join(dstDir, "notes.txt"))
+
+	retried, err := a.RetryTransfer(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retried.TargetSessionID != "retry-target" {
+		t.Errorf("Expected the retry to keep the target session, got %+v", retried)
+	}
+	waitForTransfer(t, a, retried.ID, "completed")
+	if got, err := os.ReadFile(filepath.Join(dstDir, "notes.txt")); err != nil || string(got) != "notes" {
+		t.Errorf("Expected the retry to copy the file again, got %q (%v)", got, err)
+	}
+}
+
+func TestDirectPushCommand(t *testing.T) {
+	target := SSHConfigEntry{Host: "web", Hostname: "10.0.0.2", User: "deploy", Port: 2222}
+	for symlinks, create := range map[string]string{"": "-chf", SymlinkFollow: "-chf", SymlinkCopy: "-cf"} {
+		got := directPushCommand("/srv/it's here", "/var/www", target, symlinks)
+		pipeline := "{ { tar " + create + ` - -C '/srv' 'it'\''s here'; echo $? >&3; } | ssh -o BatchMode=yes -p 2222 -- 'deploy@10.0.0.2' 'tar -xf - -C '\''/var/www'\''' >&2; } 3>&1`
+		if want := "sh -c " + shellQuote(pipeline); got != want {
+			t.Errorf("Policy %q: got %s", symlinks, got)
+		}
+	}
+}
+
+func TestDirectPushCommandRuns(t *testing.T) {
+	// A stand-in ssh that runs the remote command locally
+	bin := t.TempDir()
+	fakeSSH := "#!/bin/sh\nfor arg; do last=$arg; done\nexec sh -c \"$last\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(fakeSSH), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(srcDir, "site"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "site", "index.html"), []byte("<html></html>"), 0644)
+	os.Symlink("index.html", filepath.Join(srcDir, "site", "home.html"))
+
+	push := func(source string) string {
+		var status bytes.Buffer
+		cmd := exec.Command("sh", "-c", directPushCommand(source, dstDir, SSHConfigEntry{Host: "target"}, ""))
+		cmd.Stdout = &status
+		if err := cmd.Run(); err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(status.String())
+	}
+
+	// The default policy follows links, as streamed copies do
+	if status := push(filepath.Join(srcDir, "site")); status != "0" {
+		t.Fatalf("Expected tar to succeed, got status %q", status)
+	}
+	if info, err := os.Lstat(filepath.Join(dstDir, "site", "home.html")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("Expected home.html to be copied as a file, got %v (%v)", info, err)
+	}
+	if status := push(filepath.Join(srcDir, "missing")); status == "0" || status == "" {
+		t.Errorf("Expected a failing tar status, got %q", status)
+	}
+}
+
+func TestDirectCopyVerifiesStagedTree(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	newTestSSHSession(t, "direct-source", "source")
+	newTestSSHSession(t, "direct-target", "target")
+	newTestSFTPClient(t, "direct-source")
+	newTestSFTPClient(t, "direct-target")
+	a := &App{}
+
+	srcDir := t.TempDir()
+	os.MkdirAll(filepath.Join(srcDir, "site", "assets"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "site", "index.html"), []byte("<html></html>"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "site", "assets", "app.js"), bytes.Repeat([]byte("x"), 300000), 0644)
+
+	bin := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	copyDirect := func(fakeSSH string, dstDir string) TransferJob {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(fakeSSH), 0755); err != nil {
+			t.Fatal(err)
+		}
+		job := a.enqueueTransfer(TransferJob{
+			SessionID:       "direct-source",
+			Direction:       "copy",
+			Source:          filepath.Join(srcDir, "site"),
+			TargetDir:       dstDir,
+			TargetSessionID: "direct-target",
+			Direct:          true,
+		})
+		return waitForTransfer(t, a, job.ID, "completed")
+	}
+	checkCopy := func(dstDir string) {
+		t.Helper()
+		if got, err := os.ReadFile(filepath.Join(dstDir, "site", "assets", "app.js")); err != nil || len(got) != 300000 {
+			t.Errorf("Expected app.js to be copied, got %d bytes (%v)", len(got), err)
+		}
+		if got, err := os.ReadFile(filepath.Join(dstDir, "site", "index.html")); err != nil || string(got) != "<html></html>" {
+			t.Errorf("Expected index.html to replace the old one, got %q (%v)", got, err)
+		}
+		if names, _ := filepath.Glob(filepath.Join(dstDir, "*"+partSuffix)); len(names) != 0 {
+			t.Errorf("Expected the staging directory to be removed, got %v", names)
+		}
+	}
+
+	// A retry into a directory that already exists merges into it
+	dstDir := t.TempDir()
+	os.MkdirAll(filepath.Join(dstDir, "site"), 0755)
+	os.WriteFile(filepath.Join(dstDir, "site", "index.html"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(dstDir, "site", "keep.txt"), []byte("keep"), 0644)
+	done := copyDirect("#!/bin/sh\nfor arg; do last=$arg; done\nexec sh -c \"$last\"\n", dstDir)
+	checkCopy(dstDir)
+	if _, err := os.Stat(filepath.Join(dstDir, "site", "keep.txt")); err != nil {
+		t.Errorf("Expected files only in the target to stay: %v", err)
+	}
+	if done.TotalBytes != 300013 || done.Transferred != 300013 {
+		t.Errorf("Unexpected progress %d/%d", done.Transferred, done.TotalBytes)
+	}
+
+	// A push that lands somewhere else leaves the staging directory empty,
+	// so the copy is streamed instead
+	dstDir = t.TempDir()
+	done = copyDirect("#!/bin/sh\ncat >/dev/null\n", dstDir)
+	checkCopy(dstDir)
+	if done.Transferred != 300013 {
+		t.Errorf("Unexpected progress %d/%d", done.Transferred, done.TotalBytes)
+	}
+}
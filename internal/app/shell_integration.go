@@ -0,0 +1,279 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// MaxOSCLength caps how many bytes an unterminated OSC sequence may buffer
+// before the parser gives up and passes it through as plain output.
+const MaxOSCLength = 8 * 1024
+
+// oscSequence is a parsed Operating System Command (ESC ] code ; payload ST)
+type oscSequence struct {
+	Code    string // e.g. "7", "133", "633"
+	Payload string // everything after the first ';'
+	Start   int    // Offset of the sequence in the output returned by Feed
+	End     int    // Offset just past the terminator
+}
+
+// oscParser extracts OSC sequences from a terminal output stream.
+// Sequences can be split across reads, so an unterminated sequence is held
+// back until its terminator (BEL or ESC \) arrives.
+//
+// Thread safety: NOT thread-safe. Each output reader goroutine owns its own parser.
+type oscParser struct {
+	// Held-back bytes: an unterminated sequence from its ESC ], or a lone
+	// trailing ESC. Reads only append, as OSC 52 payloads can be megabytes.
+	pending bytes.Buffer
+}
+
+// Feed scans data for OSC sequences. It returns the output to display
+// (sequences are left in place for xterm.js) and the sequences found.
+func (p *oscParser) Feed(data string) (string, []oscSequence) {
+	if p.pending.Len() > 1 {
+		held := p.pending.Bytes()
+		// Only the new bytes can end the held sequence, with an ESC held at the end
+		scan := data
+		if held[len(held)-1] == '\x1b' {
+			scan = "\x1b" + data
+		}
+		if end, _ := findOSCTerminator(scan); end < 0 {
+			// The limit only depends on the code, so a short prefix is enough
+			prefix := string(held[2:min(len(held), 8)]) + data[:min(len(data), 8)]
+			if limit := oscLengthLimit(prefix); p.pending.Len()+len(data) > limit {
+				log.Printf("⚠️ [OSC] Unterminated sequence exceeded %d bytes, passing through", limit)
+				data = p.pending.String() + data
+				p.pending.Reset()
+				return data, nil
+			}
+			p.pending.WriteString(data)
+			return "", nil
+		}
+	}
+	if p.pending.Len() > 0 {
+		data = p.pending.String() + data
+		p.pending.Reset()
+	}
+
+	var seqs []oscSequence
+	pos := 0
+	for {
+		start := strings.Index(data[pos:], "\x1b]")
+		if start < 0 {
+			break
+		}
+		start += pos
+		bodyStart := start + 2
+
+		end, termLen := findOSCTerminator(data[bodyStart:])
+		if end < 0 {
+			if limit := oscLengthLimit(data[bodyStart:]); len(data)-start > limit {
+				// Not a real OSC sequence (or a runaway one); stop holding output back
+				log.Printf("⚠️ [OSC] Unterminated sequence exceeded %d bytes, passing through", limit)
+				return data, seqs
+			}
+			p.pending.WriteString(data[start:])
+			return data[:start], seqs
+		}
+
+		body := data[bodyStart : bodyStart+end]
+		pos = bodyStart + end + termLen
+		seq := oscSequence{Code: body, Start: start, End: pos}
+		if i := strings.IndexByte(body, ';'); i >= 0 {
+			seq.Code, seq.Payload = body[:i], body[i+1:]
+		}
+		seqs = append(seqs, seq)
+	}
+
+	// A trailing ESC may be the first half of an OSC introducer
+	if strings.HasSuffix(data, "\x1b") {
+		p.pending.WriteString("\x1b")
+		return data[:len(data)-1], seqs
+	}
+
+	return data, seqs
+}
+
+// Flush returns any held-back bytes. Called when the session ends.
+func (p *oscParser) Flush() string {
+	result := p.pending.String()
+	p.pending.Reset()
+	return result
+}
+
+// oscLengthLimit returns how long an unterminated sequence with this body may grow
+func oscLengthLimit(body string) int {
+	if strings.HasPrefix(body, "52;") {
+		return maxClipboardOSCLength // OSC 52 carries base64 clipboard content
+	}
+	return MaxOSCLength
+}
+
+// findOSCTerminator returns the index of the BEL or ST (ESC \) ending an OSC
+// body, and the terminator's length, or -1 if the body is incomplete
+func findOSCTerminator(s string) (int, int) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\x07':
+			return i, 1
+		case '\x1b':
+			if i+1 < len(s) && s[i+1] == '\\' {
+				return i, 2
+			}
+			if i+1 == len(s) {
+				return -1, 0 // ESC at the end; wait for the next read
+			}
+		}
+	}
+	return -1, 0
+}
+
+// cwdFromOSC extracts a working directory from the sequences shells and
+// terminals use to report it:
+//
+//	OSC 7 ; file://host/path          (standard, used by zsh/fish/vte)
+//	OSC 633 ; P ; Cwd=path            (VS Code shell integration)
+//	OSC 1337 ; CurrentDir=path        (iTerm2)
+//	OSC 9 ; 9 ; path                  (ConEmu / Windows Terminal)
+func cwdFromOSC(seq oscSequence) (string, bool) {
+	switch seq.Code {
+	case "7":
+		return parseFileURLPath(seq.Payload)
+	case "633":
+		if rest, ok := strings.CutPrefix(seq.Payload, "P;Cwd="); ok && rest != "" {
+			return rest, true
+		}
+	case "1337":
+		if rest, ok := strings.CutPrefix(seq.Payload, "CurrentDir="); ok && rest != "" {
+			return rest, true
+		}
+	case "9":
+		if rest, ok := strings.CutPrefix(seq.Payload, "9;"); ok {
+			rest = strings.Trim(rest, "\"")
+			if rest != "" {
+				return rest, true
+			}
+		}
+	}
+	return "", false
+}
+
+// parseFileURLPath returns the path of a file://host/path URL
+func parseFileURLPath(raw string) (string, bool) {
+	if !strings.HasPrefix(raw, "file://") {
+		return "", false
+	}
+	if u, err := url.Parse(raw); err == nil && u.Path != "" {
+		return u.Path, true
+	}
+	// Shells don't always percent-encode the path; take it verbatim
+	rest := raw[len("file://"):]
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return rest[i:], true
+	}
+	return "", false
+}
+
+// handleShellIntegration updates session state from the shell integration
+// sequences in a chunk of output: working directory reports and OSC 133
+// command markers. output and seqs come from oscParser.Feed.
+func (a *App) handleShellIntegration(termSession *TerminalSession, output string, seqs []oscSequence) {
+	var finished []CommandRecord
+	cwdChanged := false
+
+	termSession.shellMu.Lock()
+	tracker := &termSession.commands
+	pos := 0
+	for _, seq := range seqs {
+		tracker.captureInput(output[pos:seq.Start])
+		pos = seq.End
+
+		if cwd, ok := cwdFromOSC(seq); ok {
+			cwdChanged = cwdChanged || termSession.cwd != cwd
+			termSession.cwd = cwd
+		} else if marker, ok := semanticPromptMarker(seq); ok {
+			if record := tracker.handleMarker(termSession, marker, seq); record != nil {
+				finished = append(finished, *record)
+			}
+		}
+	}
+	tracker.captureInput(output[pos:])
+	cwd := termSession.cwd
+	termSession.shellMu.Unlock()
+
+	if cwdChanged && a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "terminal:cwd-changed", map[string]interface{}{
+			"sessionId": termSession.SessionID,
+			"cwd":       cwd,
+			"host":      termSession.host,
+			"isLocal":   termSession.isLocal,
+		})
+	}
+
+	for _, record := range finished {
+		a.recordFinishedCommand(record)
+	}
+}
+
+// GetTerminalCwd returns the last working directory reported by the terminal's shell.
+// Returns an empty string if the shell hasn't reported one (no shell integration).
+func (a *App) GetTerminalCwd(sessionID string) (string, error) {
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+
+	termSession.shellMu.Lock()
+	defer termSession.shellMu.Unlock()
+	return termSession.cwd, nil
+}
+
+// shellIntegrationScript is typed into bash or zsh to install prompt hooks that
+// report the working directory (OSC 7) and mark prompts and commands with
+// FinalTerm/OSC 133 semantic prompt sequences:
+//
+//	133;A  prompt start        133;B  prompt end (command input starts)
+//	133;C  command executing   133;D;<exit>  command finished
+//	633;E;<command line>       command text (VS Code extension)
+//
+// bash has no preexec hook, so a DEBUG trap stands in for it, running after any
+// DEBUG trap the user already has (bash-preexec, direnv, ...). __xfm_status runs
+// first in PROMPT_COMMAND to capture $? and disarm the trap, and __xfm_precmd
+// runs last to re-arm it, so the user's own PROMPT_COMMAND doesn't look like a
+// new command. The leading space keeps the script out of history with
+// HISTCONTROL=ignorespace (bash) or HIST_IGNORE_SPACE (zsh).
+var shellIntegrationScript = " " + strings.Join([]string{
+	`__xfm_osc7() { printf '\033]7;file://%s%s\033\\' "${HOSTNAME:-${HOST:-localhost}}" "$PWD"; }`,
+	`__xfm_precmd() { local s=$?; [ -n "$BASH_VERSION" ] && s=$__xfm_ret; if [ -n "$__xfm_running" ]; then printf '\033]133;D;%s\007' "$s"; __xfm_running=; fi; __xfm_osc7; printf '\033]133;A\007'; __xfm_ready=1; }`,
+	`if [ -n "$ZSH_VERSION" ]; then __xfm_preexec() { __xfm_running=1; printf '\033]633;E;%s\007\033]133;C\007' "$1"; }`,
+	`autoload -Uz add-zsh-hook && add-zsh-hook precmd __xfm_precmd && add-zsh-hook preexec __xfm_preexec`,
+	`case "$PS1" in *'133;B'*) ;; *) PS1="$PS1"$'%{\e]133;B\a%}' ;; esac`,
+	`elif [ -n "$BASH_VERSION" ]; then __xfm_status() { __xfm_ret=$?; __xfm_ready=; }`,
+	`__xfm_preexec() { [ -n "$__xfm_ready" ] || return 0; case "$BASH_COMMAND" in __xfm_*) return 0 ;; esac; __xfm_ready=; __xfm_running=1; printf '\033]633;E;%s\007\033]133;C\007' "$(HISTTIMEFORMAT= builtin history 1 | sed 's/^ *[0-9]* *//')"; }`,
+	`__xfm_trap() { local nl=$'\n'; eval "set -- $1"; case "$3" in *__xfm_preexec*) ;; *) trap -- "${3:+$3$nl}__xfm_preexec" DEBUG ;; esac; }; __xfm_trap "$(trap -p DEBUG)"`,
+	`case "$PROMPT_COMMAND" in *__xfm_precmd*) ;; *) PROMPT_COMMAND="__xfm_status;${PROMPT_COMMAND:+$PROMPT_COMMAND;}__xfm_precmd" ;; esac`,
+	`case "$PS1" in *'133;B'*) ;; *) PS1="$PS1"'\[\033]133;B\007\]' ;; esac`,
+	`fi`,
+	`__xfm_osc7`,
+}, "; ")
+
+// GetShellIntegrationScript returns the bash/zsh hook so users can add it to their rc files
+func (a *App) GetShellIntegrationScript() string {
+	return strings.TrimSpace(shellIntegrationScript)
+}
+
+// EnableShellIntegration installs the working directory and command tracking
+// hooks into the terminal's running shell (bash or zsh) by typing them at the prompt
+func (a *App) EnableShellIntegration(sessionID string) error {
+	log.Printf("🐚 Enabling shell integration for terminal %s", sessionID)
+	return writeToTerminalSession(sessionID, shellIntegrationScript+"\r")
+}
@@ -0,0 +1,256 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestOSCParser_CompleteSequence(t *testing.T) {
+	p := &oscParser{}
+
+	out, seqs := p.Feed("before\x1b]7;file://host/home/user\x07after")
+	if out != "before\x1b]7;file://host/home/user\x07after" {
+		t.Errorf("Expected output to be passed through unchanged, got %q", out)
+	}
+	if len(seqs) != 1 || seqs[0].Code != "7" || seqs[0].Payload != "file://host/home/user" {
+		t.Errorf("Unexpected sequences: %+v", seqs)
+	}
+}
+
+func TestOSCParser_SplitAcrossReads(t *testing.T) {
+	p := &oscParser{}
+
+	out1, seqs1 := p.Feed("$ cd /tmp\r\n\x1b]7;file://ho")
+	if out1 != "$ cd /tmp\r\n" {
+		t.Errorf("Expected text before the sequence, got %q", out1)
+	}
+	if len(seqs1) != 0 {
+		t.Errorf("Expected no sequences yet, got %+v", seqs1)
+	}
+
+	// Terminator split between ESC and backslash
+	out2, seqs2 := p.Feed("st/tmp\x1b")
+	if out2 != "" || len(seqs2) != 0 {
+		t.Errorf("Expected everything held back, got %q %+v", out2, seqs2)
+	}
+
+	out3, seqs3 := p.Feed("\\$ ")
+	if out3 != "\x1b]7;file://host/tmp\x1b\\$ " {
+		t.Errorf("Expected reassembled sequence, got %q", out3)
+	}
+	if len(seqs3) != 1 || seqs3[0].Payload != "file://host/tmp" {
+		t.Errorf("Unexpected sequences: %+v", seqs3)
+	}
+}
+
+func TestOSCParser_TrailingEscape(t *testing.T) {
+	p := &oscParser{}
+
+	out, _ := p.Feed("text\x1b")
+	if out != "text" {
+		t.Errorf("Expected trailing ESC to be held, got %q", out)
+	}
+
+	// Not an OSC after all (CSI sequence)
+	out, seqs := p.Feed("[0m")
+	if out != "\x1b[0m" || len(seqs) != 0 {
+		t.Errorf("Expected CSI to pass through, got %q %+v", out, seqs)
+	}
+}
+
+func TestOSCParser_LargeClipboardInChunks(t *testing.T) {
+	p := &oscParser{}
+	payload := strings.Repeat("QUJD", 500000) // 2 MB of base64
+	stream := "before\x1b]52;c;" + payload + "\x1b\\after"
+
+	// 4 KB reads, with one between the ESC and the backslash of the terminator
+	split := strings.Index(stream, "\x1b\\") + 1
+	var out strings.Builder
+	var seqs []oscSequence
+	for i := 0; i < len(stream); {
+		end := min(i+4096, len(stream))
+		if i < split && end > split {
+			end = split
+		}
+		chunk, found := p.Feed(stream[i:end])
+		i = end
+		for _, seq := range found {
+			seq.Start += out.Len()
+			seqs = append(seqs, seq)
+		}
+		out.WriteString(chunk)
+	}
+	if out.String() != stream {
+		t.Errorf("Expected the stream to pass through unchanged")
+	}
+	if len(seqs) != 1 || seqs[0].Code != "52" || seqs[0].Payload != "c;"+payload || seqs[0].Start != len("before") {
+		t.Errorf("Expected one clipboard sequence, got %d", len(seqs))
+	}
+}
+
+func TestOSCParser_LargeClipboardInSmallReads(t *testing.T) {
+	p := &oscParser{}
+	payload := strings.Repeat("QUJD", 750000) // 3 MB of base64
+	stream := "\x1b]52;c;" + payload + "\x07"
+
+	// Re-copying the held bytes on every 64-byte read would take minutes
+	start := time.Now()
+	var seqs []oscSequence
+	for i := 0; i < len(stream); i += 64 {
+		_, found := p.Feed(stream[i:min(i+64, len(stream))])
+		seqs = append(seqs, found...)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected small reads to stay linear, took %v", elapsed)
+	}
+	if len(seqs) != 1 || seqs[0].Payload != "c;"+payload {
+		t.Errorf("Expected one clipboard sequence, got %d", len(seqs))
+	}
+}
+
+func TestOSCParser_RunawaySequence(t *testing.T) {
+	p := &oscParser{}
+
+	p.Feed("\x1b]7;")
+	big := make([]byte, MaxOSCLength+1)
+	for i := range big {
+		big[i] = 'x'
+	}
+	out, _ := p.Feed(string(big))
+	if len(out) != len(big)+4 {
+		t.Errorf("Expected unterminated sequence to be flushed, got %d bytes", len(out))
+	}
+	if p.Flush() != "" {
+		t.Errorf("Expected no pending bytes after giving up")
+	}
+}
+
+func TestCwdFromOSC(t *testing.T) {
+	cases := []struct {
+		seq      oscSequence
+		expected string
+		ok       bool
+	}{
+		{oscSequence{Code: "7", Payload: "file://host/home/user/my%20dir"}, "/home/user/my dir", true},
+		{oscSequence{Code: "7", Payload: "file://host/tmp/100%"}, "/tmp/100%", true},
+		{oscSequence{Code: "633", Payload: "P;Cwd=/srv/app"}, "/srv/app", true},
+		{oscSequence{Code: "1337", Payload: "CurrentDir=/var/log"}, "/var/log", true},
+		{oscSequence{Code: "9", Payload: "9;\"C:\\Users\\me\""}, "C:\\Users\\me", true},
+		{oscSequence{Code: "0", Payload: "window title"}, "", false},
+		{oscSequence{Code: "7", Payload: "http://host/path"}, "", false},
+	}
+
+	for _, c := range cases {
+		cwd, ok := cwdFromOSC(c.seq)
+		if ok != c.ok || cwd != c.expected {
+			t.Errorf("cwdFromOSC(%+v) = %q, %v; expected %q, %v", c.seq, cwd, ok, c.expected, c.ok)
+		}
+	}
+}
+
+func TestHandleShellIntegration_CommandRecords(t *testing.T) {
+	// Keep the delayed history save away from the real config directory
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	commandHistory.mu.Lock()
+	savedRecords, savedLoaded := commandHistory.records, commandHistory.loaded
+	commandHistory.loaded = true
+	commandHistory.records = nil
+	commandHistory.mu.Unlock()
+	// Runs before the environment is restored, so no save can reach the real directory
+	t.Cleanup(func() {
+		commandHistory.mu.Lock()
+		defer commandHistory.mu.Unlock()
+		if commandHistory.saveTimer != nil {
+			commandHistory.saveTimer.Stop()
+			commandHistory.saveTimer = nil
+		}
+		commandHistory.records, commandHistory.loaded = savedRecords, savedLoaded
+	})
+
+	a := &App{}
+	ts := &TerminalSession{SessionID: "test-session", host: "web"}
+	p := &oscParser{}
+
+	// bash-style stream with explicit command text, then a zsh-less prompt
+	// where the command has to be recovered from the echo
+	stream := "\x1b]7;file://web/srv\x1b\\\x1b]133;A\x07$ \x1b]133;B\x07make\r\n" +
+		"\x1b]633;E;make build\x07\x1b]133;C\x07error\r\n\x1b]133;D;2\x07" +
+		"\x1b]133;A\x07$ \x1b]133;B\x07lsx\b \b\r\n\x1b[?2004l\x1b]133;C\x07ok\r\n\x1b]133;D;0\x07"
+
+	// Feed in small chunks so markers and echo are split across reads
+	for i := 0; i < len(stream); i += 5 {
+		end := i + 5
+		if end > len(stream) {
+			end = len(stream)
+		}
+		output, seqs := p.Feed(stream[i:end])
+		if len(seqs) > 0 || ts.isCapturingInput() {
+			a.handleShellIntegration(ts, output, seqs)
+		}
+	}
+
+	records := a.GetCommandHistory(CommandHistoryQuery{Host: "web"})
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d: %+v", len(records), records)
+	}
+
+	// Newest first
+	if records[0].Command != "ls" || records[0].ExitCode == nil || *records[0].ExitCode != 0 {
+		t.Errorf("Unexpected second command: %+v", records[0])
+	}
+	if records[1].Command != "make build" || records[1].Cwd != "/srv" || records[1].ExitCode == nil || *records[1].ExitCode != 2 {
+		t.Errorf("Unexpected first command: %+v", records[1])
+	}
+
+	failed := a.GetCommandHistory(CommandHistoryQuery{FailedOnly: true})
+	if len(failed) != 1 || failed[0].Command != "make build" {
+		t.Errorf("Expected only the failed command, got %+v", failed)
+	}
+
+	// Quitting before the delayed save still writes the history
+	flushCommandHistory()
+	historyPath, _ := getAppConfigPath("command-history.json")
+	if data, err := os.ReadFile(historyPath); err != nil || !strings.Contains(string(data), "make build") {
+		t.Errorf("Expected the flush to write the history, got %q (%v)", data, err)
+	}
+}
+
+func TestCaptureInputKeepsUTF8(t *testing.T) {
+	tracker := &commandTracker{state: promptStateInput}
+	tracker.captureInput(strings.Repeat("a", MaxCommandInputCapture-1) + "é")
+	if got := tracker.input.String(); len(got) != MaxCommandInputCapture-1 || !utf8.ValidString(got) {
+		t.Errorf("Expected the capture to stop before the split character, got %d bytes", len(got))
+	}
+}
+
+func TestExtractClipboardSequences(t *testing.T) {
+	p := &oscParser{}
+	out, seqs := p.Feed("a\x1b]52;c;aGVsbG8=\x07b\x1b]7;file://h/tmp\x07c")
+
+	out, seqs, payloads := extractClipboardSequences(out, seqs)
+	if out != "ab\x1b]7;file://h/tmp\x07c" {
+		t.Errorf("Expected OSC 52 to be stripped, got %q", out)
+	}
+	if len(seqs) != 1 || out[seqs[0].Start:seqs[0].End] != "\x1b]7;file://h/tmp\x07" {
+		t.Errorf("Expected remaining offsets to be shifted, got %+v", seqs)
+	}
+	if len(payloads) != 1 {
+		t.Fatalf("Expected one payload, got %v", payloads)
+	}
+
+	raw, ok, err := parseClipboardPayload(payloads[0], 16)
+	if err != nil || !ok || string(raw) != "hello" {
+		t.Errorf("Unexpected decode: %q %v %v", raw, ok, err)
+	}
+	if _, ok, _ := parseClipboardPayload("c;?", 16); ok {
+		t.Errorf("Expected clipboard query to be ignored")
+	}
+	if _, _, err := parseClipboardPayload("c;aGVsbG8gd29ybGQh", 5); err == nil {
+		t.Errorf("Expected size limit to be enforced")
+	}
+}
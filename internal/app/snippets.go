@@ -0,0 +1,498 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// SnippetPackVersion is the format version written to exported snippet packs
+const SnippetPackVersion = 1
+
+// Snippet is a reusable command template. Variables are written {{name}} or
+// {{name:default}} and filled in when the snippet is rendered.
+type Snippet struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Command     string   `json:"command"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Hosts       []string `json:"hosts"` // Host patterns (e.g. "web-*") the snippet applies to; empty means any host
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+// SnippetVariable is a variable used in a snippet's command
+type SnippetVariable struct {
+	Name       string `json:"name"`
+	Default    string `json:"default"`
+	HasDefault bool   `json:"hasDefault"`
+}
+
+// SnippetQuery filters GetSnippets results. Empty fields match everything.
+type SnippetQuery struct {
+	Search string `json:"search"` // case-insensitive substring of name, command or description
+	Tag    string `json:"tag"`
+	Host   string `json:"host"` // Only snippets that apply to this host
+}
+
+// SnippetPack is the shareable file format for importing and exporting snippets
+type SnippetPack struct {
+	Version  int       `json:"version"`
+	Snippets []Snippet `json:"snippets"`
+}
+
+// SnippetImportResult reports what an import changed
+type SnippetImportResult struct {
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+}
+
+// snippetVariablePattern matches {{name}} and {{name:default}}
+var snippetVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*(?::([^}]*))?\}\}`)
+
+// snippetStore keeps snippets in memory, backed by snippets.json
+var snippetStore = struct {
+	mu       sync.Mutex
+	loaded   bool
+	snippets []Snippet
+}{}
+
+// loadSnippetsLocked reads snippets.json once. Caller must hold the lock.
+func loadSnippetsLocked() {
+	if snippetStore.loaded {
+		return
+	}
+	snippetStore.loaded = true
+
+	snippetsPath, err := getAppConfigPath("snippets.json")
+	if err != nil {
+		log.Printf("⚠️ [Snippets] Failed to get snippets path: %v", err)
+		return
+	}
+
+	data, err := os.ReadFile(snippetsPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ [Snippets] Failed to read snippets: %v", err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(data, &snippetStore.snippets); err != nil {
+		log.Printf("⚠️ [Snippets] Failed to parse snippets: %v", err)
+		snippetStore.snippets = nil
+	}
+}
+
+// saveSnippetsLocked writes all snippets to disk. Caller must hold the lock.
+func saveSnippetsLocked() error {
+	snippetsPath, err := getAppConfigPath("snippets.json")
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snippetStore.snippets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snippets: %v", err)
+	}
+	if err := os.WriteFile(snippetsPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write snippets: %v", err)
+	}
+	return nil
+}
+
+// parseSnippetVariables returns the variables of a command template in order
+// of first use. A default given at any use applies to all of them.
+func parseSnippetVariables(command string) []SnippetVariable {
+	var vars []SnippetVariable
+	index := make(map[string]int)
+	for _, m := range snippetVariablePattern.FindAllStringSubmatchIndex(command, -1) {
+		name := command[m[2]:m[3]]
+		i, seen := index[name]
+		if !seen {
+			i = len(vars)
+			index[name] = i
+			vars = append(vars, SnippetVariable{Name: name})
+		}
+		if m[4] >= 0 && !vars[i].HasDefault {
+			vars[i].Default = command[m[4]:m[5]]
+			vars[i].HasDefault = true
+		}
+	}
+	return vars
+}
+
+// renderSnippet substitutes variables into a command template. Variables
+// without a value or default are an error.
+func renderSnippet(command string, values map[string]string) (string, error) {
+	defaults := make(map[string]string)
+	for _, v := range parseSnippetVariables(command) {
+		if v.HasDefault {
+			defaults[v.Name] = v.Default
+		}
+	}
+
+	var missing []string
+	rendered := snippetVariablePattern.ReplaceAllStringFunc(command, func(match string) string {
+		name := snippetVariablePattern.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		if value, ok := defaults[name]; ok {
+			return value
+		}
+		if !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return match
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing value for snippet variable: %s", strings.Join(missing, ", "))
+	}
+	return rendered, nil
+}
+
+// snippetMatchesHost reports whether a snippet applies to a host
+func snippetMatchesHost(snippet Snippet, host string) bool {
+	if len(snippet.Hosts) == 0 {
+		return true
+	}
+	for _, pattern := range snippet.Hosts {
+		if matched, _ := filepath.Match(pattern, host); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSnippet checks a snippet's fields and normalizes its tags
+func validateSnippet(snippet *Snippet) error {
+	snippet.Name = strings.TrimSpace(snippet.Name)
+	if snippet.Name == "" {
+		return fmt.Errorf("snippet name is required")
+	}
+	if strings.TrimSpace(snippet.Command) == "" {
+		return fmt.Errorf("snippet command is required")
+	}
+	for _, pattern := range snippet.Hosts {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid host pattern %q: %v", pattern, err)
+		}
+	}
+	tags := make([]string, 0, len(snippet.Tags))
+	for _, tag := range snippet.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	snippet.Tags = tags
+	return nil
+}
+
+// findSnippetLocked returns the index of a snippet, or -1. Caller must hold the lock.
+func findSnippetLocked(id string) int {
+	for i, s := range snippetStore.snippets {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// getSnippet returns a snippet by ID
+func getSnippet(id string) (Snippet, error) {
+	snippetStore.mu.Lock()
+	defer snippetStore.mu.Unlock()
+	loadSnippetsLocked()
+
+	i := findSnippetLocked(id)
+	if i < 0 {
+		return Snippet{}, fmt.Errorf("snippet not found: %s", id)
+	}
+	return snippetStore.snippets[i], nil
+}
+
+// GetSnippets returns the snippets matching a query, sorted by name
+func (a *App) GetSnippets(query SnippetQuery) []Snippet {
+	snippetStore.mu.Lock()
+	defer snippetStore.mu.Unlock()
+	loadSnippetsLocked()
+
+	search := strings.ToLower(query.Search)
+	result := []Snippet{}
+	for _, s := range snippetStore.snippets {
+		if query.Tag != "" && !slices.Contains(s.Tags, query.Tag) {
+			continue
+		}
+		if query.Host != "" && !snippetMatchesHost(s, query.Host) {
+			continue
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(s.Name), search) &&
+			!strings.Contains(strings.ToLower(s.Command), search) &&
+			!strings.Contains(strings.ToLower(s.Description), search) {
+			continue
+		}
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name) })
+	return result
+}
+
+// AddSnippet validates and saves a new snippet, returning it with its ID assigned
+func (a *App) AddSnippet(snippet Snippet) (Snippet, error) {
+	if err := validateSnippet(&snippet); err != nil {
+		return Snippet{}, err
+	}
+	now := time.Now().Format(time.RFC3339)
+	snippet.ID = fmt.Sprintf("snippet-%d", time.Now().UnixNano())
+	snippet.CreatedAt = now
+	snippet.UpdatedAt = now
+
+	snippetStore.mu.Lock()
+	defer snippetStore.mu.Unlock()
+	loadSnippetsLocked()
+
+	snippetStore.snippets = append(snippetStore.snippets, snippet)
+	if err := saveSnippetsLocked(); err != nil {
+		return Snippet{}, err
+	}
+
+	log.Printf("📝 [Snippets] Added snippet %q", snippet.Name)
+	return snippet, nil
+}
+
+// UpdateSnippet replaces a snippet's fields
+func (a *App) UpdateSnippet(snippet Snippet) error {
+	if err := validateSnippet(&snippet); err != nil {
+		return err
+	}
+
+	snippetStore.mu.Lock()
+	defer snippetStore.mu.Unlock()
+	loadSnippetsLocked()
+
+	i := findSnippetLocked(snippet.ID)
+	if i < 0 {
+		return fmt.Errorf("snippet not found: %s", snippet.ID)
+	}
+	snippet.CreatedAt = snippetStore.snippets[i].CreatedAt
+	snippet.UpdatedAt = time.Now().Format(time.RFC3339)
+	snippetStore.snippets[i] = snippet
+	return saveSnippetsLocked()
+}
+
+// DeleteSnippet removes a snippet
+func (a *App) DeleteSnippet(id string) error {
+	snippetStore.mu.Lock()
+	defer snippetStore.mu.Unlock()
+	loadSnippetsLocked()
+
+	i := findSnippetLocked(id)
+	if i < 0 {
+		return fmt.Errorf("snippet not found: %s", id)
+	}
+	snippetStore.snippets = append(snippetStore.snippets[:i], snippetStore.snippets[i+1:]...)
+	return saveSnippetsLocked()
+}
+
+// GetSnippetVariables returns the variables a snippet needs, for building its input form
+func (a *App) GetSnippetVariables(id string) ([]SnippetVariable, error) {
+	snippet, err := getSnippet(id)
+	if err != nil {
+		return nil, err
+	}
+	return parseSnippetVariables(snippet.Command), nil
+}
+
+// RenderSnippet fills in a snippet's variables and returns the command
+func (a *App) RenderSnippet(id string, values map[string]string) (string, error) {
+	snippet, err := getSnippet(id)
+	if err != nil {
+		return "", err
+	}
+	return renderSnippet(snippet.Command, values)
+}
+
+// renderSnippetForHost renders a snippet after checking it applies to the host
+func renderSnippetForHost(id string, host string, values map[string]string) (string, error) {
+	snippet, err := getSnippet(id)
+	if err != nil {
+		return "", err
+	}
+	if !snippetMatchesHost(snippet, host) {
+		return "", fmt.Errorf("snippet %q does not apply to host %s", snippet.Name, host)
+	}
+	return renderSnippet(snippet.Command, values)
+}
+
+// SendSnippetToTerminal types a rendered snippet into a terminal, pressing
+// Enter if execute is set. Broadcast groups apply as for typed input.
+func (a *App) SendSnippetToTerminal(id string, sessionID string, values map[string]string, execute bool) error {
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+
+	command, err := renderSnippetForHost(id, termSession.host, values)
+	if err != nil {
+		return err
+	}
+	if execute {
+		command += "\r"
+	}
+	return a.WriteToTerminal(sessionID, command)
+}
+
+// RunSnippet runs a rendered snippet on an SSH session and returns its output
+func (a *App) RunSnippet(id string, sessionID string, values map[string]string) (string, error) {
+	sshManager.mu.RLock()
+	session, exists := sshManager.sessions[sessionID]
+	sshManager.mu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	command, err := renderSnippetForHost(id, session.Config.Host, values)
+	if err != nil {
+		return "", err
+	}
+	return a.ExecuteCommand(sessionID, command)
+}
+
+// importSnippetPack merges a pack into the store: snippets with a known ID,
+// or else the same name, are updated; others are added
+func importSnippetPack(pack SnippetPack) (SnippetImportResult, error) {
+	var result SnippetImportResult
+	if pack.Version > SnippetPackVersion {
+		return result, fmt.Errorf("snippet pack version %d is newer than supported (%d)", pack.Version, SnippetPackVersion)
+	}
+	for i := range pack.Snippets {
+		if err := validateSnippet(&pack.Snippets[i]); err != nil {
+			return result, fmt.Errorf("invalid snippet #%d: %v", i+1, err)
+		}
+	}
+
+	snippetStore.mu.Lock()
+	defer snippetStore.mu.Unlock()
+	loadSnippetsLocked()
+
+	now := time.Now()
+	for n, snippet := range pack.Snippets {
+		i := -1
+		if snippet.ID != "" {
+			i = findSnippetLocked(snippet.ID)
+		}
+		if i < 0 {
+			for j, existing := range snippetStore.snippets {
+				if strings.EqualFold(existing.Name, snippet.Name) {
+					i = j
+					break
+				}
+			}
+		}
+
+		snippet.UpdatedAt = now.Format(time.RFC3339)
+		if i >= 0 {
+			snippet.ID = snippetStore.snippets[i].ID
+			snippet.CreatedAt = snippetStore.snippets[i].CreatedAt
+			snippetStore.snippets[i] = snippet
+			result.Updated++
+		} else {
+			snippet.ID = fmt.Sprintf("snippet-%d-%d", now.UnixNano(), n)
+			snippet.CreatedAt = snippet.UpdatedAt
+			snippetStore.snippets = append(snippetStore.snippets, snippet)
+			result.Added++
+		}
+	}
+	return result, saveSnippetsLocked()
+}
+
+// ExportSnippets saves snippets (all of them if ids is empty) as a snippet
+// pack chosen in a save dialog. Returns the file path, or "" if cancelled.
+func (a *App) ExportSnippets(ids []string) (string, error) {
+	snippetStore.mu.Lock()
+	loadSnippetsLocked()
+	pack := SnippetPack{Version: SnippetPackVersion, Snippets: []Snippet{}}
+	for _, s := range snippetStore.snippets {
+		if len(ids) == 0 || slices.Contains(ids, s.ID) {
+			pack.Snippets = append(pack.Snippets, s)
+		}
+	}
+	snippetStore.mu.Unlock()
+
+	data, err := json.MarshalIndent(pack, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snippets: %v", err)
+	}
+	if a.ctx == nil {
+		return "", fmt.Errorf("no window available for the save dialog")
+	}
+
+	path, err := wailsRuntime.SaveFileDialog(a.ctx, wailsRuntime.SaveDialogOptions{
+		Title:           "Export snippets",
+		DefaultFilename: "snippets.json",
+		Filters:         []wailsRuntime.FileFilter{{DisplayName: "Snippet packs (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %v", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write snippet pack: %v", err)
+	}
+	log.Printf("💾 [Snippets] Exported %d snippet(s) to %s", len(pack.Snippets), path)
+	return path, nil
+}
+
+// ImportSnippets merges a snippet pack chosen in an open dialog into the store
+func (a *App) ImportSnippets() (SnippetImportResult, error) {
+	if a.ctx == nil {
+		return SnippetImportResult{}, fmt.Errorf("no window available for the open dialog")
+	}
+
+	path, err := wailsRuntime.OpenFileDialog(a.ctx, wailsRuntime.OpenDialogOptions{
+		Title:   "Import snippets",
+		Filters: []wailsRuntime.FileFilter{{DisplayName: "Snippet packs (*.json)", Pattern: "*.json"}},
+	})
+	if err != nil {
+		return SnippetImportResult{}, fmt.Errorf("failed to open dialog: %v", err)
+	}
+	if path == "" {
+		return SnippetImportResult{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SnippetImportResult{}, fmt.Errorf("failed to read snippet pack: %v", err)
+	}
+	var pack SnippetPack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return SnippetImportResult{}, fmt.Errorf("failed to parse snippet pack: %v", err)
+	}
+
+	result, err := importSnippetPack(pack)
+	if err != nil {
+		return result, err
+	}
+	log.Printf("📥 [Snippets] Imported %s: %d added, %d updated", path, result.Added, result.Updated)
+	return result, nil
+}
@@ -0,0 +1,27 @@
+package app
+
+import "testing"
+
+func TestRenderSnippet(t *testing.T) {
+	command := "journalctl -u {{service}} -n {{lines:100}} | grep {{ service }}"
+
+	vars := parseSnippetVariables(command)
+	if len(vars) != 2 || vars[0].Name != "service" || vars[0].HasDefault || vars[1].Default != "100" {
+		t.Errorf("Unexpected variables: %+v", vars)
+	}
+
+	got, err := renderSnippet(command, map[string]string{"service": "nginx"})
+	if err != nil || got != "journalctl -u nginx -n 100 | grep nginx" {
+		t.Errorf("Unexpected render: %q %v", got, err)
+	}
+
+	// An explicitly empty value overrides the default
+	got, err = renderSnippet("ls {{flags:-la}}", map[string]string{"flags": ""})
+	if err != nil || got != "ls " {
+		t.Errorf("Unexpected render: %q %v", got, err)
+	}
+
+	if _, err := renderSnippet(command, nil); err == nil {
+		t.Errorf("Expected an error for a missing variable")
+	}
+}
@@ -17,6 +17,10 @@ type SSHConfigEntry struct {
 	User         string `json:"user"`
 	Port         int    `json:"port"`
 	IdentityFile string `json:"identityFile"`
+
+	// Environment options for terminal sessions
+	SetEnv  map[string]string `json:"setEnv"`  // SetEnv NAME=value
+	SendEnv []string          `json:"sendEnv"` // SendEnv patterns of local variables to pass
 }
 
 // GetSSHConfig parses ~/.ssh/config and returns list of hosts
@@ -51,6 +55,7 @@ func GetSSHConfig() []SSHConfigEntry {
 
 		key := strings.ToLower(parts[0])
 		value := strings.Join(parts[1:], " ")
+		rawValue := strings.TrimSpace(line[len(parts[0]):])
 
 		switch key {
 		case "host":
@@ -80,6 +85,26 @@ func GetSSHConfig() []SSHConfigEntry {
 			if currentHost != nil {
 				currentHost.IdentityFile = strings.Trim(value, "\"")
 			}
+		case "setenv":
+			if currentHost != nil {
+				for _, arg := range splitSSHConfigArgs(rawValue) {
+					name, val, ok := strings.Cut(arg, "=")
+					if !ok || name == "" {
+						continue
+					}
+					if currentHost.SetEnv == nil {
+						currentHost.SetEnv = make(map[string]string)
+					}
+					// Like ssh, the first value obtained for a variable is used
+					if _, exists := currentHost.SetEnv[name]; !exists {
+						currentHost.SetEnv[name] = val
+					}
+				}
+			}
+		case "sendenv":
+			if currentHost != nil {
+				currentHost.SendEnv = append(currentHost.SendEnv, splitSSHConfigArgs(rawValue)...)
+			}
 		}
 	}
 
@@ -89,3 +114,38 @@ func GetSSHConfig() []SSHConfigEntry {
 
 	return entries
 }
+
+// splitSSHConfigArgs splits a config value into whitespace-separated
+// arguments, honoring double and single quotes
+func splitSSHConfigArgs(s string) []string {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -2,6 +2,8 @@ package app
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
@@ -38,13 +40,21 @@ var sshManager = &SSHManager{
 	sessions: make(map[string]*SSHSession),
 }
 
-// FileInfo represents file/directory information
-type FileInfo struct {
-	Name    string `json:"name"`
-	Size    int64  `json:"size"`
-	Mode    string `json:"mode"`
-	ModTime string `json:"modTime"`
-	IsDir   bool   `json:"isDir"`
+// getConnectedSSHSession looks up an SSH session and verifies it is connected
+func getConnectedSSHSession(sessionID string) (*SSHSession, error) {
+	sshManager.mu.RLock()
+	session, exists := sshManager.sessions[sessionID]
+	sshManager.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	if !session.Connected || session.Client == nil {
+		return nil, fmt.Errorf("session not connected")
+	}
+
+	return session, nil
 }
 
 // knownHostsCallback returns an ssh.HostKeyCallback that implements TOFU
@@ -181,7 +191,14 @@ func appendKnownHost(knownHostsPath, host, port string, key ssh.PublicKey) error
 // ConnectSSH establishes SSH connection
 func (a *App) ConnectSSH(config SSHConfigEntry) (string, error) {
 	sessionID := fmt.Sprintf("%s-%d", config.Host, time.Now().Unix())
+	if err := connectSSHSession(sessionID, config); err != nil {
+		return "", err
+	}
+	return sessionID, nil
+}
 
+// connectSSHSession connects to a host and stores the session under sessionID
+func connectSSHSession(sessionID string, config SSHConfigEntry) error {
 	// Build SSH client config with known_hosts verification (TOFU strategy)
 	sshConfig := &ssh.ClientConfig{
 		User:            config.User,
@@ -203,20 +220,20 @@ func (a *App) ConnectSSH(config SSHConfigEntry) (string, error) {
 		// Read private key
 		key, err := os.ReadFile(identityFile)
 		if err != nil {
-			return "", fmt.Errorf("failed to read private key: %v", err)
+			return fmt.Errorf("failed to read private key: %v", err)
 		}
 
 		// Parse private key
 		signer, err := ssh.ParsePrivateKey(key)
 		if err != nil {
-			return "", fmt.Errorf("failed to parse private key: %v", err)
+			return fmt.Errorf("failed to parse private key: %v", err)
 		}
 
 		sshConfig.Auth = []ssh.AuthMethod{
 			ssh.PublicKeys(signer),
 		}
 	} else {
-		return "", fmt.Errorf("no authentication method configured")
+		return fmt.Errorf("no authentication method configured")
 	}
 
 	// Determine hostname
@@ -229,7 +246,7 @@ func (a *App) ConnectSSH(config SSHConfigEntry) (string, error) {
 	addr := fmt.Sprintf("%s:%d", hostname, config.Port)
 	client, err := ssh.Dial("tcp", addr, sshConfig)
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to %s: %v", addr, err)
+		return fmt.Errorf("failed to connect to %s: %v", addr, err)
 	}
 
 	// Create session object
@@ -247,7 +264,7 @@ func (a *App) ConnectSSH(config SSHConfigEntry) (string, error) {
 	sshManager.sessions[sessionID] = session
 	sshManager.mu.Unlock()
 
-	return sessionID, nil
+	return nil
 }
 
 // DisconnectSSH closes an SSH connection
@@ -273,38 +290,18 @@ func (a *App) DisconnectSSH(sessionID string) error {
 	return nil
 }
 
-// ExecuteCommand executes a command on the remote server
+// ExecuteCommand executes a command on the remote server and returns its
+// combined output. Use StartCommand to stream output or set a timeout.
 func (a *App) ExecuteCommand(sessionID string, command string) (string, error) {
-	sshManager.mu.RLock()
-	session, exists := sshManager.sessions[sessionID]
-	sshManager.mu.RUnlock()
-
-	if !exists {
-		return "", fmt.Errorf("session not found: %s", sessionID)
-	}
-
-	if !session.Connected || session.Client == nil {
-		return "", fmt.Errorf("session not connected")
-	}
-
-	// Create new SSH session for command execution
-	sshSession, err := session.Client.NewSession()
+	var output bytes.Buffer
+	exit, err := runRemoteCommand(context.Background(), sessionID, command, "", &output, &output)
 	if err != nil {
-		return "", fmt.Errorf("failed to create SSH session: %v", err)
+		return "", err
 	}
-	defer sshSession.Close()
-
-	// Execute command
-	output, err := sshSession.CombinedOutput(command)
-	if err != nil {
-		return string(output), fmt.Errorf("command failed: %v", err)
+	if err := exit.failure(); err != nil {
+		return output.String(), fmt.Errorf("command failed: %v", err)
 	}
-
-	session.mu.Lock()
-	session.LastActive = time.Now()
-	session.mu.Unlock()
-
-	return string(output), nil
+	return output.String(), nil
 }
 
 // ListFiles lists files in a directory via SFTP
@@ -344,29 +341,23 @@ func (a *App) ListFiles(sessionID string, path string) ([]FileInfo, error) {
 	}
 
 	// Convert to FileInfo
+	names := remoteOwnerNames(sessionID, sftpClient)
 	var files []FileInfo
 	for _, entry := range entries {
 		// Skip . and ..
 		if entry.Name() == "." || entry.Name() == ".." {
 			continue
 		}
-
-		fileInfo := FileInfo{
-			Name:    entry.Name(),
-			Size:    entry.Size(),
-			Mode:    entry.Mode().String(),
-			ModTime: entry.ModTime().Format(time.RFC3339),
-			IsDir:   entry.IsDir(),
-		}
-		files = append(files, fileInfo)
+		files = append(files, describeRemoteFile(names, path, entry))
 	}
+	resolveRemoteLinks(sftpClient, files)
 
 	return files, nil
 }
 
 // GetCurrentDirectory gets the current working directory
 func (a *App) GetCurrentDirectory(sessionID string) (string, error) {
-	return a.ExecuteCommand(sessionID, "pwd")
+	return runProbeCommand(sessionID, "pwd")
 }
 
 // CreatePTY creates a pseudo-terminal session.
@@ -105,6 +105,7 @@ type fileSnapshot struct {
 	Size    int64
 	ModTime time.Time
 	IsDir   bool
+	Link    string // Symlink target, when links are copied as links
 }
 
 // SyncManager manages all sync rules and their runtime state
@@ -391,7 +392,7 @@ func (a *App) TestSyncConnection(sshHost string) error {
 	}
 
 	// Run a simple test command
-	output, err := a.ExecuteCommand(sessionID, "echo ok")
+	output, err := runProbeCommand(sessionID, "echo ok")
 	if err != nil {
 		// Clean up the session even if the command fails
 		a.DisconnectSSH(sessionID)
@@ -414,7 +415,7 @@ func (a *App) TestSyncConnection(sshHost string) error {
 func (a *App) CheckRemoteSyncDeps(sessionID string) RemoteDepsStatus {
 	result := RemoteDepsStatus{}
 
-	output, err := a.ExecuteCommand(sessionID, "which rsync 2>/dev/null && rsync --version 2>/dev/null | head -1")
+	output, err := runProbeCommand(sessionID, "which rsync 2>/dev/null && rsync --version 2>/dev/null | head -1")
 	if err == nil && strings.TrimSpace(output) != "" {
 		result.HasRsync = true
 		lines := strings.Split(strings.TrimSpace(output), "\n")
@@ -423,7 +424,7 @@ func (a *App) CheckRemoteSyncDeps(sessionID string) RemoteDepsStatus {
 		}
 	}
 
-	output2, err := a.ExecuteCommand(sessionID, "which inotifywait 2>/dev/null")
+	output2, err := runProbeCommand(sessionID, "which inotifywait 2>/dev/null")
 	if err == nil && strings.TrimSpace(output2) != "" {
 		result.HasInotify = true
 	}
@@ -672,12 +673,15 @@ func (sm *SyncManager) rsyncFullSync(ctx context.Context, state *syncRuleState)
 	// Ensure local directory exists
 	os.MkdirAll(cleanLocal, 0755)
 
-	args := []string{
-		"-avz", "--delete",
-		"--timeout=30",
-		"-e", sshCmd,
-		src, dst,
+	args := []string{"-avz", "--delete", "--timeout=30"}
+	// -a copies links as links already
+	switch sessionHostSettings(state.sessionID).Symlinks {
+	case SymlinkFollow:
+		args = append(args, "--copy-links")
+	case SymlinkSkip:
+		args = append(args, "--no-links")
 	}
+	args = append(args, "-e", sshCmd, src, dst)
 
 	sm.emitLog(SyncLogEntry{
 		RuleID:  rule.ID,
@@ -729,12 +733,13 @@ func (sm *SyncManager) sftpFullSync(ctx context.Context, state *syncRuleState, s
 	localPath := strings.TrimRight(rule.LocalPath, "/")
 	os.MkdirAll(localPath, 0755)
 
-	localFiles, err := sm.buildLocalFileList(localPath)
+	settings := sessionHostSettings(state.sessionID)
+	localFiles, err := sm.buildLocalFileList(localPath, settings.Symlinks)
 	if err != nil {
 		return fmt.Errorf("failed to list local files: %v", err)
 	}
 
-	remoteFiles, err := sm.buildRemoteFileList(sftpClient, remotePath)
+	remoteFiles, err := sm.buildRemoteFileList(sftpClient, remotePath, settings.Symlinks)
 	if err != nil {
 		return fmt.Errorf("failed to list remote files: %v", err)
 	}
@@ -757,11 +762,27 @@ func (sm *SyncManager) sftpFullSync(ctx context.Context, state *syncRuleState, s
 				continue
 			}
 			remoteSnap, exists := remoteFiles[relPath]
-			if !exists || localSnap.Size != remoteSnap.Size || localSnap.ModTime.After(remoteSnap.ModTime) {
+			if localSnap.Link != "" {
+				if !exists || remoteSnap.Link != localSnap.Link {
+					sftpClient.MkdirAll(remotePath + "/" + filepath.Dir(relPath))
+					if err := linkRemote(sftpClient, localSnap.Link, remotePath+"/"+relPath); err != nil {
+						sm.emitLog(SyncLogEntry{RuleID: rule.ID, Action: "error", FilePath: relPath, Direction: "local->remote", Status: "error", Message: fmt.Sprintf("Symlink failed: %v", err)})
+						continue
+					}
+					syncCount++
+					sm.emitLog(SyncLogEntry{RuleID: rule.ID, Action: "upload", FilePath: relPath, Direction: "local->remote", Status: "success", Message: "Symlink to " + localSnap.Link})
+				}
+				continue
+			}
+			if !exists || remoteSnap.Link != "" || localSnap.Size != remoteSnap.Size || localSnap.ModTime.After(remoteSnap.ModTime) {
 				localFull := filepath.Join(localPath, relPath)
 				remoteFull := remotePath + "/" + relPath
 				sftpClient.MkdirAll(remotePath + "/" + filepath.Dir(relPath))
-				if err := sm.uploadFileSFTP(sftpClient, localFull, remoteFull); err != nil {
+				if remoteSnap.Link != "" {
+					// Writing through the link would change its target instead
+					sftpClient.Remove(remoteFull)
+				}
+				if err := sm.uploadFileSFTP(sftpClient, localFull, remoteFull, settings.PreserveAttributes); err != nil {
 					sm.emitLog(SyncLogEntry{RuleID: rule.ID, Action: "error", FilePath: relPath, Direction: "local->remote", Status: "error", Message: fmt.Sprintf("Upload failed: %v", err)})
 					continue
 				}
@@ -792,11 +813,28 @@ func (sm *SyncManager) sftpFullSync(ctx context.Context, state *syncRuleState, s
 				continue
 			}
 			localSnap, exists := localFiles[relPath]
-			if !exists || remoteSnap.Size != localSnap.Size || remoteSnap.ModTime.After(localSnap.ModTime) {
+			if remoteSnap.Link != "" {
+				if !exists || localSnap.Link != remoteSnap.Link {
+					localFull := filepath.Join(localPath, relPath)
+					os.MkdirAll(filepath.Dir(localFull), 0755)
+					if err := linkLocal(remoteSnap.Link, localFull); err != nil {
+						sm.emitLog(SyncLogEntry{RuleID: rule.ID, Action: "error", FilePath: relPath, Direction: "remote->local", Status: "error", Message: fmt.Sprintf("Symlink failed: %v", err)})
+						continue
+					}
+					syncCount++
+					sm.emitLog(SyncLogEntry{RuleID: rule.ID, Action: "download", FilePath: relPath, Direction: "remote->local", Status: "success", Message: "Symlink to " + remoteSnap.Link})
+				}
+				continue
+			}
+			if !exists || localSnap.Link != "" || remoteSnap.Size != localSnap.Size || remoteSnap.ModTime.After(localSnap.ModTime) {
 				remoteFull := remotePath + "/" + relPath
 				localFull := filepath.Join(localPath, relPath)
 				os.MkdirAll(filepath.Dir(localFull), 0755)
-				if err := sm.downloadFileSFTP(sftpClient, remoteFull, localFull); err != nil {
+				if localSnap.Link != "" {
+					// Writing through the link would change its target instead
+					os.Remove(localFull)
+				}
+				if err := sm.downloadFileSFTP(sftpClient, remoteFull, localFull, settings.PreserveAttributes); err != nil {
 					sm.emitLog(SyncLogEntry{RuleID: rule.ID, Action: "error", FilePath: relPath, Direction: "remote->local", Status: "error", Message: fmt.Sprintf("Download failed: %v", err)})
 					continue
 				}
@@ -829,7 +867,9 @@ func (sm *SyncManager) sftpFullSync(ctx context.Context, state *syncRuleState, s
 
 // --- File list helpers ---
 
-func (sm *SyncManager) buildLocalFileList(basePath string) (map[string]fileSnapshot, error) {
+// buildLocalFileList lists a local directory, handling symlinks by policy.
+// Followed links to directories are left out.
+func (sm *SyncManager) buildLocalFileList(basePath string, symlinks string) (map[string]fileSnapshot, error) {
 	result := make(map[string]fileSnapshot)
 	err := filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -839,13 +879,28 @@ func (sm *SyncManager) buildLocalFileList(basePath string) (map[string]fileSnaps
 		if err != nil || relPath == "." {
 			return nil
 		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			switch symlinks {
+			case SymlinkSkip:
+				return nil
+			case SymlinkCopy:
+				if target, err := os.Readlink(path); err == nil {
+					result[relPath] = fileSnapshot{Size: info.Size(), ModTime: info.ModTime(), Link: target}
+				}
+				return nil
+			}
+			if info, err = os.Stat(path); err != nil || info.IsDir() {
+				return nil
+			}
+		}
 		result[relPath] = fileSnapshot{Size: info.Size(), ModTime: info.ModTime(), IsDir: info.IsDir()}
 		return nil
 	})
 	return result, err
 }
 
-func (sm *SyncManager) buildRemoteFileList(sftpClient *sftp.Client, basePath string) (map[string]fileSnapshot, error) {
+// buildRemoteFileList lists a remote directory like buildLocalFileList
+func (sm *SyncManager) buildRemoteFileList(sftpClient *sftp.Client, basePath string, symlinks string) (map[string]fileSnapshot, error) {
 	result := make(map[string]fileSnapshot)
 	walker := sftpClient.Walk(basePath)
 	for walker.Step() {
@@ -857,6 +912,20 @@ func (sm *SyncManager) buildRemoteFileList(sftpClient *sftp.Client, basePath str
 			continue
 		}
 		stat := walker.Stat()
+		if stat.Mode()&os.ModeSymlink != 0 {
+			switch symlinks {
+			case SymlinkSkip:
+				continue
+			case SymlinkCopy:
+				if target, err := sftpClient.ReadLink(walker.Path()); err == nil {
+					result[relPath] = fileSnapshot{Size: stat.Size(), ModTime: stat.ModTime(), Link: target}
+				}
+				continue
+			}
+			if stat, err = sftpClient.Stat(walker.Path()); err != nil || stat.IsDir() {
+				continue
+			}
+		}
 		result[relPath] = fileSnapshot{Size: stat.Size(), ModTime: stat.ModTime(), IsDir: stat.IsDir()}
 	}
 	return result, nil
@@ -864,7 +933,7 @@ func (sm *SyncManager) buildRemoteFileList(sftpClient *sftp.Client, basePath str
 
 // --- SFTP transfer helpers ---
 
-func (sm *SyncManager) uploadFileSFTP(sftpClient *sftp.Client, localPath, remotePath string) error {
+func (sm *SyncManager) uploadFileSFTP(sftpClient *sftp.Client, localPath, remotePath string, preserve bool) error {
 	localFile, err := os.Open(localPath)
 	if err != nil {
 		return err
@@ -875,13 +944,20 @@ func (sm *SyncManager) uploadFileSFTP(sftpClient *sftp.Client, localPath, remote
 	if err != nil {
 		return err
 	}
-	defer remoteFile.Close()
 
 	_, err = io.Copy(remoteFile, localFile)
-	return err
+	remoteFile.Close()
+	if err != nil || !preserve {
+		return err
+	}
+	localInfo, err := localFile.Stat()
+	if err != nil {
+		return err
+	}
+	return preserveRemote(sftpClient, remotePath, localInfo.Mode(), localAccessTime(localInfo), localInfo.ModTime())
 }
 
-func (sm *SyncManager) downloadFileSFTP(sftpClient *sftp.Client, remotePath, localPath string) error {
+func (sm *SyncManager) downloadFileSFTP(sftpClient *sftp.Client, remotePath, localPath string, preserve bool) error {
 	remoteFile, err := sftpClient.Open(remotePath)
 	if err != nil {
 		return err
@@ -892,10 +968,17 @@ func (sm *SyncManager) downloadFileSFTP(sftpClient *sftp.Client, remotePath, loc
 	if err != nil {
 		return err
 	}
-	defer localFile.Close()
 
 	_, err = io.Copy(localFile, remoteFile)
-	return err
+	localFile.Close()
+	if err != nil || !preserve {
+		return err
+	}
+	remoteInfo, err := remoteFile.Stat()
+	if err != nil {
+		return err
+	}
+	return preserveLocal(localPath, remoteInfo.Mode(), remoteAccessTime(remoteInfo), remoteInfo.ModTime())
 }
 
 func (sm *SyncManager) removeRemoteDirRecursive(sftpClient *sftp.Client, path string) {
@@ -1095,7 +1178,7 @@ func (sm *SyncManager) startPollingWatcher(ctx context.Context, state *syncRuleS
 					continue
 				}
 				remotePath := resolveRemotePath(sftpClient, state.rule.RemotePath)
-				currentFiles, err := sm.buildRemoteFileList(sftpClient, remotePath)
+				currentFiles, err := sm.buildRemoteFileList(sftpClient, remotePath, sessionHostSettings(state.sessionID).Symlinks)
 				if err != nil {
 					continue
 				}
@@ -1105,7 +1188,7 @@ func (sm *SyncManager) startPollingWatcher(ctx context.Context, state *syncRuleS
 				old := state.remoteSnapshot
 
 				for path, cur := range currentFiles {
-					if prev, exists := old[path]; !exists || prev.Size != cur.Size || !prev.ModTime.Equal(cur.ModTime) {
+					if prev, exists := old[path]; !exists || prev.Size != cur.Size || !prev.ModTime.Equal(cur.ModTime) || prev.Link != cur.Link {
 						changed = true
 						break
 					}
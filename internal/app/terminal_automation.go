@@ -0,0 +1,568 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Automation step types
+const (
+	AutomationStepSend   = "send"   // Type Text, followed by Enter if set
+	AutomationStepExpect = "expect" // Wait for Pattern
+	AutomationStepBranch = "branch" // Wait for the first of Branches to match and jump to its label
+	AutomationStepSleep  = "sleep"  // Pause for DurationMs
+	AutomationStepGoto   = "goto"   // Jump to the Goto label
+	AutomationStepStop   = "stop"   // End the run successfully
+)
+
+// Automation limits
+const (
+	// AutomationExpectTimeout is how long expect and branch steps wait when
+	// they don't set their own timeout
+	AutomationExpectTimeout = 30 * time.Second
+	// MaxAutomationSteps bounds the steps one run executes, so a goto loop
+	// that never matches can't run forever
+	MaxAutomationSteps = 1000
+	// maxAutomationSleep bounds a single sleep step
+	maxAutomationSleep = time.Hour
+)
+
+// AutomationBranch is one pattern of a branch step
+type AutomationBranch struct {
+	Pattern string `json:"pattern"`
+	Goto    string `json:"goto"` // Label to jump to when Pattern matches
+}
+
+// AutomationStep is one step of an automation script. Which fields apply depends on Type.
+type AutomationStep struct {
+	Type           string             `json:"type"`
+	Label          string             `json:"label"`          // Optional name that goto, branch and onTimeout can jump to
+	Text           string             `json:"text"`           // send
+	Enter          bool               `json:"enter"`          // send: press Enter after the text
+	Secret         bool               `json:"secret"`         // send: keep the text out of events and logs
+	Pattern        string             `json:"pattern"`        // expect: regex matched against output with escape sequences removed
+	Branches       []AutomationBranch `json:"branches"`       // branch
+	TimeoutSeconds int                `json:"timeoutSeconds"` // expect, branch: 0 means 30s
+	OnTimeout      string             `json:"onTimeout"`      // expect, branch: label to jump to on timeout; empty fails the run
+	DurationMs     int                `json:"durationMs"`     // sleep
+	Goto           string             `json:"goto"`           // goto
+}
+
+// AutomationScript is a saved sequence of steps that can be run against any terminal
+type AutomationScript struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Steps       []AutomationStep `json:"steps"`
+	CreatedAt   string           `json:"createdAt"`
+	UpdatedAt   string           `json:"updatedAt"`
+}
+
+// compiledAutomation is a validated script ready to run
+type compiledAutomation struct {
+	steps    []AutomationStep
+	patterns [][]*regexp.Regexp // Per step: the expect pattern or the branch patterns
+	labels   map[string]int
+}
+
+// automationRun is a script running against a terminal
+type automationRun struct {
+	id        string
+	scriptID  string
+	sessionID string
+	cancel    chan struct{}
+	once      sync.Once
+}
+
+// automationStore keeps scripts in memory, backed by automation-scripts.json
+var automationStore = struct {
+	mu      sync.Mutex
+	loaded  bool
+	scripts []AutomationScript
+}{}
+
+// automationRuns holds running scripts so they can be cancelled. A terminal
+// runs at most one script at a time.
+var automationRuns = struct {
+	mu   sync.Mutex
+	runs map[string]*automationRun
+}{
+	runs: make(map[string]*automationRun),
+}
+
+// loadAutomationScriptsLocked reads automation-scripts.json once. Caller must hold the lock.
+func loadAutomationScriptsLocked() {
+	if automationStore.loaded {
+		return
+	}
+	automationStore.loaded = true
+
+	scriptsPath, err := getAppConfigPath("automation-scripts.json")
+	if err != nil {
+		log.Printf("⚠️ [Automation] Failed to get scripts path: %v", err)
+		return
+	}
+
+	data, err := os.ReadFile(scriptsPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ [Automation] Failed to read scripts: %v", err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(data, &automationStore.scripts); err != nil {
+		log.Printf("⚠️ [Automation] Failed to parse scripts: %v", err)
+		automationStore.scripts = nil
+	}
+}
+
+// saveAutomationScriptsLocked writes all scripts to disk. Caller must hold the lock.
+func saveAutomationScriptsLocked() error {
+	scriptsPath, err := getAppConfigPath("automation-scripts.json")
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(automationStore.scripts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal automation scripts: %v", err)
+	}
+	// Secret steps hold passwords, so only the user may read the file,
+	// including one saved readable by everyone before
+	if err := os.Chmod(scriptsPath, 0600); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to restrict automation scripts: %v", err)
+	}
+	if err := os.WriteFile(scriptsPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write automation scripts: %v", err)
+	}
+	return nil
+}
+
+// compileAutomation checks a script's steps, compiles their patterns and
+// resolves their labels
+func compileAutomation(steps []AutomationStep) (*compiledAutomation, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("script has no steps")
+	}
+
+	c := &compiledAutomation{
+		steps:    steps,
+		patterns: make([][]*regexp.Regexp, len(steps)),
+		labels:   make(map[string]int),
+	}
+	for i, step := range steps {
+		if step.Label == "" {
+			continue
+		}
+		if _, exists := c.labels[step.Label]; exists {
+			return nil, fmt.Errorf("step #%d: duplicate label %q", i+1, step.Label)
+		}
+		c.labels[step.Label] = i
+	}
+
+	checkLabel := func(i int, label string) error {
+		if _, exists := c.labels[label]; !exists {
+			return fmt.Errorf("step #%d: unknown label %q", i+1, label)
+		}
+		return nil
+	}
+	compile := func(i int, pattern string) error {
+		if pattern == "" {
+			return fmt.Errorf("step #%d: pattern is required", i+1)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("step #%d: invalid pattern: %v", i+1, err)
+		}
+		c.patterns[i] = append(c.patterns[i], re)
+		return nil
+	}
+
+	for i, step := range steps {
+		if step.TimeoutSeconds < 0 || step.DurationMs < 0 {
+			return nil, fmt.Errorf("step #%d: timeout and duration cannot be negative", i+1)
+		}
+		switch step.Type {
+		case AutomationStepSend:
+			if step.Text == "" && !step.Enter {
+				return nil, fmt.Errorf("step #%d: nothing to send", i+1)
+			}
+		case AutomationStepExpect:
+			if err := compile(i, step.Pattern); err != nil {
+				return nil, err
+			}
+		case AutomationStepBranch:
+			if len(step.Branches) == 0 {
+				return nil, fmt.Errorf("step #%d: branch has no patterns", i+1)
+			}
+			for _, b := range step.Branches {
+				if err := compile(i, b.Pattern); err != nil {
+					return nil, err
+				}
+				if err := checkLabel(i, b.Goto); err != nil {
+					return nil, err
+				}
+			}
+		case AutomationStepSleep:
+			if time.Duration(step.DurationMs)*time.Millisecond > maxAutomationSleep {
+				return nil, fmt.Errorf("step #%d: sleep is longer than %v", i+1, maxAutomationSleep)
+			}
+		case AutomationStepGoto:
+			if err := checkLabel(i, step.Goto); err != nil {
+				return nil, err
+			}
+		case AutomationStepStop:
+		default:
+			return nil, fmt.Errorf("step #%d: unknown step type %q", i+1, step.Type)
+		}
+		if step.OnTimeout != "" {
+			if err := checkLabel(i, step.OnTimeout); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// automationStepResult describes how a step ended, for progress events
+type automationStepResult struct {
+	Index  int
+	Status string // "sent", "matched", "timeout", "slept", "jumped" or "stopped"
+	Detail string // The pattern that matched, or the label jumped to
+}
+
+// execute runs the script's steps, reading output from w and typing with send.
+// Each finished step is passed to report. Returns nil when the script reaches
+// its end or a stop step, and errAutomationStopped if w.stop closes.
+func (c *compiledAutomation) execute(w *outputWatcher, send func(string) error, report func(automationStepResult)) error {
+	pc := 0
+	for executed := 0; pc < len(c.steps); executed++ {
+		if executed >= MaxAutomationSteps {
+			return fmt.Errorf("gave up after %d steps; the script may be looping", MaxAutomationSteps)
+		}
+		step := c.steps[pc]
+		next := pc + 1
+
+		switch step.Type {
+		case AutomationStepSend:
+			text := step.Text
+			if step.Enter {
+				text += "\r"
+			}
+			if err := send(text); err != nil {
+				return fmt.Errorf("step #%d: failed to send: %v", pc+1, err)
+			}
+			report(automationStepResult{Index: pc, Status: "sent"})
+
+		case AutomationStepExpect, AutomationStepBranch:
+			timeout := AutomationExpectTimeout
+			if step.TimeoutSeconds > 0 {
+				timeout = time.Duration(step.TimeoutSeconds) * time.Second
+			}
+			matched := w.waitFor(c.patterns[pc], timeout)
+			if matched < 0 {
+				if w.stopped() {
+					return errAutomationStopped
+				}
+				report(automationStepResult{Index: pc, Status: "timeout", Detail: step.OnTimeout})
+				if step.OnTimeout == "" {
+					return fmt.Errorf("step #%d: timed out after %v waiting for output", pc+1, timeout)
+				}
+				next = c.labels[step.OnTimeout]
+				break
+			}
+			if step.Type == AutomationStepBranch {
+				next = c.labels[step.Branches[matched].Goto]
+				report(automationStepResult{Index: pc, Status: "matched", Detail: step.Branches[matched].Pattern})
+			} else {
+				report(automationStepResult{Index: pc, Status: "matched", Detail: step.Pattern})
+			}
+
+		case AutomationStepSleep:
+			select {
+			case <-time.After(time.Duration(step.DurationMs) * time.Millisecond):
+			case <-w.stop:
+				return errAutomationStopped
+			}
+			report(automationStepResult{Index: pc, Status: "slept"})
+
+		case AutomationStepGoto:
+			next = c.labels[step.Goto]
+			report(automationStepResult{Index: pc, Status: "jumped", Detail: step.Goto})
+
+		case AutomationStepStop:
+			report(automationStepResult{Index: pc, Status: "stopped"})
+			return nil
+		}
+		pc = next
+	}
+	return nil
+}
+
+// errAutomationStopped means the run was cancelled or its terminal closed
+var errAutomationStopped = errors.New("automation stopped")
+
+// stopped reports whether the watcher's stop channel is closed
+func (w *outputWatcher) stopped() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateAutomationScript checks a script's name and steps
+func validateAutomationScript(script *AutomationScript) error {
+	script.Name = strings.TrimSpace(script.Name)
+	if script.Name == "" {
+		return fmt.Errorf("script name is required")
+	}
+	_, err := compileAutomation(script.Steps)
+	return err
+}
+
+// findAutomationScriptLocked returns the index of a script, or -1. Caller must hold the lock.
+func findAutomationScriptLocked(id string) int {
+	for i, s := range automationStore.scripts {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetAutomationScripts returns all automation scripts, sorted by name
+func (a *App) GetAutomationScripts() []AutomationScript {
+	automationStore.mu.Lock()
+	defer automationStore.mu.Unlock()
+	loadAutomationScriptsLocked()
+
+	result := append([]AutomationScript{}, automationStore.scripts...)
+	sort.Slice(result, func(i, j int) bool { return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name) })
+	return result
+}
+
+// AddAutomationScript validates and saves a new script, returning it with its ID assigned
+func (a *App) AddAutomationScript(script AutomationScript) (AutomationScript, error) {
+	if err := validateAutomationScript(&script); err != nil {
+		return AutomationScript{}, err
+	}
+	now := time.Now().Format(time.RFC3339)
+	script.ID = fmt.Sprintf("automation-%d", time.Now().UnixNano())
+	script.CreatedAt = now
+	script.UpdatedAt = now
+
+	automationStore.mu.Lock()
+	defer automationStore.mu.Unlock()
+	loadAutomationScriptsLocked()
+
+	automationStore.scripts = append(automationStore.scripts, script)
+	if err := saveAutomationScriptsLocked(); err != nil {
+		return AutomationScript{}, err
+	}
+
+	log.Printf("🤖 [Automation] Added script %q", script.Name)
+	return script, nil
+}
+
+// UpdateAutomationScript replaces a script's fields. Runs already started keep the old steps.
+func (a *App) UpdateAutomationScript(script AutomationScript) error {
+	if err := validateAutomationScript(&script); err != nil {
+		return err
+	}
+
+	automationStore.mu.Lock()
+	defer automationStore.mu.Unlock()
+	loadAutomationScriptsLocked()
+
+	i := findAutomationScriptLocked(script.ID)
+	if i < 0 {
+		return fmt.Errorf("automation script not found: %s", script.ID)
+	}
+	script.CreatedAt = automationStore.scripts[i].CreatedAt
+	script.UpdatedAt = time.Now().Format(time.RFC3339)
+	automationStore.scripts[i] = script
+	return saveAutomationScriptsLocked()
+}
+
+// DeleteAutomationScript removes a script
+func (a *App) DeleteAutomationScript(id string) error {
+	automationStore.mu.Lock()
+	defer automationStore.mu.Unlock()
+	loadAutomationScriptsLocked()
+
+	i := findAutomationScriptLocked(id)
+	if i < 0 {
+		return fmt.Errorf("automation script not found: %s", id)
+	}
+	automationStore.scripts = append(automationStore.scripts[:i], automationStore.scripts[i+1:]...)
+	return saveAutomationScriptsLocked()
+}
+
+// RunAutomationScript starts a script against a terminal and returns the run
+// ID. Progress arrives as automation:step events and the outcome as an
+// automation:finished event with status "completed", "failed" or "cancelled".
+func (a *App) RunAutomationScript(scriptID string, sessionID string) (string, error) {
+	automationStore.mu.Lock()
+	loadAutomationScriptsLocked()
+	i := findAutomationScriptLocked(scriptID)
+	var script AutomationScript
+	if i >= 0 {
+		script = automationStore.scripts[i]
+	}
+	automationStore.mu.Unlock()
+
+	if i < 0 {
+		return "", fmt.Errorf("automation script not found: %s", scriptID)
+	}
+	program, err := compileAutomation(script.Steps)
+	if err != nil {
+		return "", err
+	}
+
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+	if !termSession.isConnected.Load() {
+		return "", fmt.Errorf("terminal session not connected")
+	}
+
+	run := &automationRun{
+		id:        fmt.Sprintf("automation-run-%d", time.Now().UnixNano()),
+		scriptID:  scriptID,
+		sessionID: sessionID,
+		cancel:    make(chan struct{}),
+	}
+	automationRuns.mu.Lock()
+	for _, r := range automationRuns.runs {
+		if r.sessionID == sessionID {
+			automationRuns.mu.Unlock()
+			return "", fmt.Errorf("terminal %s is already running an automation script", sessionID)
+		}
+	}
+	automationRuns.runs[run.id] = run
+	automationRuns.mu.Unlock()
+
+	// Subscribe now, so output that arrives before the goroutine starts isn't missed
+	output, unsubscribe := termSession.subscribeOutput(256)
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-run.cancel:
+		case <-termSession.stopChan:
+		}
+		close(stop)
+	}()
+
+	log.Printf("🤖 [Automation] Running %q on terminal %s (run %s)", script.Name, sessionID, run.id)
+
+	go func() {
+		defer func() {
+			unsubscribe()
+			run.stop()
+			automationRuns.mu.Lock()
+			delete(automationRuns.runs, run.id)
+			automationRuns.mu.Unlock()
+		}()
+
+		w := &outputWatcher{output: output, stop: stop}
+		send := func(text string) error { return writeToTerminalSession(sessionID, text) }
+		report := func(r automationStepResult) { a.emitAutomationStep(run, program.steps, r) }
+
+		err := program.execute(w, send, report)
+
+		status := "completed"
+		switch {
+		case errors.Is(err, errAutomationStopped):
+			status = "cancelled"
+			select {
+			case <-run.cancel:
+			default:
+				err = fmt.Errorf("terminal closed")
+				status = "failed"
+			}
+		case err != nil:
+			status = "failed"
+		}
+		a.emitAutomationFinished(run, status, err)
+	}()
+
+	return run.id, nil
+}
+
+// stop signals the run to end. Safe to call more than once.
+func (r *automationRun) stop() {
+	r.once.Do(func() { close(r.cancel) })
+}
+
+// CancelAutomationRun stops a running script
+func (a *App) CancelAutomationRun(runID string) error {
+	automationRuns.mu.Lock()
+	run, exists := automationRuns.runs[runID]
+	automationRuns.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("automation run not found: %s", runID)
+	}
+	log.Printf("🛑 [Automation] Cancelling run %s", runID)
+	run.stop()
+	return nil
+}
+
+// emitAutomationStep reports a finished step of a run
+func (a *App) emitAutomationStep(run *automationRun, steps []AutomationStep, r automationStepResult) {
+	if a.ctx == nil {
+		return
+	}
+	step := steps[r.Index]
+	event := map[string]interface{}{
+		"runId":     run.id,
+		"scriptId":  run.scriptID,
+		"sessionId": run.sessionID,
+		"index":     r.Index,
+		"type":      step.Type,
+		"label":     step.Label,
+		"status":    r.Status,
+		"detail":    r.Detail,
+	}
+	if step.Type == AutomationStepSend && !step.Secret {
+		event["text"] = step.Text
+	}
+	wailsRuntime.EventsEmit(a.ctx, "automation:step", event)
+}
+
+// emitAutomationFinished reports how a run ended
+func (a *App) emitAutomationFinished(run *automationRun, status string, err error) {
+	if err != nil && status == "failed" {
+		log.Printf("❌ [Automation] Run %s failed: %v", run.id, err)
+	} else {
+		log.Printf("⏹️ [Automation] Run %s %s", run.id, status)
+	}
+	if a.ctx == nil {
+		return
+	}
+	event := map[string]interface{}{
+		"runId":     run.id,
+		"scriptId":  run.scriptID,
+		"sessionId": run.sessionID,
+		"status":    status,
+	}
+	if err != nil && status == "failed" {
+		event["error"] = err.Error()
+	}
+	wailsRuntime.EventsEmit(a.ctx, "automation:finished", event)
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAutomationExecute(t *testing.T) {
+	steps := []AutomationStep{
+		{Type: AutomationStepSend, Text: "su -", Enter: true},
+		{Label: "prompt", Type: AutomationStepBranch, Branches: []AutomationBranch{
+			{Pattern: `[Pp]assword:`, Goto: "password"},
+			{Pattern: `# $`, Goto: "done"},
+		}},
+		{Label: "password", Type: AutomationStepSend, Text: "secret", Enter: true, Secret: true},
+		{Type: AutomationStepGoto, Goto: "prompt"},
+		{Label: "done", Type: AutomationStepStop},
+		{Type: AutomationStepSend, Text: "never sent"},
+	}
+	program, err := compileAutomation(steps)
+	if err != nil {
+		t.Fatalf("compileAutomation: %v", err)
+	}
+
+	output := make(chan string, 4)
+	output <- "Password: "
+	w := &outputWatcher{output: output, stop: make(chan struct{})}
+
+	var sent []string
+	send := func(text string) error {
+		sent = append(sent, text)
+		if text == "secret\r" {
+			output <- "\r\n\x1b[1mroot@host\x1b[0m:~# "
+		}
+		return nil
+	}
+	var statuses []string
+	report := func(r automationStepResult) { statuses = append(statuses, r.Status) }
+
+	if err := program.execute(w, send, report); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := strings.Join(sent, "|"); got != "su -\r|secret\r" {
+		t.Errorf("Unexpected input: %q", got)
+	}
+	if got := strings.Join(statuses, ","); got != "sent,matched,sent,jumped,matched,stopped" {
+		t.Errorf("Unexpected steps: %s", got)
+	}
+
+	if _, err := compileAutomation([]AutomationStep{{Type: AutomationStepGoto, Goto: "missing"}}); err == nil {
+		t.Errorf("Expected an error for an unknown label")
+	}
+}
+
+func TestAutomationScriptsFileIsPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	automationStore.mu.Lock()
+	savedScripts, savedLoaded := automationStore.scripts, automationStore.loaded
+	automationStore.scripts, automationStore.loaded = nil, false
+	automationStore.mu.Unlock()
+	t.Cleanup(func() {
+		automationStore.mu.Lock()
+		automationStore.scripts, automationStore.loaded = savedScripts, savedLoaded
+		automationStore.mu.Unlock()
+	})
+
+	// Saved readable by everyone before
+	scriptsPath, err := getAppConfigPath("automation-scripts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(scriptsPath, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	script := AutomationScript{Name: "login", Steps: []AutomationStep{{Type: AutomationStepSend, Text: "hunter2", Enter: true, Secret: true}}}
+	if _, err := (&App{}).AddAutomationScript(script); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(scriptsPath); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the scripts file to be private, got %v", info.Mode())
+	}
+}
@@ -0,0 +1,342 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// BroadcastMember is a terminal in a broadcast group
+type BroadcastMember struct {
+	SessionID string `json:"sessionId"`
+	Enabled   bool   `json:"enabled"` // Disabled members stay in the group but get no input
+}
+
+// BroadcastGroup is a set of terminals that receive the same input. While the
+// group is active, typing into any enabled member types into all of them.
+type BroadcastGroup struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Active  bool              `json:"active"`
+	Members []BroadcastMember `json:"members"`
+}
+
+// BroadcastFailure is a member that could not be written to
+type BroadcastFailure struct {
+	SessionID string `json:"sessionId"`
+	Error     string `json:"error"`
+}
+
+// BroadcastResult reports which members received a broadcast
+type BroadcastResult struct {
+	Sent   []string           `json:"sent"`
+	Failed []BroadcastFailure `json:"failed"`
+}
+
+// broadcastStore holds broadcast groups in memory. Terminal sessions don't
+// outlive the app, so neither do the groups. A terminal belongs to at most one group.
+var broadcastStore = struct {
+	mu     sync.RWMutex
+	groups map[string]*BroadcastGroup
+}{
+	groups: make(map[string]*BroadcastGroup),
+}
+
+// findMemberLocked returns the index of a session in a group, or -1. Caller must hold the lock.
+func (g *BroadcastGroup) findMemberLocked(sessionID string) int {
+	for i, m := range g.Members {
+		if m.SessionID == sessionID {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyGroup returns a copy of a group that is safe to hand out
+func copyGroup(g *BroadcastGroup) BroadcastGroup {
+	c := *g
+	c.Members = append([]BroadcastMember(nil), g.Members...)
+	return c
+}
+
+// groupOfLocked returns the group a session belongs to. Caller must hold the lock.
+func groupOfLocked(sessionID string) *BroadcastGroup {
+	for _, g := range broadcastStore.groups {
+		if g.findMemberLocked(sessionID) >= 0 {
+			return g
+		}
+	}
+	return nil
+}
+
+// broadcastGroupFor returns the ID of the active group in which a session is an
+// enabled member, or "" if its input isn't broadcast
+func broadcastGroupFor(sessionID string) string {
+	broadcastStore.mu.RLock()
+	defer broadcastStore.mu.RUnlock()
+
+	g := groupOfLocked(sessionID)
+	if g == nil || !g.Active {
+		return ""
+	}
+	if i := g.findMemberLocked(sessionID); !g.Members[i].Enabled {
+		return ""
+	}
+	return g.ID
+}
+
+// removeBroadcastMember drops a closed terminal from its group
+func removeBroadcastMember(sessionID string) {
+	broadcastStore.mu.Lock()
+	defer broadcastStore.mu.Unlock()
+
+	if g := groupOfLocked(sessionID); g != nil {
+		i := g.findMemberLocked(sessionID)
+		g.Members = append(g.Members[:i], g.Members[i+1:]...)
+	}
+}
+
+// disableBroadcastMembers stops sending to members that disconnected, so a
+// session that later reconnects under the same ID doesn't silently receive input
+func disableBroadcastMembers(groupID string, sessionIDs []string) {
+	broadcastStore.mu.Lock()
+	defer broadcastStore.mu.Unlock()
+
+	g, exists := broadcastStore.groups[groupID]
+	if !exists {
+		return
+	}
+	for _, sessionID := range sessionIDs {
+		if i := g.findMemberLocked(sessionID); i >= 0 {
+			g.Members[i].Enabled = false
+		}
+	}
+}
+
+// broadcast writes data to every enabled member of a group concurrently, so
+// one slow SSH connection doesn't hold up the others
+func broadcast(groupID string, data string) (BroadcastResult, error) {
+	broadcastStore.mu.RLock()
+	g, exists := broadcastStore.groups[groupID]
+	var members []string
+	if exists {
+		for _, m := range g.Members {
+			if m.Enabled {
+				members = append(members, m.SessionID)
+			}
+		}
+	}
+	broadcastStore.mu.RUnlock()
+
+	if !exists {
+		return BroadcastResult{}, fmt.Errorf("broadcast group not found: %s", groupID)
+	}
+
+	errs := make([]error, len(members))
+	var disconnected []string
+	var wg sync.WaitGroup
+	for i, sessionID := range members {
+		termSessionMu.RLock()
+		termSession, exists := terminalSessions[sessionID]
+		termSessionMu.RUnlock()
+
+		if !exists || !termSession.isConnected.Load() {
+			errs[i] = fmt.Errorf("terminal session not connected")
+			disconnected = append(disconnected, sessionID)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, ts *TerminalSession) {
+			defer wg.Done()
+			errs[i] = ts.writeInput(data)
+		}(i, termSession)
+	}
+	wg.Wait()
+
+	if len(disconnected) > 0 {
+		log.Printf("⚠️ [Broadcast] Disabling %d disconnected member(s) of group %s", len(disconnected), groupID)
+		disableBroadcastMembers(groupID, disconnected)
+	}
+
+	result := BroadcastResult{Sent: []string{}, Failed: []BroadcastFailure{}}
+	for i, sessionID := range members {
+		if errs[i] != nil {
+			result.Failed = append(result.Failed, BroadcastFailure{SessionID: sessionID, Error: errs[i].Error()})
+		} else {
+			result.Sent = append(result.Sent, sessionID)
+		}
+	}
+	return result, nil
+}
+
+// broadcastFromTerminal fans input typed into one member out to its group.
+// Failures of other members are reported with a broadcast:failed event; only
+// the terminal being typed into fails the call.
+func (a *App) broadcastFromTerminal(groupID string, sessionID string, data string) error {
+	result, err := broadcast(groupID, data)
+	if err != nil {
+		return err
+	}
+	if len(result.Failed) == 0 {
+		return nil
+	}
+
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "broadcast:failed", map[string]interface{}{
+			"groupId": groupID,
+			"failed":  result.Failed,
+		})
+	}
+	for _, f := range result.Failed {
+		// Input to the terminal's own transfer is ignored, as without broadcasting
+		if f.SessionID == sessionID && f.Error != errZmodemInputDropped.Error() {
+			return fmt.Errorf("%s", f.Error)
+		}
+	}
+	return nil
+}
+
+// CreateBroadcastGroup creates an inactive group with the given terminals as enabled members
+func (a *App) CreateBroadcastGroup(name string, sessionIDs []string) (BroadcastGroup, error) {
+	if name == "" {
+		return BroadcastGroup{}, fmt.Errorf("group name is required")
+	}
+
+	broadcastStore.mu.Lock()
+	defer broadcastStore.mu.Unlock()
+
+	group := &BroadcastGroup{
+		ID:      fmt.Sprintf("broadcast-%d", time.Now().UnixNano()),
+		Name:    name,
+		Members: []BroadcastMember{},
+	}
+	for _, sessionID := range sessionIDs {
+		if err := checkBroadcastCandidateLocked(sessionID); err != nil {
+			return BroadcastGroup{}, err
+		}
+		if group.findMemberLocked(sessionID) < 0 {
+			group.Members = append(group.Members, BroadcastMember{SessionID: sessionID, Enabled: true})
+		}
+	}
+	broadcastStore.groups[group.ID] = group
+
+	log.Printf("📢 [Broadcast] Created group %q with %d member(s)", name, len(group.Members))
+	return copyGroup(group), nil
+}
+
+// checkBroadcastCandidateLocked verifies a terminal exists and isn't in a group yet.
+// Caller must hold the lock.
+func checkBroadcastCandidateLocked(sessionID string) error {
+	termSessionMu.RLock()
+	_, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+	if g := groupOfLocked(sessionID); g != nil {
+		return fmt.Errorf("terminal %s is already in broadcast group %q", sessionID, g.Name)
+	}
+	return nil
+}
+
+// GetBroadcastGroups returns all broadcast groups, sorted by name
+func (a *App) GetBroadcastGroups() []BroadcastGroup {
+	broadcastStore.mu.RLock()
+	defer broadcastStore.mu.RUnlock()
+
+	result := make([]BroadcastGroup, 0, len(broadcastStore.groups))
+	for _, g := range broadcastStore.groups {
+		result = append(result, copyGroup(g))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// DeleteBroadcastGroup removes a group. Its terminals are left untouched.
+func (a *App) DeleteBroadcastGroup(groupID string) error {
+	broadcastStore.mu.Lock()
+	defer broadcastStore.mu.Unlock()
+
+	if _, exists := broadcastStore.groups[groupID]; !exists {
+		return fmt.Errorf("broadcast group not found: %s", groupID)
+	}
+	delete(broadcastStore.groups, groupID)
+	return nil
+}
+
+// SetBroadcastGroupActive turns broadcasting of typed input on or off for a group
+func (a *App) SetBroadcastGroupActive(groupID string, active bool) error {
+	broadcastStore.mu.Lock()
+	defer broadcastStore.mu.Unlock()
+
+	g, exists := broadcastStore.groups[groupID]
+	if !exists {
+		return fmt.Errorf("broadcast group not found: %s", groupID)
+	}
+	g.Active = active
+	log.Printf("📢 [Broadcast] Group %q active: %v", g.Name, active)
+	return nil
+}
+
+// AddBroadcastMember adds a terminal to a group as an enabled member
+func (a *App) AddBroadcastMember(groupID string, sessionID string) error {
+	broadcastStore.mu.Lock()
+	defer broadcastStore.mu.Unlock()
+
+	g, exists := broadcastStore.groups[groupID]
+	if !exists {
+		return fmt.Errorf("broadcast group not found: %s", groupID)
+	}
+	if err := checkBroadcastCandidateLocked(sessionID); err != nil {
+		return err
+	}
+	g.Members = append(g.Members, BroadcastMember{SessionID: sessionID, Enabled: true})
+	return nil
+}
+
+// RemoveBroadcastMember removes a terminal from a group
+func (a *App) RemoveBroadcastMember(groupID string, sessionID string) error {
+	broadcastStore.mu.Lock()
+	defer broadcastStore.mu.Unlock()
+
+	g, exists := broadcastStore.groups[groupID]
+	if !exists {
+		return fmt.Errorf("broadcast group not found: %s", groupID)
+	}
+	i := g.findMemberLocked(sessionID)
+	if i < 0 {
+		return fmt.Errorf("terminal %s is not in broadcast group %q", sessionID, g.Name)
+	}
+	g.Members = append(g.Members[:i], g.Members[i+1:]...)
+	return nil
+}
+
+// SetBroadcastMemberEnabled includes or excludes a member from broadcasts
+// without removing it from the group
+func (a *App) SetBroadcastMemberEnabled(groupID string, sessionID string, enabled bool) error {
+	broadcastStore.mu.Lock()
+	defer broadcastStore.mu.Unlock()
+
+	g, exists := broadcastStore.groups[groupID]
+	if !exists {
+		return fmt.Errorf("broadcast group not found: %s", groupID)
+	}
+	i := g.findMemberLocked(sessionID)
+	if i < 0 {
+		return fmt.Errorf("terminal %s is not in broadcast group %q", sessionID, g.Name)
+	}
+	g.Members[i].Enabled = enabled
+	return nil
+}
+
+// BroadcastToGroup sends input to every enabled member of a group, whether or
+// not the group is active, and reports which members failed
+func (a *App) BroadcastToGroup(groupID string, data string) (BroadcastResult, error) {
+	return broadcast(groupID, data)
+}
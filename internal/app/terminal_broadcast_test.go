@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bytes"
+	"testing"
+)
+
+// nopWriteCloser collects what a terminal is sent
+type nopWriteCloser struct{ bytes.Buffer }
+
+func (w *nopWriteCloser) Close() error { return nil }
+
+// newTestBroadcastTerminals registers connected SSH terminals that collect
+// their input, and removes them when the test ends
+func newTestBroadcastTerminals(t *testing.T, sessionIDs ...string) (map[string]*TerminalSession, map[string]*nopWriteCloser) {
+	t.Helper()
+	sessions := make(map[string]*TerminalSession)
+	inputs := make(map[string]*nopWriteCloser)
+	termSessionMu.Lock()
+	for _, id := range sessionIDs {
+		inputs[id] = &nopWriteCloser{}
+		sessions[id] = &TerminalSession{SessionID: id, StdinPipe: inputs[id]}
+		sessions[id].isConnected.Store(true)
+		terminalSessions[id] = sessions[id]
+	}
+	termSessionMu.Unlock()
+	t.Cleanup(func() {
+		termSessionMu.Lock()
+		for _, id := range sessionIDs {
+			delete(terminalSessions, id)
+		}
+		termSessionMu.Unlock()
+	})
+	return sessions, inputs
+}
+
+func TestBroadcastReportsZmodemMembers(t *testing.T) {
+	a := &App{}
+	sessions, inputs := newTestBroadcastTerminals(t, "bc-idle", "bc-busy")
+	idle, busy := inputs["bc-idle"], inputs["bc-busy"]
+	sessions["bc-busy"].zmodem.Store(&zmodemTransfer{direction: "download"})
+
+	group, err := a.CreateBroadcastGroup("zmodem", []string{"bc-idle", "bc-busy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.DeleteBroadcastGroup(group.ID) })
+
+	result, err := a.BroadcastToGroup(group.ID, "uptime\r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sent) != 1 || result.Sent[0] != "bc-idle" || idle.String() != "uptime\r" {
+		t.Errorf("Expected only the idle terminal to get the input, got %+v", result)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].SessionID != "bc-busy" || result.Failed[0].Error != errZmodemInputDropped.Error() || busy.Len() != 0 {
+		t.Errorf("Expected the transferring terminal to be reported, got %+v", result.Failed)
+	}
+}
+
+func TestBroadcastFansOutTypedInput(t *testing.T) {
+	a := &App{}
+	_, inputs := newTestBroadcastTerminals(t, "bc-web1", "bc-web2", "bc-web3")
+
+	group, err := a.CreateBroadcastGroup("web", []string{"bc-web1", "bc-web2", "bc-web3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.DeleteBroadcastGroup(group.ID) })
+
+	// Until the group is active, typing only reaches the terminal typed into
+	if err := a.WriteToTerminal("bc-web1", "ls\r"); err != nil {
+		t.Fatal(err)
+	}
+	if inputs["bc-web2"].Len() != 0 {
+		t.Errorf("Expected an inactive group not to broadcast")
+	}
+
+	if err := a.SetBroadcastGroupActive(group.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.WriteToTerminal("bc-web2", "uptime\r"); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]string{"bc-web1": "ls\ruptime\r", "bc-web2": "uptime\r", "bc-web3": "uptime\r"} {
+		if got := inputs[id].String(); got != want {
+			t.Errorf("%s: expected %q, got %q", id, want, got)
+		}
+	}
+
+	// Disabled members stay in the group without input
+	if err := a.SetBroadcastMemberEnabled(group.ID, "bc-web3", false); err != nil {
+		t.Fatal(err)
+	}
+	result, err := a.BroadcastToGroup(group.ID, "w\r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sent) != 2 || len(result.Failed) != 0 || inputs["bc-web3"].String() != "uptime\r" {
+		t.Errorf("Expected only the enabled members to get input, got %+v", result)
+	}
+}
+
+func TestBroadcastDisablesDisconnectedMembers(t *testing.T) {
+	a := &App{}
+	sessions, inputs := newTestBroadcastTerminals(t, "bc-up", "bc-down")
+
+	group, err := a.CreateBroadcastGroup("mixed", []string{"bc-up", "bc-down"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.DeleteBroadcastGroup(group.ID) })
+	if err := a.SetBroadcastGroupActive(group.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	sessions["bc-down"].isConnected.Store(false)
+
+	// Typing into the connected member still works
+	if err := a.WriteToTerminal("bc-up", "date\r"); err != nil {
+		t.Fatalf("Expected a disconnected member not to fail the broadcast: %v", err)
+	}
+	if inputs["bc-up"].String() != "date\r" || inputs["bc-down"].Len() != 0 {
+		t.Errorf("Unexpected input %q %q", inputs["bc-up"].String(), inputs["bc-down"].String())
+	}
+
+	for _, g := range a.GetBroadcastGroups() {
+		if g.ID != group.ID {
+			continue
+		}
+		for _, m := range g.Members {
+			if m.Enabled != (m.SessionID == "bc-up") {
+				t.Errorf("Expected only the disconnected member to be disabled, got %+v", g.Members)
+			}
+		}
+	}
+
+	// A session that reconnects under the same ID gets nothing until re-enabled
+	sessions["bc-down"].isConnected.Store(true)
+	result, err := a.BroadcastToGroup(group.ID, "id\r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sent) != 1 || result.Sent[0] != "bc-up" || len(result.Failed) != 0 || inputs["bc-down"].Len() != 0 {
+		t.Errorf("Expected the disabled member to be skipped, got %+v", result)
+	}
+}
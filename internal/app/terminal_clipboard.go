@@ -0,0 +1,244 @@
+package app
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Clipboard policies for OSC 52 writes from a host
+const (
+	ClipboardAsk   = "ask"
+	ClipboardAllow = "allow"
+	ClipboardDeny  = "deny"
+)
+
+// Size limits for OSC 52 clipboard writes
+const (
+	DefaultClipboardMaxBytes = 1 << 20
+	MaxClipboardBytes        = 4 << 20
+)
+
+// maxClipboardOSCLength is how long an unterminated OSC 52 sequence may grow:
+// the base64 encoding of MaxClipboardBytes plus the selection prefix
+const maxClipboardOSCLength = MaxClipboardBytes/3*4 + 64
+
+// clipboardRequestTimeout is how long an "ask" request waits for an answer
+const clipboardRequestTimeout = 2 * time.Minute
+
+// clipboardRequest is an OSC 52 write waiting for the user to allow or deny it
+type clipboardRequest struct {
+	ID        string
+	SessionID string
+	Host      string
+	Text      string
+	CreatedAt time.Time
+}
+
+// pendingClipboardRequests holds "ask" requests, at most one per session
+var pendingClipboardRequests = struct {
+	mu       sync.Mutex
+	requests map[string]*clipboardRequest
+}{
+	requests: make(map[string]*clipboardRequest),
+}
+
+// extractClipboardSequences removes OSC 52 sequences from output so xterm.js
+// never sees them, shifting the offsets of the remaining sequences.
+// Returns the new output and sequences, and the OSC 52 payloads.
+func extractClipboardSequences(output string, seqs []oscSequence) (string, []oscSequence, []string) {
+	var payloads []string
+	var b strings.Builder
+	kept := make([]oscSequence, 0, len(seqs))
+	pos, removed := 0, 0
+	for _, seq := range seqs {
+		if seq.Code != "52" {
+			seq.Start -= removed
+			seq.End -= removed
+			kept = append(kept, seq)
+			continue
+		}
+		b.WriteString(output[pos:seq.Start])
+		pos = seq.End
+		removed += seq.End - seq.Start
+		payloads = append(payloads, seq.Payload)
+	}
+	if payloads == nil {
+		return output, seqs, nil
+	}
+	b.WriteString(output[pos:])
+	return b.String(), kept, payloads
+}
+
+// parseClipboardPayload decodes an OSC 52 payload ("selection;base64").
+// Queries ("?") and clears (empty data) are reported as not ok. Content
+// larger than limit bytes returns an error without being decoded.
+func parseClipboardPayload(payload string, limit int) ([]byte, bool, error) {
+	_, data, found := strings.Cut(payload, ";")
+	if !found || data == "" || data == "?" {
+		return nil, false, nil
+	}
+	if base64.StdEncoding.DecodedLen(len(data)) > limit+2 {
+		return nil, true, fmt.Errorf("clipboard content exceeds %d bytes", limit)
+	}
+
+	// Some programs omit the padding
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "="))
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid base64 clipboard content: %v", err)
+	}
+	if len(raw) > limit {
+		return nil, true, fmt.Errorf("clipboard content exceeds %d bytes", limit)
+	}
+	return raw, true, nil
+}
+
+// handleClipboardSequences strips OSC 52 sequences from a chunk of output and
+// applies the host's clipboard policy to each. output and seqs come from oscParser.Feed.
+func (a *App) handleClipboardSequences(termSession *TerminalSession, output string, seqs []oscSequence) (string, []oscSequence) {
+	output, seqs, payloads := extractClipboardSequences(output, seqs)
+	for _, payload := range payloads {
+		a.handleClipboardWrite(termSession, payload)
+	}
+	return output, seqs
+}
+
+// handleClipboardWrite applies the host's policy to one OSC 52 payload
+func (a *App) handleClipboardWrite(termSession *TerminalSession, payload string) {
+	settings := getHostSettings(termSession.host)
+	limit := settings.ClipboardMaxBytes
+	if limit <= 0 {
+		limit = DefaultClipboardMaxBytes
+	}
+
+	raw, ok, err := parseClipboardPayload(payload, limit)
+	if err != nil {
+		log.Printf("⚠️ [Clipboard] Rejected OSC 52 write from %s: %v", termSession.host, err)
+		a.emitClipboardStatus(termSession, "rejected", 0, err)
+		return
+	}
+	if !ok {
+		// Reading the local clipboard is never allowed, and clears are ignored
+		return
+	}
+
+	// The content is in the remote's encoding
+	text, err := decodeFileContent(termSession.currentEncoding(), raw)
+	if err != nil {
+		text = string(raw)
+	}
+	text = strings.ToValidUTF8(text, "�")
+
+	switch settings.Clipboard {
+	case ClipboardDeny:
+		log.Printf("🚫 [Clipboard] OSC 52 write from %s denied by host policy", termSession.host)
+		a.emitClipboardStatus(termSession, "denied", len(text), nil)
+	case ClipboardAllow:
+		go a.copyClipboardText(termSession, text)
+	default:
+		a.requestClipboardWrite(termSession, text)
+	}
+}
+
+// copyClipboardText writes text to the system clipboard and reports the result
+func (a *App) copyClipboardText(termSession *TerminalSession, text string) {
+	if err := copyTextToSystemClipboard(text); err != nil {
+		log.Printf("❌ [Clipboard] %v", err)
+		a.emitClipboardStatus(termSession, "failed", len(text), err)
+		return
+	}
+	log.Printf("📋 [Clipboard] Copied %d bytes from %s", len(text), termSession.host)
+	a.emitClipboardStatus(termSession, "copied", len(text), nil)
+}
+
+// emitClipboardStatus tells the frontend what happened to an OSC 52 write
+func (a *App) emitClipboardStatus(termSession *TerminalSession, status string, size int, err error) {
+	if a.ctx == nil {
+		return
+	}
+	event := map[string]interface{}{
+		"sessionId": termSession.SessionID,
+		"host":      termSession.host,
+		"status":    status, // "copied", "denied", "rejected" or "failed"
+		"size":      size,
+	}
+	if err != nil {
+		event["error"] = err.Error()
+	}
+	wailsRuntime.EventsEmit(a.ctx, "terminal:clipboard", event)
+}
+
+// requestClipboardWrite asks the user whether to accept an OSC 52 write.
+// A newer request from the same session replaces an unanswered one.
+func (a *App) requestClipboardWrite(termSession *TerminalSession, text string) {
+	request := &clipboardRequest{
+		ID:        fmt.Sprintf("clip-%d", time.Now().UnixNano()),
+		SessionID: termSession.SessionID,
+		Host:      termSession.host,
+		Text:      text,
+		CreatedAt: time.Now(),
+	}
+
+	pendingClipboardRequests.mu.Lock()
+	for id, r := range pendingClipboardRequests.requests {
+		if r.SessionID == request.SessionID || time.Since(r.CreatedAt) > clipboardRequestTimeout {
+			delete(pendingClipboardRequests.requests, id)
+		}
+	}
+	pendingClipboardRequests.requests[request.ID] = request
+	pendingClipboardRequests.mu.Unlock()
+
+	preview := text
+	if utf8.RuneCountInString(preview) > 200 {
+		preview = string([]rune(preview)[:200]) + "…"
+	}
+
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "terminal:clipboard-request", map[string]interface{}{
+			"requestId": request.ID,
+			"sessionId": request.SessionID,
+			"host":      request.Host,
+			"size":      len(text),
+			"preview":   preview,
+		})
+	}
+}
+
+// RespondClipboardRequest answers a "terminal:clipboard-request" event. With
+// remember, the answer becomes the host's clipboard policy.
+func (a *App) RespondClipboardRequest(requestID string, allow bool, remember bool) error {
+	pendingClipboardRequests.mu.Lock()
+	request, exists := pendingClipboardRequests.requests[requestID]
+	delete(pendingClipboardRequests.requests, requestID)
+	pendingClipboardRequests.mu.Unlock()
+
+	if !exists || time.Since(request.CreatedAt) > clipboardRequestTimeout {
+		return fmt.Errorf("clipboard request not found or expired: %s", requestID)
+	}
+
+	if remember {
+		settings := getHostSettings(request.Host)
+		settings.Clipboard = ClipboardDeny
+		if allow {
+			settings.Clipboard = ClipboardAllow
+		}
+		if err := a.SetHostSettings(settings); err != nil {
+			return fmt.Errorf("failed to save clipboard policy: %v", err)
+		}
+	}
+
+	if !allow {
+		return nil
+	}
+	if err := copyTextToSystemClipboard(request.Text); err != nil {
+		return fmt.Errorf("failed to copy to system clipboard: %v", err)
+	}
+	log.Printf("📋 [Clipboard] Copied %d bytes from %s", len(request.Text), request.Host)
+	return nil
+}
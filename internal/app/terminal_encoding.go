@@ -0,0 +1,314 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+)
+
+// DefaultEncoding is the character encoding assumed for terminals and remote files
+const DefaultEncoding = "utf-8"
+
+// TerminalEncoding is a character encoding that can be selected for a terminal or host
+type TerminalEncoding struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+// supportedEncodings lists the selectable encodings (nil means UTF-8, no transcoding)
+var supportedEncodings = []struct {
+	TerminalEncoding
+	enc encoding.Encoding
+}{
+	{TerminalEncoding{"utf-8", "Unicode (UTF-8)"}, nil},
+	{TerminalEncoding{"gbk", "Chinese Simplified (GBK)"}, simplifiedchinese.GBK},
+	{TerminalEncoding{"gb18030", "Chinese Simplified (GB18030)"}, simplifiedchinese.GB18030},
+	{TerminalEncoding{"big5", "Chinese Traditional (Big5)"}, traditionalchinese.Big5},
+	{TerminalEncoding{"shift_jis", "Japanese (Shift-JIS)"}, japanese.ShiftJIS},
+	{TerminalEncoding{"euc-jp", "Japanese (EUC-JP)"}, japanese.EUCJP},
+	{TerminalEncoding{"euc-kr", "Korean (EUC-KR)"}, korean.EUCKR},
+	{TerminalEncoding{"iso-8859-1", "Western (ISO-8859-1, Latin-1)"}, charmap.ISO8859_1},
+	{TerminalEncoding{"iso-8859-15", "Western (ISO-8859-15, Latin-9)"}, charmap.ISO8859_15},
+	{TerminalEncoding{"windows-1252", "Western (Windows-1252)"}, charmap.Windows1252},
+	{TerminalEncoding{"windows-1251", "Cyrillic (Windows-1251)"}, charmap.Windows1251},
+	{TerminalEncoding{"koi8-r", "Cyrillic (KOI8-R)"}, charmap.KOI8R},
+}
+
+// encodingAliases maps common alternative spellings to supported encoding names
+var encodingAliases = map[string]string{
+	"":          "utf-8",
+	"utf8":      "utf-8",
+	"cp936":     "gbk",
+	"gb2312":    "gbk",
+	"big-5":     "big5",
+	"cp950":     "big5",
+	"sjis":      "shift_jis",
+	"shift-jis": "shift_jis",
+	"cp932":     "shift_jis",
+	"eucjp":     "euc-jp",
+	"euckr":     "euc-kr",
+	"cp949":     "euc-kr",
+	"latin1":    "iso-8859-1",
+	"latin-1":   "iso-8859-1",
+	"latin9":    "iso-8859-15",
+	"cp1252":    "windows-1252",
+	"cp1251":    "windows-1251",
+}
+
+// lookupEncoding resolves an encoding name. Returns the canonical name and
+// the encoding, which is nil for UTF-8.
+func lookupEncoding(name string) (string, encoding.Encoding, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := encodingAliases[name]; ok {
+		name = alias
+	}
+	for _, e := range supportedEncodings {
+		if e.Name == name {
+			return e.Name, e.enc, nil
+		}
+	}
+	return "", nil, fmt.Errorf("unsupported encoding: %s", name)
+}
+
+// terminalDecoder turns raw terminal output into UTF-8 text, holding back
+// multi-byte characters split across reads.
+//
+// Thread safety: NOT thread-safe. Guarded by TerminalSession.encodingMu.
+type terminalDecoder interface {
+	AppendAndFlush(newBytes []byte) string
+	Flush() string
+}
+
+// newTerminalDecoder returns a decoder for an encoding name
+func newTerminalDecoder(name string) terminalDecoder {
+	_, enc, err := lookupEncoding(name)
+	if err != nil || enc == nil {
+		return &UTF8SafeBuffer{}
+	}
+	return &encodedBuffer{decoder: enc.NewDecoder()}
+}
+
+// encodedBuffer is the legacy-encoding counterpart of UTF8SafeBuffer: it
+// transcodes output to UTF-8 and keeps incomplete trailing bytes (reported by
+// the decoder as transform.ErrShortSrc) for the next read
+type encodedBuffer struct {
+	decoder transform.Transformer
+	pending []byte
+}
+
+// AppendAndFlush decodes pending plus new bytes and returns the complete characters
+func (b *encodedBuffer) AppendAndFlush(newBytes []byte) string {
+	if len(newBytes) == 0 {
+		return ""
+	}
+
+	combined := append(b.pending, newBytes...)
+	b.pending = nil
+
+	// Safety check: legacy multi-byte characters are at most 4 bytes
+	if len(combined)-len(newBytes) > MaxPendingBytes {
+		log.Printf("⚠️ [EncodedBuffer] Pending bytes exceeded %d bytes, force flushing (possible encoding corruption)", MaxPendingBytes)
+		return b.decode(combined, true)
+	}
+
+	return b.decode(combined, false)
+}
+
+// Flush decodes any remaining bytes (invalid ones become U+FFFD). Called when the session ends.
+func (b *encodedBuffer) Flush() string {
+	if len(b.pending) == 0 {
+		return ""
+	}
+	pending := b.pending
+	b.pending = nil
+	return b.decode(pending, true)
+}
+
+// decode runs the decoder over src, saving an incomplete tail unless atEOF
+func (b *encodedBuffer) decode(src []byte, atEOF bool) string {
+	dst := make([]byte, len(src)*3+16)
+	var out []byte
+	for {
+		nDst, nSrc, err := b.decoder.Transform(dst, src, atEOF)
+		out = append(out, dst[:nDst]...)
+		src = src[nSrc:]
+
+		switch err {
+		case nil:
+			return string(out)
+		case transform.ErrShortDst:
+			if nDst == 0 && nSrc == 0 {
+				dst = make([]byte, len(dst)*2)
+			}
+		case transform.ErrShortSrc:
+			b.pending = append([]byte(nil), src...)
+			return string(out)
+		default:
+			// Decoders substitute U+FFFD for invalid input, so this shouldn't happen
+			log.Printf("⚠️ [EncodedBuffer] Decode failed: %v", err)
+			b.decoder.Reset()
+			return string(out) + strings.ToValidUTF8(string(src), "�")
+		}
+	}
+}
+
+// encodeTerminalInput converts UTF-8 input typed in the terminal to the
+// session's encoding. Characters the encoding can't represent become '?'-like
+// replacements rather than failing the whole write.
+func encodeTerminalInput(name string, data string) []byte {
+	_, enc, err := lookupEncoding(name)
+	if err != nil || enc == nil {
+		return []byte(data)
+	}
+	encoded, err := encoding.ReplaceUnsupported(enc.NewEncoder()).String(data)
+	if err != nil {
+		log.Printf("⚠️ Failed to encode terminal input as %s: %v", name, err)
+		return []byte(data)
+	}
+	return []byte(encoded)
+}
+
+// decodeFileContent converts remote file content to UTF-8
+func decodeFileContent(name string, content []byte) (string, error) {
+	_, enc, err := lookupEncoding(name)
+	if err != nil {
+		return "", err
+	}
+	if enc == nil {
+		return string(content), nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode file as %s: %v", name, err)
+	}
+	return string(decoded), nil
+}
+
+// encodeFileContent converts UTF-8 text to a remote file's encoding. Unlike
+// terminal input, characters the encoding can't represent are an error, so
+// saving a file never silently loses text.
+func encodeFileContent(name string, content string) ([]byte, error) {
+	_, enc, err := lookupEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	if enc == nil {
+		return []byte(content), nil
+	}
+	encoded, err := enc.NewEncoder().Bytes([]byte(content))
+	if err != nil {
+		return nil, fmt.Errorf("content cannot be saved as %s: %v", name, err)
+	}
+	return encoded, nil
+}
+
+// decode runs output from one of the session's streams through its decoder
+func (ts *TerminalSession) decode(stream *terminalDecoder, data []byte) string {
+	ts.encodingMu.Lock()
+	defer ts.encodingMu.Unlock()
+	if *stream == nil {
+		*stream = newTerminalDecoder(ts.encoding)
+	}
+	return (*stream).AppendAndFlush(data)
+}
+
+// flushDecoder returns the bytes a stream's decoder is holding back
+func (ts *TerminalSession) flushDecoder(stream *terminalDecoder) string {
+	ts.encodingMu.Lock()
+	defer ts.encodingMu.Unlock()
+	if *stream == nil {
+		return ""
+	}
+	return (*stream).Flush()
+}
+
+// currentEncoding returns the session's encoding name
+func (ts *TerminalSession) currentEncoding() string {
+	ts.encodingMu.Lock()
+	defer ts.encodingMu.Unlock()
+	if ts.encoding == "" {
+		return DefaultEncoding
+	}
+	return ts.encoding
+}
+
+// remoteFileEncoding returns the encoding for files on an SSH session's host:
+// the live encoding of the session's terminal if one is open, else the host's setting
+func remoteFileEncoding(sessionID string) string {
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+	if exists && !termSession.isLocal {
+		return termSession.currentEncoding()
+	}
+
+	sshManager.mu.RLock()
+	session, exists := sshManager.sessions[sessionID]
+	sshManager.mu.RUnlock()
+	if !exists {
+		return DefaultEncoding
+	}
+	if settings := getHostSettings(session.Config.Host); settings.Encoding != "" {
+		return settings.Encoding
+	}
+	return DefaultEncoding
+}
+
+// GetSupportedEncodings returns the encodings that can be selected for terminals and hosts
+func (a *App) GetSupportedEncodings() []TerminalEncoding {
+	result := make([]TerminalEncoding, 0, len(supportedEncodings))
+	for _, e := range supportedEncodings {
+		result = append(result, e.TerminalEncoding)
+	}
+	return result
+}
+
+// GetTerminalEncoding returns a terminal's current encoding
+func (a *App) GetTerminalEncoding(sessionID string) (string, error) {
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+	return termSession.currentEncoding(), nil
+}
+
+// SetTerminalEncoding switches a running terminal to another encoding.
+// Bytes of a character split across the switch are dropped.
+func (a *App) SetTerminalEncoding(sessionID string, encodingName string) error {
+	name, _, err := lookupEncoding(encodingName)
+	if err != nil {
+		return err
+	}
+
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+
+	termSession.encodingMu.Lock()
+	defer termSession.encodingMu.Unlock()
+
+	termSession.encoding = name
+	for _, stream := range []*terminalDecoder{&termSession.utf8Buffer, &termSession.stdoutBuffer, &termSession.stderrBuffer} {
+		if *stream != nil {
+			*stream = newTerminalDecoder(name)
+		}
+	}
+
+	log.Printf("🔤 Terminal %s encoding set to %s", sessionID, name)
+	return nil
+}
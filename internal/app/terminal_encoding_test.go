@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestEncodedBuffer_SplitGBKCharacter(t *testing.T) {
+	raw, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte("中文 ok"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoder := newTerminalDecoder("gbk")
+
+	// "中" is 2 bytes in GBK; split it across reads
+	out := decoder.AppendAndFlush(raw[:1])
+	if out != "" {
+		t.Errorf("Expected incomplete character to be held, got %q", out)
+	}
+	out = decoder.AppendAndFlush(raw[1:3])
+	if out != "中" {
+		t.Errorf("Expected first character, got %q", out)
+	}
+	out = decoder.AppendAndFlush(raw[3:])
+	if out != "文 ok" {
+		t.Errorf("Expected the rest, got %q", out)
+	}
+	if decoder.Flush() != "" {
+		t.Errorf("Expected nothing pending")
+	}
+}
+
+func TestEncodeTerminalInput(t *testing.T) {
+	encoded := encodeTerminalInput("shift_jis", "ls 日本")
+	decoded, err := decodeFileContent("sjis", encoded)
+	if err != nil || decoded != "ls 日本" {
+		t.Errorf("Expected round trip through Shift-JIS, got %q, %v", decoded, err)
+	}
+
+	if _, err := encodeFileContent("iso-8859-1", "中"); err == nil {
+		t.Errorf("Expected an error for text Latin-1 can't represent")
+	}
+}
@@ -1,12 +1,16 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"unicode/utf8"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 	"golang.org/x/crypto/ssh"
@@ -14,7 +18,7 @@ import (
 
 // TerminalMessage represents a message sent over WebSocket
 type TerminalMessage struct {
-	Type      string `json:"type"`      // "input", "resize", "ping"
+	Type      string `json:"type"`      // "input", "resize", "ping"; shared terminals also send "mode", "output" and "closed"
 	Data      string `json:"data"`      // terminal input data
 	SessionID string `json:"sessionId"` // SSH session ID
 	Rows      int    `json:"rows"`      // terminal rows (for resize)
@@ -32,13 +36,41 @@ type TerminalSession struct {
 	mu          sync.Mutex
 	stopChan    chan struct{}
 	stopOnce    sync.Once // Prevent double-close of stopChan
-	isConnected bool
+	isConnected atomic.Bool
 	isLocal     bool // true for local terminal, false for SSH
+	rows, cols  int  // Current PTY size, guarded by mu
+
+	// tmux/screen session the SSH shell runs in, empty for a plain shell
+	multiplexer string
+	muxSession  string
+
+	// Shell integration state parsed from the output stream
+	host         string            // SSH host alias, or "local"
+	shellMu      sync.Mutex        // Guards cwd, commands and triggerLines (updated by the output readers)
+	cwd          string            // Last working directory reported via OSC 7
+	commands     commandTracker    // OSC 133 semantic prompt state
+	triggerLines triggerLineBuffer // Partial output line for trigger matching
+
+	// Decoders that transcode output to UTF-8 without splitting characters at
+	// read boundaries (UTF8SafeBuffer, or encodedBuffer for legacy encodings)
+	encodingMu   sync.Mutex      // Guards encoding and the decoders (swapped by SetTerminalEncoding)
+	encoding     string          // Character encoding of the session, "utf-8" by default
+	utf8Buffer   terminalDecoder // For local terminal output
+	stdoutBuffer terminalDecoder // For SSH stdout
+	stderrBuffer terminalDecoder // For SSH stderr
+
+	zmodem atomic.Pointer[zmodemTransfer] // ZMODEM transfer in progress, nil if none
+
+	// Backend consumers of the output (startup commands, automation, shares)
+	outputSubsMu sync.Mutex
+	outputSubs   []*outputSubscriber
+	scrollback   []byte // Recent output for share viewers, guarded by outputSubsMu
+}
 
-	// UTF-8 safe buffers to prevent character truncation at byte boundaries
-	utf8Buffer   *UTF8SafeBuffer // For local terminal output
-	stdoutBuffer *UTF8SafeBuffer // For SSH stdout
-	stderrBuffer *UTF8SafeBuffer // For SSH stderr
+// outputSubscriber is a backend consumer of a terminal's output
+type outputSubscriber struct {
+	ch     chan string
+	behind chan struct{} // Closed the first time output is dropped
 }
 
 var (
@@ -46,7 +78,9 @@ var (
 	termSessionMu    sync.RWMutex
 )
 
-// StartTerminalSession starts a PTY session over WebSocket
+// StartTerminalSession starts a PTY session over WebSocket.
+// If the host has a multiplexer configured, the shell runs inside a named
+// tmux/screen session that is reattached on the next connect.
 func (a *App) StartTerminalSession(sessionID string, rows int, cols int) error {
 	sshManager.mu.RLock()
 	session, exists := sshManager.sessions[sessionID]
@@ -56,6 +90,33 @@ func (a *App) StartTerminalSession(sessionID string, rows int, cols int) error {
 		return fmt.Errorf("SSH session not found: %s", sessionID)
 	}
 
+	settings := getHostSettings(session.Config.Host)
+	multiplexer, muxSession := "", ""
+	if settings.Multiplexer != "" {
+		multiplexer = a.resolveMultiplexer(sessionID, settings.Multiplexer)
+		if multiplexer == "" {
+			log.Printf("⚠️ No multiplexer found on %s, starting plain shell", session.Config.Host)
+		} else if settings.MultiplexerSession != "" {
+			muxSession = sanitizeMultiplexerSessionName(settings.MultiplexerSession)
+		} else {
+			muxSession = defaultMultiplexerSessionName(session.Config.Host)
+		}
+	}
+
+	return a.startSSHTerminal(sessionID, rows, cols, multiplexer, muxSession)
+}
+
+// startSSHTerminal opens the PTY channel and output readers for an SSH terminal.
+// When multiplexer is set, the shell is started inside the named tmux/screen session.
+func (a *App) startSSHTerminal(sessionID string, rows int, cols int, multiplexer string, muxSession string) error {
+	sshManager.mu.RLock()
+	session, exists := sshManager.sessions[sessionID]
+	sshManager.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("SSH session not found: %s", sessionID)
+	}
+
 	if !session.Connected || session.Client == nil {
 		return fmt.Errorf("SSH session not connected")
 	}
@@ -66,25 +127,38 @@ func (a *App) StartTerminalSession(sessionID string, rows int, cols int) error {
 		return fmt.Errorf("failed to create SSH session: %v", err)
 	}
 
-	// Set up terminal modes
-	modes := ssh.TerminalModes{
-		ssh.ECHO:          1,
-		ssh.TTY_OP_ISPEED: 14400,
-		ssh.TTY_OP_OSPEED: 14400,
+	// Set up terminal type and modes from the host's settings
+	settings := getHostSettings(session.Config.Host)
+	modes, err := buildTerminalModes(settings.TerminalModes)
+	if err != nil {
+		sshSession.Close()
+		return err
+	}
+	termType := settings.TermType
+	if termType == "" {
+		termType = DefaultTermType
+	}
+	encodingName, _, err := lookupEncoding(settings.Encoding)
+	if err != nil {
+		log.Printf("⚠️ %v, using %s", err, DefaultEncoding)
+		encodingName = DefaultEncoding
 	}
 
 	// Request PTY
-	if err := sshSession.RequestPty("xterm-256color", rows, cols, modes); err != nil {
+	if err := sshSession.RequestPty(termType, rows, cols, modes); err != nil {
 		sshSession.Close()
 		return fmt.Errorf("failed to request PTY: %v", err)
 	}
 
-	// Try to set UTF-8 locale environment variables for proper Chinese/CJK character support.
-	// Many SSH servers reject Setenv requests for security (AcceptEnv not configured),
-	// so we silently ignore errors here. If the remote server doesn't accept these,
-	// the user needs to configure their server's locale manually.
-	_ = sshSession.Setenv("LANG", "en_US.UTF-8")
-	_ = sshSession.Setenv("LC_ALL", "en_US.UTF-8")
+	// Send the locale and environment. Many SSH servers reject Setenv requests
+	// for security (AcceptEnv not configured), so rejections don't fail the
+	// terminal; they are reported to the frontend after it starts.
+	var rejectedEnv []string
+	for _, v := range buildRemoteEnv(settings, session.Config, os.Environ()) {
+		if err := sshSession.Setenv(v.Name, v.Value); err != nil {
+			rejectedEnv = append(rejectedEnv, v.Name)
+		}
+	}
 
 	// Get pipes
 	stdin, err := sshSession.StdinPipe()
@@ -105,8 +179,21 @@ func (a *App) StartTerminalSession(sessionID string, rows int, cols int) error {
 		return fmt.Errorf("failed to get stderr: %v", err)
 	}
 
-	// Start shell
-	if err := sshSession.Shell(); err != nil {
+	// Startup commands go into a new multiplexer session's first pane, but
+	// not into one being reattached, which may be running anything by now
+	runStartup := len(settings.StartupCommands) > 0
+	if runStartup && multiplexer != "" {
+		runStartup = !multiplexerSessionExists(sessionID, multiplexer, muxSession)
+	}
+
+	// Start shell, or attach to the multiplexer session
+	if multiplexer != "" {
+		if err := sshSession.Start(multiplexerCommand(multiplexer, muxSession)); err != nil {
+			sshSession.Close()
+			return fmt.Errorf("failed to start %s session: %v", multiplexer, err)
+		}
+		log.Printf("🔁 Attached terminal %s to %s session %q", sessionID, multiplexer, muxSession)
+	} else if err := sshSession.Shell(); err != nil {
 		sshSession.Close()
 		return fmt.Errorf("failed to start shell: %v", err)
 	}
@@ -117,27 +204,70 @@ func (a *App) StartTerminalSession(sessionID string, rows int, cols int) error {
 		SSHSession:   sshSession,
 		StdinPipe:    stdin,
 		stopChan:     make(chan struct{}),
-		isConnected:  true,
-		stdoutBuffer: &UTF8SafeBuffer{}, // Prevent UTF-8 truncation in stdout
-		stderrBuffer: &UTF8SafeBuffer{}, // Prevent UTF-8 truncation in stderr
+		encoding:     encodingName,
+		stdoutBuffer: newTerminalDecoder(encodingName), // Prevent character truncation in stdout
+		stderrBuffer: newTerminalDecoder(encodingName), // Prevent character truncation in stderr
+		multiplexer:  multiplexer,
+		muxSession:   muxSession,
+		host:         session.Config.Host,
+		rows:         rows,
+		cols:         cols,
 	}
+	termSession.isConnected.Store(true)
 
 	// Store session
 	termSessionMu.Lock()
 	terminalSessions[sessionID] = termSession
 	termSessionMu.Unlock()
 
+	if len(rejectedEnv) > 0 {
+		log.Printf("⚠️ Server %s rejected environment variables: %s", session.Config.Host, strings.Join(rejectedEnv, ", "))
+		if a.ctx != nil {
+			wailsRuntime.EventsEmit(a.ctx, "terminal:env-rejected", map[string]interface{}{
+				"sessionId": sessionID,
+				"host":      session.Config.Host,
+				"variables": rejectedEnv,
+				"message":   "The server did not accept these variables. Add them to AcceptEnv in the server's sshd_config, or set them in the remote shell's profile.",
+			})
+		}
+	}
+
+	if multiplexer != "" && a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "terminal:multiplexer-attached", map[string]interface{}{
+			"sessionId":   sessionID,
+			"multiplexer": multiplexer,
+			"name":        muxSession,
+		})
+	}
+
+	if runStartup {
+		a.runStartupCommands(termSession, settings.StartupCommands)
+	} else if len(settings.StartupCommands) > 0 {
+		reason := fmt.Errorf("reattached to the existing %s session %q, which may already be set up", multiplexer, muxSession)
+		a.skipStartupCommands(termSession, settings.StartupCommands, reason)
+	}
+
 	// Start output readers (these will be sent via WebSocket events)
 	go func() {
+		osc := &oscParser{}
+		zdetect := &zmodemDetector{} // rz/sz start sequences only appear on stdout
 		defer func() {
 			termSessionMu.Lock()
 			if ts, ok := terminalSessions[sessionID]; ok {
-				ts.isConnected = false
+				ts.isConnected.Store(false)
 			}
 			termSessionMu.Unlock()
 
 			// Flush any remaining bytes when session ends
-			if remaining := termSession.stdoutBuffer.Flush(); remaining != "" {
+			if held := zdetect.flush(); len(held) > 0 {
+				if output := termSession.decode(&termSession.stdoutBuffer, held); output != "" {
+					a.handleTerminalOutput(termSession, osc, output)
+				}
+			}
+			if remaining := termSession.flushDecoder(&termSession.stdoutBuffer); remaining != "" {
+				a.handleTerminalOutput(termSession, osc, remaining)
+			}
+			if remaining := osc.Flush(); remaining != "" {
 				a.emitTerminalOutput(sessionID, remaining)
 			}
 		}()
@@ -155,21 +285,32 @@ func (a *App) StartTerminalSession(sessionID string, rows int, cols int) error {
 					}
 					return
 				}
-				if n > 0 {
+				data := buffer[:n]
+				for len(data) > 0 {
+					output, direction, rest := zdetect.scan(data)
 					// Use UTF-8 safe buffer to prevent character truncation
-					completeUTF8 := termSession.stdoutBuffer.AppendAndFlush(buffer[:n])
+					completeUTF8 := termSession.decode(&termSession.stdoutBuffer, output)
 					if completeUTF8 != "" {
-						a.emitTerminalOutput(sessionID, completeUTF8)
+						a.handleTerminalOutput(termSession, osc, completeUTF8)
+					}
+					if direction == "" {
+						break
 					}
+					// The transfer reads stdout until it ends; what it read past the end is output again
+					data = a.runZmodemTransfer(termSession, stdout, direction, rest)
 				}
 			}
 		}
 	}()
 
 	go func() {
+		osc := &oscParser{}
 		defer func() {
 			// Flush any remaining bytes when session ends
-			if remaining := termSession.stderrBuffer.Flush(); remaining != "" {
+			if remaining := termSession.flushDecoder(&termSession.stderrBuffer); remaining != "" {
+				a.handleTerminalOutput(termSession, osc, remaining)
+			}
+			if remaining := osc.Flush(); remaining != "" {
 				a.emitTerminalOutput(sessionID, remaining)
 			}
 		}()
@@ -189,9 +330,9 @@ func (a *App) StartTerminalSession(sessionID string, rows int, cols int) error {
 				}
 				if n > 0 {
 					// Use UTF-8 safe buffer to prevent character truncation
-					completeUTF8 := termSession.stderrBuffer.AppendAndFlush(buffer[:n])
+					completeUTF8 := termSession.decode(&termSession.stderrBuffer, buffer[:n])
 					if completeUTF8 != "" {
-						a.emitTerminalOutput(sessionID, completeUTF8)
+						a.handleTerminalOutput(termSession, osc, completeUTF8)
 					}
 				}
 			}
@@ -201,16 +342,16 @@ func (a *App) StartTerminalSession(sessionID string, rows int, cols int) error {
 	// Monitor session
 	go func() {
 		sshSession.Wait()
-		termSession.mu.Lock()
-		termSession.isConnected = false
-		termSession.mu.Unlock()
+		termSession.isConnected.Store(false)
 		termSession.stopOnce.Do(func() { close(termSession.stopChan) })
 
 		// Emit disconnection event to frontend
 		if a.ctx != nil {
 			wailsRuntime.EventsEmit(a.ctx, "terminal:disconnected", map[string]interface{}{
-				"sessionId": sessionID,
-				"reason":    "SSH session ended",
+				"sessionId":          sessionID,
+				"reason":             "SSH session ended",
+				"multiplexer":        multiplexer,
+				"multiplexerSession": muxSession,
 			})
 		}
 
@@ -220,8 +361,22 @@ func (a *App) StartTerminalSession(sessionID string, rows int, cols int) error {
 	return nil
 }
 
-// WriteToTerminal writes data to the terminal stdin
+// WriteToTerminal writes data to the terminal stdin. If the terminal is an
+// enabled member of an active broadcast group, every enabled member gets it.
+// Typing into a terminal that's running a ZMODEM transfer is ignored.
 func (a *App) WriteToTerminal(sessionID string, data string) error {
+	if group := broadcastGroupFor(sessionID); group != "" {
+		return a.broadcastFromTerminal(group, sessionID, data)
+	}
+	if err := writeToTerminalSession(sessionID, data); !errors.Is(err, errZmodemInputDropped) {
+		return err
+	}
+	return nil
+}
+
+// writeToTerminalSession writes data to a single terminal, never broadcasting.
+// Input generated for one terminal (trigger responses, scripts) goes through here.
+func writeToTerminalSession(sessionID string, data string) error {
 	termSessionMu.RLock()
 	termSession, exists := terminalSessions[sessionID]
 	termSessionMu.RUnlock()
@@ -229,23 +384,38 @@ func (a *App) WriteToTerminal(sessionID string, data string) error {
 	if !exists {
 		return fmt.Errorf("terminal session not found: %s", sessionID)
 	}
+	return termSession.writeInput(data)
+}
 
-	if !termSession.isConnected {
+// writeInput encodes data for the session and writes it to the shell
+func (ts *TerminalSession) writeInput(data string) error {
+	if !ts.isConnected.Load() {
 		return fmt.Errorf("terminal session not connected")
 	}
 
-	termSession.mu.Lock()
-	defer termSession.mu.Unlock()
+	// Keystrokes would corrupt a ZMODEM transfer; Ctrl+C cancels it instead
+	if transfer := ts.zmodem.Load(); transfer != nil {
+		if strings.Contains(data, "\x03") {
+			cancelZmodemTransfer(ts, transfer, "Ctrl+C")
+			return nil
+		}
+		return errZmodemInputDropped
+	}
 
-	if termSession.isLocal {
+	input := encodeTerminalInput(ts.currentEncoding(), data)
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.isLocal {
 		// Local terminal: write to PTY
-		_, err := termSession.LocalStdin.Write([]byte(data))
+		_, err := ts.LocalStdin.Write(input)
 		if err != nil {
 			return fmt.Errorf("failed to write to local terminal: %v", err)
 		}
 	} else {
 		// SSH terminal: write to stdin pipe
-		_, err := termSession.StdinPipe.Write([]byte(data))
+		_, err := ts.StdinPipe.Write(input)
 		if err != nil {
 			return fmt.Errorf("failed to write to terminal: %v", err)
 		}
@@ -264,10 +434,14 @@ func (a *App) ResizeTerminal(sessionID string, rows int, cols int) error {
 		return fmt.Errorf("terminal session not found: %s", sessionID)
 	}
 
-	if !termSession.isConnected {
+	if !termSession.isConnected.Load() {
 		return fmt.Errorf("terminal session not connected")
 	}
 
+	termSession.mu.Lock()
+	termSession.rows, termSession.cols = rows, cols
+	termSession.mu.Unlock()
+
 	if termSession.isLocal {
 		// Local terminal: resize PTY (platform-specific)
 		log.Printf("🖥️ [ResizeTerminal] Resizing LOCAL terminal %s to %dx%d (rows x cols)", sessionID, rows, cols)
@@ -299,10 +473,17 @@ func (a *App) CloseTerminalSession(sessionID string) error {
 		return fmt.Errorf("terminal session not found: %s", sessionID)
 	}
 
+	removeSessionTriggers(sessionID)
+	removeBroadcastMember(sessionID)
+	a.revokeSessionShares(sessionID)
+	if _, err := closeTerminalRecording(sessionID); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+
 	termSession.mu.Lock()
 	defer termSession.mu.Unlock()
 
-	if termSession.isConnected {
+	if termSession.isConnected.Load() {
 		termSession.stopOnce.Do(func() { close(termSession.stopChan) })
 		if termSession.isLocal {
 			// Local terminal: close PTY (platform-specific)
@@ -313,12 +494,99 @@ func (a *App) CloseTerminalSession(sessionID string) error {
 				termSession.SSHSession.Close()
 			}
 		}
-		termSession.isConnected = false
+		termSession.isConnected.Store(false)
 	}
 
 	return nil
 }
 
+// size returns the terminal's current rows and columns
+func (ts *TerminalSession) size() (int, int) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.rows, ts.cols
+}
+
+// handleTerminalOutput runs decoded terminal output through shell integration
+// parsing (working directory, command tracking), output triggers and the
+// session recording before sending it to the frontend. osc is the calling
+// reader's parser.
+func (a *App) handleTerminalOutput(termSession *TerminalSession, osc *oscParser, data string) {
+	output, seqs := osc.Feed(data)
+	output, seqs = a.handleClipboardSequences(termSession, output, seqs)
+	if len(seqs) > 0 || termSession.isCapturingInput() {
+		a.handleShellIntegration(termSession, output, seqs)
+	}
+	if output != "" {
+		termSession.publishOutput(output)
+		a.handleTriggers(termSession, output)
+		recordTerminalOutput(termSession.SessionID, output)
+		a.emitTerminalOutput(termSession.SessionID, output)
+	}
+}
+
+// subscribeOutput returns a channel that receives the terminal's output from
+// now on, and a function to unsubscribe. Output is dropped if the subscriber
+// falls more than size chunks behind.
+func (ts *TerminalSession) subscribeOutput(size int) (<-chan string, func()) {
+	_, output, _, unsubscribe := ts.subscribeOutputWithScrollback(size)
+	return output, unsubscribe
+}
+
+// subscribeOutputWithScrollback is subscribeOutput that also returns the
+// recent output, with nothing lost or repeated between the two, and a channel
+// closed once output was dropped because the subscriber fell behind
+func (ts *TerminalSession) subscribeOutputWithScrollback(size int) (string, <-chan string, <-chan struct{}, func()) {
+	sub := &outputSubscriber{ch: make(chan string, size), behind: make(chan struct{})}
+	ts.outputSubsMu.Lock()
+	ts.outputSubs = append(ts.outputSubs, sub)
+	scrollback := ts.scrollback
+	if len(scrollback) > maxScrollbackBytes {
+		scrollback = scrollback[len(scrollback)-maxScrollbackBytes:]
+	}
+	// Don't start in the middle of a UTF-8 character
+	for len(scrollback) > 0 && !utf8.RuneStart(scrollback[0]) {
+		scrollback = scrollback[1:]
+	}
+	text := string(scrollback)
+	ts.outputSubsMu.Unlock()
+
+	return text, sub.ch, sub.behind, func() {
+		ts.outputSubsMu.Lock()
+		defer ts.outputSubsMu.Unlock()
+		for i, other := range ts.outputSubs {
+			if other == sub {
+				ts.outputSubs = append(ts.outputSubs[:i], ts.outputSubs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// publishOutput hands output to subscribers without ever blocking the reader
+func (ts *TerminalSession) publishOutput(output string) {
+	ts.outputSubsMu.Lock()
+	defer ts.outputSubsMu.Unlock()
+
+	// Trimmed only when twice the limit, so most chunks don't copy the buffer
+	ts.scrollback = append(ts.scrollback, output...)
+	if len(ts.scrollback) > 2*maxScrollbackBytes {
+		ts.scrollback = append([]byte(nil), ts.scrollback[len(ts.scrollback)-maxScrollbackBytes:]...)
+	}
+	for _, sub := range ts.outputSubs {
+		select {
+		case sub.ch <- output:
+		default:
+			select {
+			case <-sub.behind:
+			default:
+				log.Printf("⚠️ Output subscriber of terminal %s is behind, dropping output", ts.SessionID)
+				close(sub.behind)
+			}
+		}
+	}
+}
+
 // emitTerminalOutput sends terminal output to the frontend
 func (a *App) emitTerminalOutput(sessionID string, data string) {
 	// Use Wails runtime to emit event to frontend
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Defaults for SSH terminal options, used when a host doesn't override them
+const (
+	DefaultTermType = "xterm-256color"
+	DefaultLocale   = "en_US.UTF-8"
+)
+
+// terminalModeOpcodes maps RFC 4254 terminal mode names to their opcodes
+var terminalModeOpcodes = map[string]uint8{
+	// Special characters
+	"VINTR": ssh.VINTR, "VQUIT": ssh.VQUIT, "VERASE": ssh.VERASE, "VKILL": ssh.VKILL,
+	"VEOF": ssh.VEOF, "VEOL": ssh.VEOL, "VEOL2": ssh.VEOL2, "VSTART": ssh.VSTART,
+	"VSTOP": ssh.VSTOP, "VSUSP": ssh.VSUSP, "VDSUSP": ssh.VDSUSP, "VREPRINT": ssh.VREPRINT,
+	"VWERASE": ssh.VWERASE, "VLNEXT": ssh.VLNEXT, "VFLUSH": ssh.VFLUSH, "VSWTCH": ssh.VSWTCH,
+	"VSTATUS": ssh.VSTATUS, "VDISCARD": ssh.VDISCARD,
+
+	// Input modes
+	"IGNPAR": ssh.IGNPAR, "PARMRK": ssh.PARMRK, "INPCK": ssh.INPCK, "ISTRIP": ssh.ISTRIP,
+	"INLCR": ssh.INLCR, "IGNCR": ssh.IGNCR, "ICRNL": ssh.ICRNL, "IUCLC": ssh.IUCLC,
+	"IXON": ssh.IXON, "IXANY": ssh.IXANY, "IXOFF": ssh.IXOFF, "IMAXBEL": ssh.IMAXBEL,
+	"IUTF8": ssh.IUTF8,
+
+	// Local modes
+	"ISIG": ssh.ISIG, "ICANON": ssh.ICANON, "XCASE": ssh.XCASE, "ECHO": ssh.ECHO,
+	"ECHOE": ssh.ECHOE, "ECHOK": ssh.ECHOK, "ECHONL": ssh.ECHONL, "NOFLSH": ssh.NOFLSH,
+	"TOSTOP": ssh.TOSTOP, "IEXTEN": ssh.IEXTEN, "ECHOCTL": ssh.ECHOCTL, "ECHOKE": ssh.ECHOKE,
+	"PENDIN": ssh.PENDIN,
+
+	// Output and control modes
+	"OPOST": ssh.OPOST, "OLCUC": ssh.OLCUC, "ONLCR": ssh.ONLCR, "OCRNL": ssh.OCRNL,
+	"ONOCR": ssh.ONOCR, "ONLRET": ssh.ONLRET,
+	"CS7": ssh.CS7, "CS8": ssh.CS8, "PARENB": ssh.PARENB, "PARODD": ssh.PARODD,
+
+	// Baud rates
+	"TTY_OP_ISPEED": ssh.TTY_OP_ISPEED, "TTY_OP_OSPEED": ssh.TTY_OP_OSPEED,
+}
+
+// buildTerminalModes returns the default PTY modes with a host's overrides applied
+func buildTerminalModes(overrides map[string]uint32) (ssh.TerminalModes, error) {
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	for name, value := range overrides {
+		opcode, ok := terminalModeOpcodes[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown terminal mode: %s", name)
+		}
+		modes[opcode] = value
+	}
+	return modes, nil
+}
+
+// remoteEnvVar is an environment variable sent to the server with Setenv
+type remoteEnvVar struct {
+	Name  string
+	Value string
+}
+
+// sendEnvMatches reports whether a local variable is selected by SendEnv
+// patterns. A pattern starting with '-' deselects what earlier patterns matched.
+func sendEnvMatches(name string, patterns []string) bool {
+	selected := false
+	for _, pattern := range patterns {
+		if negated, ok := strings.CutPrefix(pattern, "-"); ok {
+			if matched, _ := filepath.Match(negated, name); matched {
+				selected = false
+			}
+		} else if matched, _ := filepath.Match(pattern, name); matched {
+			selected = true
+		}
+	}
+	return selected
+}
+
+// buildRemoteEnv works out the variables to send for a terminal, in increasing
+// priority: the host's locale, local variables selected by SendEnv, SetEnv from
+// ~/.ssh/config and the host's own Env. environ is in os.Environ() form.
+func buildRemoteEnv(settings HostSettings, config SSHConfigEntry, environ []string) []remoteEnvVar {
+	vars := make(map[string]string)
+
+	locale := settings.Locale
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	if locale != "none" {
+		vars["LANG"] = locale
+		vars["LC_ALL"] = locale
+	}
+
+	if len(config.SendEnv) > 0 {
+		for _, entry := range environ {
+			name, value, ok := strings.Cut(entry, "=")
+			if ok && name != "" && sendEnvMatches(name, config.SendEnv) {
+				vars[name] = value
+			}
+		}
+	}
+	for name, value := range config.SetEnv {
+		vars[name] = value
+	}
+	for name, value := range settings.Env {
+		vars[name] = value
+	}
+
+	result := make([]remoteEnvVar, 0, len(vars))
+	for name, value := range vars {
+		result = append(result, remoteEnvVar{Name: name, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestBuildRemoteEnv(t *testing.T) {
+	settings := HostSettings{Locale: "zh_CN.UTF-8", Env: map[string]string{"APP_ENV": "staging"}}
+	config := SSHConfigEntry{
+		SetEnv:  map[string]string{"APP_ENV": "prod", "FOO": "bar"},
+		SendEnv: []string{"LC_*", "GIT_*", "-GIT_SECRET"},
+	}
+	environ := []string{"LC_TIME=C", "GIT_AUTHOR_NAME=me", "GIT_SECRET=x", "HOME=/home/me"}
+
+	vars := make(map[string]string)
+	for _, v := range buildRemoteEnv(settings, config, environ) {
+		vars[v.Name] = v.Value
+	}
+
+	expected := map[string]string{
+		"LANG": "zh_CN.UTF-8", "LC_ALL": "zh_CN.UTF-8", "LC_TIME": "C",
+		"GIT_AUTHOR_NAME": "me", "FOO": "bar", "APP_ENV": "staging",
+	}
+	if len(vars) != len(expected) {
+		t.Errorf("Expected %d variables, got %v", len(expected), vars)
+	}
+	for name, value := range expected {
+		if vars[name] != value {
+			t.Errorf("Expected %s=%s, got %q", name, value, vars[name])
+		}
+	}
+
+	if env := buildRemoteEnv(HostSettings{Locale: "none"}, SSHConfigEntry{}, environ); len(env) != 0 {
+		t.Errorf("Expected no variables with locale disabled, got %v", env)
+	}
+}
+
+func TestSplitSSHConfigArgs(t *testing.T) {
+	args := splitSSHConfigArgs(`FOO=bar "GREETING=hello world" EMPTY=`)
+	if len(args) != 3 || args[0] != "FOO=bar" || args[1] != "GREETING=hello world" || args[2] != "EMPTY=" {
+		t.Errorf("Unexpected args: %q", args)
+	}
+}
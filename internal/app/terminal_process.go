@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// TerminalProcess describes the foreground process of a local terminal
+type TerminalProcess struct {
+	PID      int    `json:"pid"`
+	Name     string `json:"name"`
+	ShellPID int    `json:"shellPid"`
+	IsShell  bool   `json:"isShell"` // The shell itself is in the foreground: no command is running
+}
+
+// terminalSignals are the signals that can be sent to a local terminal's foreground process
+var terminalSignals = []string{"SIGINT", "SIGTERM", "SIGKILL", "SIGTSTP", "SIGCONT"}
+
+// normalizeSignalName accepts "SIGINT", "sigint" or "INT" and returns "SIGINT"
+func normalizeSignalName(name string) (string, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	for _, s := range terminalSignals {
+		if s == name {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported signal: %s (must be one of %s)", name, strings.Join(terminalSignals, ", "))
+}
+
+// getLocalTerminalSession returns a connected local terminal session
+func getLocalTerminalSession(sessionID string) (*TerminalSession, error) {
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+	if !termSession.isLocal {
+		return nil, fmt.Errorf("process control is only available for local terminals")
+	}
+	if !termSession.isConnected.Load() {
+		return nil, fmt.Errorf("terminal session not connected")
+	}
+	return termSession, nil
+}
+
+// GetTerminalForegroundProcess returns the process currently in the
+// foreground of a local terminal
+func (a *App) GetTerminalForegroundProcess(sessionID string) (TerminalProcess, error) {
+	termSession, err := getLocalTerminalSession(sessionID)
+	if err != nil {
+		return TerminalProcess{}, err
+	}
+	return foregroundProcess(termSession)
+}
+
+// IsTerminalBusy reports whether a command is running in a local terminal,
+// so the frontend can confirm before closing its tab
+func (a *App) IsTerminalBusy(sessionID string) (bool, error) {
+	termSession, err := getLocalTerminalSession(sessionID)
+	if err != nil {
+		return false, err
+	}
+	process, err := foregroundProcess(termSession)
+	if err != nil {
+		return false, err
+	}
+	return !process.IsShell, nil
+}
+
+// SignalTerminalProcess sends a signal (SIGINT, SIGTERM, SIGKILL, SIGTSTP or
+// SIGCONT) to the foreground process group of a local terminal
+func (a *App) SignalTerminalProcess(sessionID string, signal string) error {
+	name, err := normalizeSignalName(signal)
+	if err != nil {
+		return err
+	}
+	termSession, err := getLocalTerminalSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err := signalForegroundProcess(termSession, name); err != nil {
+		return fmt.Errorf("failed to send %s: %v", name, err)
+	}
+	log.Printf("📡 Sent %s to foreground process of terminal %s", name, sessionID)
+	return nil
+}
+
+// emitLocalTerminalExit reports a local shell's exit status in terminal:disconnected.
+// signal is the terminating signal's name, or empty if the process exited normally.
+func (a *App) emitLocalTerminalExit(sessionID string, exitCode int, signal string) {
+	reason := fmt.Sprintf("Process exited with code %d", exitCode)
+	if signal != "" {
+		reason = "Process killed by " + signal
+	}
+	log.Printf("Local terminal session ended: %s (%s)", sessionID, reason)
+
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "terminal:disconnected", map[string]interface{}{
+			"sessionId": sessionID,
+			"reason":    reason,
+			"exitCode":  exitCode,
+			"signal":    signal,
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTerminalProfileID is the ID of the built-in profile used when no profiles are saved
+const DefaultTerminalProfileID = "default"
+
+// TerminalProfile describes how a local terminal's shell is started
+type TerminalProfile struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Shell      string            `json:"shell"`      // Executable path or name, empty for $SHELL (COMSPEC on Windows)
+	Args       []string          `json:"args"`       // Arguments passed to the shell
+	LoginShell bool              `json:"loginShell"` // Prepend -l (ignored on Windows)
+	Env        map[string]string `json:"env"`        // Variables to add or override
+	UnsetEnv   []string          `json:"unsetEnv"`   // Variables to remove; a trailing * matches a prefix
+	Locale     string            `json:"locale"`     // Sets LANG and LC_ALL, empty keeps the inherited locale
+	Term       string            `json:"term"`       // TERM value, empty keeps the inherited TERM (xterm-256color if unset)
+	WorkingDir string            `json:"workingDir"` // Starting directory, ~ is expanded. Empty for the app's directory
+	IsDefault  bool              `json:"isDefault"`
+
+	// StartupCommands are typed into the shell once it's ready
+	StartupCommands []StartupCommand `json:"startupCommands"`
+}
+
+// builtinTerminalProfile returns the profile matching the app's original behavior:
+// the user's shell without arguments, Python venv variables removed and a UTF-8
+// locale forced on Unix (venv variables break shell hooks in a child shell, and
+// the locale is needed for CJK input)
+func builtinTerminalProfile() TerminalProfile {
+	profile := TerminalProfile{
+		ID:        DefaultTerminalProfileID,
+		Name:      "Default",
+		IsDefault: true,
+	}
+	if runtime.GOOS != "windows" {
+		profile.UnsetEnv = []string{"VIRTUAL_ENV*"}
+		profile.Locale = "en_US.UTF-8"
+	}
+	return profile
+}
+
+// terminalProfileStore holds local terminal profiles, backed by terminal-profiles.json
+var terminalProfileStore = struct {
+	mu       sync.Mutex
+	loaded   bool
+	profiles []TerminalProfile
+}{}
+
+// loadTerminalProfilesLocked reads terminal-profiles.json once, falling back to
+// the built-in profile. Caller must hold the lock.
+func loadTerminalProfilesLocked() {
+	if terminalProfileStore.loaded {
+		return
+	}
+	terminalProfileStore.loaded = true
+	terminalProfileStore.profiles = []TerminalProfile{builtinTerminalProfile()}
+
+	profilesPath, err := getAppConfigPath("terminal-profiles.json")
+	if err != nil {
+		log.Printf("⚠️ [Profiles] Failed to get profiles path: %v", err)
+		return
+	}
+
+	data, err := os.ReadFile(profilesPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ [Profiles] Failed to read terminal profiles: %v", err)
+		}
+		return
+	}
+
+	var profiles []TerminalProfile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		log.Printf("⚠️ [Profiles] Failed to parse terminal profiles: %v", err)
+		return
+	}
+	if len(profiles) > 0 {
+		terminalProfileStore.profiles = profiles
+		ensureDefaultTerminalProfileLocked()
+	}
+}
+
+// ensureDefaultTerminalProfileLocked makes sure exactly one profile is the default.
+// Caller must hold the lock.
+func ensureDefaultTerminalProfileLocked() {
+	found := false
+	for i := range terminalProfileStore.profiles {
+		if terminalProfileStore.profiles[i].IsDefault && !found {
+			found = true
+		} else {
+			terminalProfileStore.profiles[i].IsDefault = false
+		}
+	}
+	if !found && len(terminalProfileStore.profiles) > 0 {
+		terminalProfileStore.profiles[0].IsDefault = true
+	}
+}
+
+// saveTerminalProfilesLocked writes terminal-profiles.json. Caller must hold the lock.
+func saveTerminalProfilesLocked() error {
+	profilesPath, err := getAppConfigPath("terminal-profiles.json")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(terminalProfileStore.profiles, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal terminal profiles: %v", err)
+	}
+
+	if err := os.WriteFile(profilesPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save terminal profiles: %v", err)
+	}
+	return nil
+}
+
+// getTerminalProfile returns a profile by ID, or the default profile if profileID is empty
+func getTerminalProfile(profileID string) (TerminalProfile, error) {
+	terminalProfileStore.mu.Lock()
+	defer terminalProfileStore.mu.Unlock()
+	loadTerminalProfilesLocked()
+
+	for _, profile := range terminalProfileStore.profiles {
+		if (profileID == "" && profile.IsDefault) || (profileID != "" && profile.ID == profileID) {
+			return profile, nil
+		}
+	}
+	if profileID == "" {
+		return builtinTerminalProfile(), nil
+	}
+	return TerminalProfile{}, fmt.Errorf("terminal profile not found: %s", profileID)
+}
+
+// envKeyMatches reports whether an environment variable name matches an
+// UnsetEnv pattern (exact name, or prefix with a trailing *)
+func envKeyMatches(key, pattern string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(key, prefix)
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(key, pattern)
+	}
+	return key == pattern
+}
+
+// buildProfileEnv applies a profile's environment settings to base (in
+// os.Environ() form) and returns the environment for the shell
+func buildProfileEnv(base []string, profile TerminalProfile) []string {
+	overrides := make(map[string]string)
+	for key, value := range profile.Env {
+		overrides[key] = value
+	}
+	if profile.Locale != "" {
+		overrides["LANG"] = profile.Locale
+		overrides["LC_ALL"] = profile.Locale
+	}
+	if profile.Term != "" {
+		overrides["TERM"] = profile.Term
+	}
+
+	env := make([]string, 0, len(base)+len(overrides)+1)
+	termSet := false
+	for _, entry := range base {
+		key, _, _ := strings.Cut(entry, "=")
+		if _, ok := overrides[key]; ok {
+			continue
+		}
+		removed := false
+		for _, pattern := range profile.UnsetEnv {
+			if envKeyMatches(key, pattern) {
+				removed = true
+				break
+			}
+		}
+		if removed {
+			continue
+		}
+		if key == "TERM" {
+			termSet = true
+		}
+		env = append(env, entry)
+	}
+
+	// Set TERM to xterm-256color if not already set (fixes Chinese input and delete key)
+	if !termSet && profile.Term == "" && runtime.GOOS != "windows" {
+		env = append(env, "TERM=xterm-256color")
+	}
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+overrides[key])
+	}
+	return env
+}
+
+// profileWorkingDir picks the starting directory: the explicit initialDir, else
+// the profile's. Returns an empty string if neither is a valid directory.
+func profileWorkingDir(initialDir string, profile TerminalProfile) string {
+	for _, dir := range []string{initialDir, profile.WorkingDir} {
+		if dir == "" {
+			continue
+		}
+		expanded, err := expandHome(dir)
+		if err != nil {
+			log.Printf("⚠️ Invalid initial directory %s: %v", dir, err)
+			continue
+		}
+		if stat, err := os.Stat(expanded); err == nil && stat.IsDir() {
+			return expanded
+		}
+		log.Printf("⚠️ Invalid initial directory %s, using default", dir)
+	}
+	return ""
+}
+
+// GetTerminalProfiles returns the saved local terminal profiles
+func (a *App) GetTerminalProfiles() []TerminalProfile {
+	terminalProfileStore.mu.Lock()
+	defer terminalProfileStore.mu.Unlock()
+	loadTerminalProfilesLocked()
+
+	return append([]TerminalProfile(nil), terminalProfileStore.profiles...)
+}
+
+// SaveTerminalProfile creates a profile (empty ID) or updates an existing one
+func (a *App) SaveTerminalProfile(profile TerminalProfile) (TerminalProfile, error) {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return TerminalProfile{}, fmt.Errorf("profile name is required")
+	}
+	for key := range profile.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return TerminalProfile{}, fmt.Errorf("invalid environment variable name: %q", key)
+		}
+	}
+	if _, err := validateStartupCommands(profile.StartupCommands); err != nil {
+		return TerminalProfile{}, err
+	}
+
+	terminalProfileStore.mu.Lock()
+	defer terminalProfileStore.mu.Unlock()
+	loadTerminalProfilesLocked()
+
+	if profile.ID == "" {
+		profile.ID = fmt.Sprintf("profile-%d", time.Now().UnixNano())
+		terminalProfileStore.profiles = append(terminalProfileStore.profiles, profile)
+	} else {
+		found := false
+		for i := range terminalProfileStore.profiles {
+			if terminalProfileStore.profiles[i].ID == profile.ID {
+				// Clearing the flag on the default profile is ignored; another must be chosen instead
+				profile.IsDefault = profile.IsDefault || terminalProfileStore.profiles[i].IsDefault
+				terminalProfileStore.profiles[i] = profile
+				found = true
+				break
+			}
+		}
+		if !found {
+			return TerminalProfile{}, fmt.Errorf("terminal profile not found: %s", profile.ID)
+		}
+	}
+
+	if profile.IsDefault {
+		for i := range terminalProfileStore.profiles {
+			terminalProfileStore.profiles[i].IsDefault = terminalProfileStore.profiles[i].ID == profile.ID
+		}
+	}
+	ensureDefaultTerminalProfileLocked()
+
+	if err := saveTerminalProfilesLocked(); err != nil {
+		return TerminalProfile{}, err
+	}
+	log.Printf("💾 Saved terminal profile %q", profile.Name)
+	return profile, nil
+}
+
+// DeleteTerminalProfile removes a profile. The last remaining profile can't be deleted.
+func (a *App) DeleteTerminalProfile(profileID string) error {
+	terminalProfileStore.mu.Lock()
+	defer terminalProfileStore.mu.Unlock()
+	loadTerminalProfilesLocked()
+
+	if len(terminalProfileStore.profiles) <= 1 {
+		return fmt.Errorf("cannot delete the only terminal profile")
+	}
+
+	for i, profile := range terminalProfileStore.profiles {
+		if profile.ID == profileID {
+			terminalProfileStore.profiles = append(terminalProfileStore.profiles[:i], terminalProfileStore.profiles[i+1:]...)
+			ensureDefaultTerminalProfileLocked()
+			return saveTerminalProfilesLocked()
+		}
+	}
+	return fmt.Errorf("terminal profile not found: %s", profileID)
+}
+
+// SetDefaultTerminalProfile marks a profile as the one used for new local terminals
+func (a *App) SetDefaultTerminalProfile(profileID string) error {
+	terminalProfileStore.mu.Lock()
+	defer terminalProfileStore.mu.Unlock()
+	loadTerminalProfilesLocked()
+
+	found := false
+	for _, profile := range terminalProfileStore.profiles {
+		found = found || profile.ID == profileID
+	}
+	if !found {
+		return fmt.Errorf("terminal profile not found: %s", profileID)
+	}
+
+	for i := range terminalProfileStore.profiles {
+		terminalProfileStore.profiles[i].IsDefault = terminalProfileStore.profiles[i].ID == profileID
+	}
+	return saveTerminalProfilesLocked()
+}
+
+// StartLocalTerminalSession starts a local terminal with the default profile
+func (a *App) StartLocalTerminalSession(sessionID string, rows int, cols int, initialDir string) error {
+	return a.StartLocalTerminalSessionWithProfile(sessionID, rows, cols, initialDir, "")
+}
+
+// StartLocalTerminalSessionWithProfile starts a local terminal with the given
+// profile (the default profile if profileID is empty). initialDir, if set,
+// overrides the profile's working directory.
+func (a *App) StartLocalTerminalSessionWithProfile(sessionID string, rows int, cols int, initialDir string, profileID string) error {
+	profile, err := getTerminalProfile(profileID)
+	if err != nil {
+		return err
+	}
+	return a.startLocalTerminal(sessionID, rows, cols, initialDir, profile)
+}
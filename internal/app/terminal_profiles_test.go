@@ -0,0 +1,39 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildProfileEnv(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "VIRTUAL_ENV=/venv", "VIRTUAL_ENV_PROMPT=(venv)", "LANG=C", "EDITOR=vi"}
+	profile := TerminalProfile{
+		UnsetEnv: []string{"VIRTUAL_ENV*", "EDITOR"},
+		Env:      map[string]string{"FOO": "bar", "PATH": "/opt/bin"},
+		Locale:   "en_US.UTF-8",
+	}
+
+	env := strings.Join(buildProfileEnv(base, profile), "\n")
+	for _, want := range []string{"PATH=/opt/bin", "FOO=bar", "LANG=en_US.UTF-8", "LC_ALL=en_US.UTF-8", "TERM=xterm-256color"} {
+		if !strings.Contains(env, want) {
+			t.Errorf("Expected %s in environment:\n%s", want, env)
+		}
+	}
+	for _, unwanted := range []string{"VIRTUAL_ENV", "EDITOR", "LANG=C", "PATH=/usr/bin"} {
+		if strings.Contains(env, unwanted) {
+			t.Errorf("Expected %s to be removed from environment:\n%s", unwanted, env)
+		}
+	}
+}
+
+func TestBuildProfileEnv_KeepsInheritedTerm(t *testing.T) {
+	env := buildProfileEnv([]string{"TERM=screen"}, TerminalProfile{})
+	if len(env) != 1 || env[0] != "TERM=screen" {
+		t.Errorf("Expected inherited TERM to be kept, got %v", env)
+	}
+
+	env = buildProfileEnv([]string{"TERM=screen"}, TerminalProfile{Term: "xterm-kitty"})
+	if len(env) != 1 || env[0] != "TERM=xterm-kitty" {
+		t.Errorf("Expected profile TERM to override, got %v", env)
+	}
+}
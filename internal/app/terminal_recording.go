@@ -0,0 +1,181 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// terminalRecording writes a session's output to an asciicast v2 file
+// (https://docs.asciinema.org/manual/asciicast/v2/), playable with asciinema
+type terminalRecording struct {
+	path    string
+	file    *os.File
+	started time.Time
+	mu      sync.Mutex
+}
+
+var (
+	terminalRecordings   = make(map[string]*terminalRecording)
+	terminalRecordingsMu sync.Mutex
+)
+
+// unsafeFileNameChars matches characters not wanted in recording file names
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// getRecordingsDir returns the directory recordings are saved to
+func getRecordingsDir() (string, error) {
+	configPath, err := getAppConfigPath("recordings")
+	if err != nil {
+		return "", err
+	}
+	// Recordings hold everything a terminal printed, so only the user may read them
+	if err := os.MkdirAll(configPath, 0700); err != nil {
+		return "", fmt.Errorf("failed to create recordings directory: %v", err)
+	}
+	if err := os.Chmod(configPath, 0700); err != nil {
+		return "", fmt.Errorf("failed to restrict recordings directory: %v", err)
+	}
+	return configPath, nil
+}
+
+// StartTerminalRecording starts recording a terminal's output and returns the recording file path.
+// If the session is already being recorded, the existing path is returned.
+func (a *App) StartTerminalRecording(sessionID string) (string, error) {
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+
+	terminalRecordingsMu.Lock()
+	defer terminalRecordingsMu.Unlock()
+
+	if rec, ok := terminalRecordings[sessionID]; ok {
+		return rec.path, nil
+	}
+
+	dir, err := getRecordingsDir()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	// The session ID keeps terminals on the same host that start recording
+	// in the same second apart
+	name := fmt.Sprintf("%s-%s-%s.cast", unsafeFileNameChars.ReplaceAllString(termSession.host, "_"),
+		now.Format("20060102-150405"), unsafeFileNameChars.ReplaceAllString(sessionID, "_"))
+	path := filepath.Join(dir, name)
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create recording file: %v", err)
+	}
+
+	rows, cols := termSession.size()
+	header, _ := json.Marshal(map[string]interface{}{
+		"version":   2,
+		"width":     cols,
+		"height":    rows,
+		"timestamp": now.Unix(),
+		"title":     termSession.host,
+	})
+
+	if _, err := file.Write(append(header, '\n')); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write recording header: %v", err)
+	}
+	rec := &terminalRecording{path: path, file: file, started: now}
+
+	terminalRecordings[sessionID] = rec
+	log.Printf("⏺️ Recording terminal %s to %s", sessionID, path)
+
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "terminal:recording", map[string]interface{}{
+			"sessionId": sessionID,
+			"recording": true,
+			"path":      path,
+		})
+	}
+
+	return path, nil
+}
+
+// StopTerminalRecording stops recording a terminal and returns the recording file path
+func (a *App) StopTerminalRecording(sessionID string) (string, error) {
+	rec, err := closeTerminalRecording(sessionID)
+	if rec == nil {
+		return "", fmt.Errorf("terminal session is not being recorded: %s", sessionID)
+	}
+	if err != nil {
+		return rec.path, err
+	}
+
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "terminal:recording", map[string]interface{}{
+			"sessionId": sessionID,
+			"recording": false,
+			"path":      rec.path,
+		})
+	}
+
+	return rec.path, nil
+}
+
+// closeTerminalRecording stops a session's recording, if one is active.
+// Returns nil if the session wasn't being recorded.
+func closeTerminalRecording(sessionID string) (*terminalRecording, error) {
+	terminalRecordingsMu.Lock()
+	rec, ok := terminalRecordings[sessionID]
+	delete(terminalRecordings, sessionID)
+	terminalRecordingsMu.Unlock()
+
+	if !ok {
+		return nil, nil
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if err := rec.file.Close(); err != nil {
+		return rec, fmt.Errorf("failed to close recording file: %v", err)
+	}
+
+	log.Printf("⏹️ Stopped recording terminal %s", sessionID)
+	return rec, nil
+}
+
+// IsTerminalRecording reports whether a terminal's output is being recorded
+func (a *App) IsTerminalRecording(sessionID string) bool {
+	terminalRecordingsMu.Lock()
+	defer terminalRecordingsMu.Unlock()
+	_, ok := terminalRecordings[sessionID]
+	return ok
+}
+
+// recordTerminalOutput appends output to the session's recording, if one is active
+func recordTerminalOutput(sessionID string, data string) {
+	terminalRecordingsMu.Lock()
+	rec, ok := terminalRecordings[sessionID]
+	terminalRecordingsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	event, err := json.Marshal([]interface{}{time.Since(rec.started).Seconds(), "o", data})
+	if err != nil {
+		return
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.file.Write(append(event, '\n'))
+}
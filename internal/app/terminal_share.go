@@ -0,0 +1,531 @@
+package app
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Terminal sharing limits
+const (
+	// DefaultShareBindAddress keeps shared terminals reachable from this machine only
+	DefaultShareBindAddress = "127.0.0.1"
+	// maxScrollbackBytes is how much recent output a terminal keeps for share viewers
+	maxScrollbackBytes = 256 * 1024
+	// maxShareInputBytes bounds a single message from a read-write viewer
+	maxShareInputBytes = 64 * 1024
+	// shareWriteTimeout drops viewers that stop reading
+	shareWriteTimeout = 10 * time.Second
+)
+
+// ShareServerConfig is where the terminal sharing server listens
+type ShareServerConfig struct {
+	BindAddress string `json:"bindAddress"` // IP address to listen on, e.g. "0.0.0.0" for all interfaces; empty means 127.0.0.1
+	Port        int    `json:"port"`        // 0 picks a free port
+}
+
+// ShareOptions controls what a share allows
+type ShareOptions struct {
+	ReadWrite         bool `json:"readWrite"`         // Viewers can type into the terminal
+	IncludeScrollback bool `json:"includeScrollback"` // Viewers first see the terminal's recent output
+}
+
+// TerminalShare is a terminal shared with browsers through a secret URL
+type TerminalShare struct {
+	ID                string `json:"id"`
+	SessionID         string `json:"sessionId"`
+	URL               string `json:"url"`
+	ReadWrite         bool   `json:"readWrite"`
+	IncludeScrollback bool   `json:"includeScrollback"`
+	Viewers           int    `json:"viewers"`
+	CreatedAt         string `json:"createdAt"`
+}
+
+// terminalShare is a share and its connected viewers
+type terminalShare struct {
+	info    TerminalShare // URL and Viewers are filled in when handed out
+	token   string
+	viewers int
+	revoked chan struct{}
+}
+
+// shareStore holds active shares and the server that serves them. The server
+// only runs while there are shares.
+var shareStore = struct {
+	mu       sync.Mutex
+	loaded   bool
+	config   ShareServerConfig
+	server   *http.Server
+	addr     *net.TCPAddr // Where the server listens
+	shares   map[string]*terminalShare
+	upgrader websocket.Upgrader
+	assets   fs.FS // Built frontend, for the viewer page and its scripts
+}{
+	shares: make(map[string]*terminalShare),
+}
+
+// SetShareViewerAssets sets the built frontend (frontend/dist) the sharing
+// server serves the viewer page (share.html) and its assets from
+func SetShareViewerAssets(assets fs.FS) {
+	shareStore.mu.Lock()
+	defer shareStore.mu.Unlock()
+	shareStore.assets = assets
+}
+
+// loadShareConfigLocked reads share-settings.json once. Caller must hold the lock.
+func loadShareConfigLocked() {
+	if shareStore.loaded {
+		return
+	}
+	shareStore.loaded = true
+	shareStore.config = ShareServerConfig{BindAddress: DefaultShareBindAddress}
+
+	configPath, err := getAppConfigPath("share-settings.json")
+	if err != nil {
+		log.Printf("⚠️ [Share] Failed to get settings path: %v", err)
+		return
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ [Share] Failed to read settings: %v", err)
+		}
+		return
+	}
+
+	var config ShareServerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Printf("⚠️ [Share] Failed to parse settings: %v", err)
+		return
+	}
+	if err := validateShareConfig(&config); err != nil {
+		log.Printf("⚠️ [Share] Ignoring invalid settings: %v", err)
+		return
+	}
+	shareStore.config = config
+}
+
+// validateShareConfig checks the listen address and fills in the default
+func validateShareConfig(config *ShareServerConfig) error {
+	if config.BindAddress == "" {
+		config.BindAddress = DefaultShareBindAddress
+	}
+	if net.ParseIP(config.BindAddress) == nil {
+		return fmt.Errorf("bind address must be an IP address: %s", config.BindAddress)
+	}
+	if config.Port < 0 || config.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", config.Port)
+	}
+	return nil
+}
+
+// startShareServerLocked starts the sharing server if it isn't running. Caller must hold the lock.
+func (a *App) startShareServerLocked() error {
+	if shareStore.server != nil {
+		return nil
+	}
+
+	addr := net.JoinHostPort(shareStore.config.BindAddress, strconv.Itoa(shareStore.config.Port))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+
+	server := &http.Server{Handler: a.shareHandler(), ReadHeaderTimeout: 10 * time.Second}
+
+	shareStore.server = server
+	shareStore.addr = listener.Addr().(*net.TCPAddr)
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("❌ [Share] Server error: %v", err)
+		}
+	}()
+
+	log.Printf("🔗 [Share] Server listening on %s", shareStore.addr)
+	return nil
+}
+
+// shareHandler routes viewer pages, their assets and sockets
+func (a *App) shareHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /share/{token}", a.handleShareViewerPage)
+	mux.HandleFunc("GET /share/{token}/ws", a.handleShareSocket)
+	mux.HandleFunc("GET /share/{token}/assets/{file}", handleShareAsset)
+	return mux
+}
+
+// stopShareServerLocked stops accepting viewers. Connected viewers are
+// disconnected by revoking their shares. Caller must hold the lock.
+func stopShareServerLocked() {
+	if shareStore.server == nil {
+		return
+	}
+	shareStore.server.Close()
+	shareStore.server = nil
+	shareStore.addr = nil
+	log.Printf("🔗 [Share] Server stopped")
+}
+
+// shareURLLocked returns the viewer URL for a token. A server listening on all
+// interfaces is advertised at this machine's first non-loopback address.
+// Caller must hold the lock.
+func shareURLLocked(token string) string {
+	if shareStore.addr == nil {
+		return ""
+	}
+	host := shareStore.addr.IP
+	if host.IsUnspecified() {
+		host = net.IPv4(127, 0, 0, 1)
+		if addrs, err := net.InterfaceAddrs(); err == nil {
+			for _, addr := range addrs {
+				if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.IsGlobalUnicast() && ipNet.IP.To4() != nil {
+					host = ipNet.IP
+					break
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("http://%s/share/%s", net.JoinHostPort(host.String(), strconv.Itoa(shareStore.addr.Port)), token)
+}
+
+// shareInfoLocked returns a share as handed out to the frontend. Caller must hold the lock.
+func shareInfoLocked(share *terminalShare) TerminalShare {
+	info := share.info
+	info.URL = shareURLLocked(share.token)
+	info.Viewers = share.viewers
+	return info
+}
+
+// findShareByTokenLocked returns the share for a URL token, or nil. Caller must hold the lock.
+func findShareByTokenLocked(token string) *terminalShare {
+	for _, share := range shareStore.shares {
+		if subtle.ConstantTimeCompare([]byte(share.token), []byte(token)) == 1 {
+			return share
+		}
+	}
+	return nil
+}
+
+// revokeShareLocked disconnects a share's viewers and invalidates its URL,
+// stopping the server after the last share. Caller must hold the lock.
+func (a *App) revokeShareLocked(share *terminalShare) {
+	delete(shareStore.shares, share.info.ID)
+	close(share.revoked)
+	if len(shareStore.shares) == 0 {
+		stopShareServerLocked()
+	}
+	log.Printf("🔗 [Share] Revoked share %s of terminal %s", share.info.ID, share.info.SessionID)
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "share:revoked", map[string]interface{}{
+			"shareId":   share.info.ID,
+			"sessionId": share.info.SessionID,
+		})
+	}
+}
+
+// revokeSessionShares revokes every share of a closed terminal
+func (a *App) revokeSessionShares(sessionID string) {
+	shareStore.mu.Lock()
+	defer shareStore.mu.Unlock()
+
+	for _, share := range shareStore.shares {
+		if share.info.SessionID == sessionID {
+			a.revokeShareLocked(share)
+		}
+	}
+}
+
+// GetShareServerConfig returns where the sharing server listens
+func (a *App) GetShareServerConfig() ShareServerConfig {
+	shareStore.mu.Lock()
+	defer shareStore.mu.Unlock()
+	loadShareConfigLocked()
+	return shareStore.config
+}
+
+// SetShareServerConfig saves the sharing server's listen address. A running
+// server moves to the new address; connected viewers stay connected, but
+// share URLs change.
+func (a *App) SetShareServerConfig(config ShareServerConfig) error {
+	if err := validateShareConfig(&config); err != nil {
+		return err
+	}
+
+	shareStore.mu.Lock()
+	defer shareStore.mu.Unlock()
+	loadShareConfigLocked()
+
+	configPath, err := getAppConfigPath("share-settings.json")
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal share settings: %v", err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write share settings: %v", err)
+	}
+	shareStore.config = config
+
+	if shareStore.server != nil {
+		stopShareServerLocked()
+		return a.startShareServerLocked()
+	}
+	return nil
+}
+
+// ShareTerminal shares a terminal through a new secret URL. Viewers count
+// changes arrive as share:viewers events.
+func (a *App) ShareTerminal(sessionID string, options ShareOptions) (TerminalShare, error) {
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+	if !exists {
+		return TerminalShare{}, fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+	if !termSession.isConnected.Load() {
+		return TerminalShare{}, fmt.Errorf("terminal session not connected")
+	}
+
+	tokenBytes := make([]byte, 24)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return TerminalShare{}, fmt.Errorf("failed to generate share token: %v", err)
+	}
+
+	shareStore.mu.Lock()
+	defer shareStore.mu.Unlock()
+	loadShareConfigLocked()
+
+	if err := a.startShareServerLocked(); err != nil {
+		return TerminalShare{}, err
+	}
+
+	share := &terminalShare{
+		info: TerminalShare{
+			ID:                fmt.Sprintf("share-%d", time.Now().UnixNano()),
+			SessionID:         sessionID,
+			ReadWrite:         options.ReadWrite,
+			IncludeScrollback: options.IncludeScrollback,
+			CreatedAt:         time.Now().Format(time.RFC3339),
+		},
+		token:   hex.EncodeToString(tokenBytes),
+		revoked: make(chan struct{}),
+	}
+	shareStore.shares[share.info.ID] = share
+
+	log.Printf("🔗 [Share] Shared terminal %s (read-write: %v)", sessionID, options.ReadWrite)
+	return shareInfoLocked(share), nil
+}
+
+// GetTerminalShares returns the active shares, oldest first
+func (a *App) GetTerminalShares() []TerminalShare {
+	shareStore.mu.Lock()
+	defer shareStore.mu.Unlock()
+
+	result := make([]TerminalShare, 0, len(shareStore.shares))
+	for _, share := range shareStore.shares {
+		result = append(result, shareInfoLocked(share))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// RevokeTerminalShare invalidates a share's URL and disconnects its viewers immediately
+func (a *App) RevokeTerminalShare(shareID string) error {
+	shareStore.mu.Lock()
+	defer shareStore.mu.Unlock()
+
+	share, exists := shareStore.shares[shareID]
+	if !exists {
+		return fmt.Errorf("terminal share not found: %s", shareID)
+	}
+	a.revokeShareLocked(share)
+	return nil
+}
+
+// updateShareViewers adjusts a share's viewer count and reports it
+func (a *App) updateShareViewers(share *terminalShare, delta int) {
+	shareStore.mu.Lock()
+	share.viewers += delta
+	viewers := share.viewers
+	shareStore.mu.Unlock()
+
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "share:viewers", map[string]interface{}{
+			"shareId":   share.info.ID,
+			"sessionId": share.info.SessionID,
+			"viewers":   viewers,
+		})
+	}
+}
+
+// lookupShare returns the share for a request's token and its terminal
+func lookupShare(r *http.Request) (*terminalShare, *TerminalSession) {
+	shareStore.mu.Lock()
+	share := findShareByTokenLocked(r.PathValue("token"))
+	shareStore.mu.Unlock()
+	if share == nil {
+		return nil, nil
+	}
+
+	termSessionMu.RLock()
+	termSession := terminalSessions[share.info.SessionID]
+	termSessionMu.RUnlock()
+	if termSession == nil {
+		return nil, nil
+	}
+	return share, termSession
+}
+
+// handleShareViewerPage serves the browser viewer of a shared terminal
+func (a *App) handleShareViewerPage(w http.ResponseWriter, r *http.Request) {
+	share, _ := lookupShare(r)
+	if share == nil {
+		http.NotFound(w, r)
+		return
+	}
+	shareStore.mu.Lock()
+	assets := shareStore.assets
+	shareStore.mu.Unlock()
+	if assets == nil {
+		http.Error(w, "The share viewer isn't available", http.StatusServiceUnavailable)
+		return
+	}
+	page, err := fs.ReadFile(assets, "share.html")
+	if err != nil {
+		log.Printf("⚠️ [Share] Viewer page unavailable: %v", err)
+		http.Error(w, "The share viewer isn't part of this build", http.StatusServiceUnavailable)
+		return
+	}
+	// Assets are only served under the share's own URL
+	page = bytes.ReplaceAll(page, []byte(`"/assets/`), []byte(`"/share/`+share.token+`/assets/`))
+	// The token is in the URL, so don't leak it; scripts only come from this server
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("Content-Security-Policy", "script-src 'self'")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
+
+// handleShareAsset serves the viewer page's bundled scripts and styles
+func handleShareAsset(w http.ResponseWriter, r *http.Request) {
+	if share, _ := lookupShare(r); share == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	shareStore.mu.Lock()
+	assets := shareStore.assets
+	shareStore.mu.Unlock()
+	if assets == nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFileFS(w, r, assets, "assets/"+r.PathValue("file"))
+}
+
+// handleShareSocket streams a shared terminal to a viewer. Messages use
+// TerminalMessage: the server sends "mode", "resize", "output" and "closed";
+// read-write viewers send "input".
+func (a *App) handleShareSocket(w http.ResponseWriter, r *http.Request) {
+	share, termSession := lookupShare(r)
+	if share == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	conn, err := shareStore.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("⚠️ [Share] WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	log.Printf("👀 [Share] Viewer %s connected to share %s", r.RemoteAddr, share.info.ID)
+	a.updateShareViewers(share, 1)
+	defer a.updateShareViewers(share, -1)
+
+	scrollback, output, behind, unsubscribe := termSession.subscribeOutputWithScrollback(256)
+	defer unsubscribe()
+
+	// Read-only viewers are still read from, to notice when they leave
+	viewerGone := make(chan struct{})
+	go func() {
+		defer close(viewerGone)
+		conn.SetReadLimit(maxShareInputBytes)
+		for {
+			var msg TerminalMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Type == "input" && share.info.ReadWrite {
+				if err := writeToTerminalSession(share.info.SessionID, msg.Data); err != nil {
+					log.Printf("⚠️ [Share] Failed to write viewer input: %v", err)
+				}
+			}
+		}
+	}()
+
+	send := func(msg TerminalMessage) bool {
+		conn.SetWriteDeadline(time.Now().Add(shareWriteTimeout))
+		return conn.WriteJSON(msg) == nil
+	}
+
+	mode := "read-only"
+	if share.info.ReadWrite {
+		mode = "read-write"
+	}
+	rows, cols := termSession.size()
+	if !send(TerminalMessage{Type: "mode", Data: mode}) || !send(TerminalMessage{Type: "resize", Rows: rows, Cols: cols}) {
+		return
+	}
+	if share.info.IncludeScrollback && scrollback != "" && !send(TerminalMessage{Type: "output", Data: scrollback}) {
+		return
+	}
+
+	for {
+		select {
+		case data := <-output:
+			if newRows, newCols := termSession.size(); newRows != rows || newCols != cols {
+				rows, cols = newRows, newCols
+				if !send(TerminalMessage{Type: "resize", Rows: rows, Cols: cols}) {
+					return
+				}
+			}
+			if !send(TerminalMessage{Type: "output", Data: data}) {
+				return
+			}
+		case <-behind:
+			// Its screen is missing output now; a reload starts over from the scrollback
+			log.Printf("⚠️ [Share] Viewer %s of share %s fell behind, disconnecting", r.RemoteAddr, share.info.ID)
+			send(TerminalMessage{Type: "closed", Data: "Output was missed because the connection is too slow. Reload the page to resync."})
+			return
+		case <-share.revoked:
+			send(TerminalMessage{Type: "closed", Data: "The share was revoked"})
+			return
+		case <-termSession.stopChan:
+			send(TerminalMessage{Type: "closed", Data: "The terminal was closed"})
+			return
+		case <-viewerGone:
+			log.Printf("👀 [Share] Viewer %s left share %s", r.RemoteAddr, share.info.ID)
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestShareViewerServesBundledAssets(t *testing.T) {
+	termSessionMu.Lock()
+	terminalSessions["share-test"] = &TerminalSession{SessionID: "share-test"}
+	termSessionMu.Unlock()
+	shareStore.mu.Lock()
+	savedAssets := shareStore.assets
+	shareStore.shares["share-test"] = &terminalShare{info: TerminalShare{ID: "share-test", SessionID: "share-test"}, token: "secret"}
+	shareStore.mu.Unlock()
+	t.Cleanup(func() {
+		termSessionMu.Lock()
+		delete(terminalSessions, "share-test")
+		termSessionMu.Unlock()
+		shareStore.mu.Lock()
+		delete(shareStore.shares, "share-test")
+		shareStore.assets = savedAssets
+		shareStore.mu.Unlock()
+	})
+
+	SetShareViewerAssets(fstest.MapFS{
+		"share.html":           {Data: []byte(`<script type="module" src="/assets/share-1a2b.js"></script>`)},
+		"assets/share-1a2b.js": {Data: []byte("new Terminal()")},
+	})
+	handler := (&App{}).shareHandler()
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		return recorder
+	}
+
+	page := get("/share/secret")
+	if page.Code != http.StatusOK || !strings.Contains(page.Body.String(), "/assets/share-1a2b.js") {
+		t.Fatalf("Unexpected viewer page: %d %q", page.Code, page.Body.String())
+	}
+	if csp := page.Header().Get("Content-Security-Policy"); csp != "script-src 'self'" {
+		t.Errorf("Expected scripts to be limited to the share server, got %q", csp)
+	}
+	if !strings.Contains(page.Body.String(), `"/share/secret/assets/share-1a2b.js"`) {
+		t.Errorf("Expected asset URLs under the share's URL, got %q", page.Body.String())
+	}
+	if script := get("/share/secret/assets/share-1a2b.js"); script.Code != http.StatusOK || script.Body.String() != "new Terminal()" {
+		t.Errorf("Unexpected script: %d %q", script.Code, script.Body.String())
+	}
+	for _, path := range []string{"/assets/share-1a2b.js", "/share/guess/assets/share-1a2b.js"} {
+		if script := get(path); script.Code != http.StatusNotFound {
+			t.Errorf("Expected %s to need the share token, got %d", path, script.Code)
+		}
+	}
+	if wrong := get("/share/guess"); wrong.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown token to be rejected, got %d", wrong.Code)
+	}
+}
+
+func TestOutputSubscriberFallsBehind(t *testing.T) {
+	ts := &TerminalSession{SessionID: "behind-test"}
+	_, output, behind, unsubscribe := ts.subscribeOutputWithScrollback(1)
+	defer unsubscribe()
+
+	ts.publishOutput("one")
+	select {
+	case <-behind:
+		t.Fatal("Expected no drop while the subscriber keeps up")
+	default:
+	}
+	ts.publishOutput("two")
+	ts.publishOutput("three")
+	select {
+	case <-behind:
+	default:
+		t.Fatal("Expected the subscriber to be told it missed output")
+	}
+	if got := <-output; got != "one" {
+		t.Errorf("Expected the buffered chunk to stay, got %q", got)
+	}
+}
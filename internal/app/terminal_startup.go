@@ -0,0 +1,223 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Startup command timing
+const (
+	// StartupWaitTimeout is how long a startup command waits for its prompt
+	// regex when it doesn't set its own timeout
+	StartupWaitTimeout = 30 * time.Second
+	// StartupSettleDelay is how long the shell must stay quiet after printing
+	// something before a startup command without a prompt regex is sent
+	StartupSettleDelay = 500 * time.Millisecond
+	// StartupSettleTimeout caps the wait for the shell to settle
+	StartupSettleTimeout = 10 * time.Second
+	// maxWatchedOutput bounds the output kept for prompt matching
+	maxWatchedOutput = 16 * 1024
+)
+
+// StartupCommand is typed into a terminal after it connects
+type StartupCommand struct {
+	Command        string `json:"command"`
+	WaitFor        string `json:"waitFor"`        // Regex the output must match before sending, e.g. `\$ $`; empty waits for the shell to settle
+	DelayMs        int    `json:"delayMs"`        // Pause before sending, after any wait
+	TimeoutSeconds int    `json:"timeoutSeconds"` // Limit on WaitFor, 0 means 30s
+}
+
+// validateStartupCommands checks commands and compiles their prompt regexes
+func validateStartupCommands(commands []StartupCommand) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, len(commands))
+	for i, c := range commands {
+		if strings.TrimSpace(c.Command) == "" {
+			return nil, fmt.Errorf("startup command #%d is empty", i+1)
+		}
+		if c.DelayMs < 0 || c.TimeoutSeconds < 0 {
+			return nil, fmt.Errorf("startup command #%d: delay and timeout cannot be negative", i+1)
+		}
+		if c.WaitFor != "" {
+			re, err := regexp.Compile(c.WaitFor)
+			if err != nil {
+				return nil, fmt.Errorf("startup command #%d: invalid prompt regex: %v", i+1, err)
+			}
+			patterns[i] = re
+		}
+	}
+	return patterns, nil
+}
+
+// outputWatcher accumulates a terminal's output, without escape sequences,
+// for prompt matching by startup commands and automation scripts
+type outputWatcher struct {
+	output <-chan string
+	stop   <-chan struct{}
+	text   string
+}
+
+// append adds a chunk of output, keeping the last maxWatchedOutput bytes
+func (w *outputWatcher) append(chunk string) {
+	w.text += stripANSI(chunk)
+	if len(w.text) > maxWatchedOutput {
+		w.text = w.text[len(w.text)-maxWatchedOutput:]
+	}
+}
+
+// next waits for more output. Returns false if stop closed or the deadline passed.
+func (w *outputWatcher) next(deadline <-chan time.Time) bool {
+	select {
+	case chunk := <-w.output:
+		w.append(chunk)
+		return true
+	case <-w.stop:
+		return false
+	case <-deadline:
+		return false
+	}
+}
+
+// waitFor waits until the buffered output matches one of the patterns and
+// discards it up to the end of the earliest match. Returns the index of the
+// pattern that matched, or -1 on timeout or stop.
+func (w *outputWatcher) waitFor(patterns []*regexp.Regexp, timeout time.Duration) int {
+	deadline := time.After(timeout)
+	for {
+		best, bestStart, bestEnd := -1, 0, 0
+		for i, re := range patterns {
+			if loc := re.FindStringIndex(w.text); loc != nil && (best < 0 || loc[0] < bestStart) {
+				best, bestStart, bestEnd = i, loc[0], loc[1]
+			}
+		}
+		if best >= 0 {
+			w.text = w.text[bestEnd:]
+			return best
+		}
+		if !w.next(deadline) {
+			return -1
+		}
+	}
+}
+
+// settle waits for the first output, then for StartupSettleDelay of silence
+func (w *outputWatcher) settle() bool {
+	deadline := time.After(StartupSettleTimeout)
+	for w.text == "" {
+		if !w.next(deadline) {
+			return false
+		}
+	}
+	for {
+		select {
+		case chunk := <-w.output:
+			w.append(chunk)
+		case <-time.After(StartupSettleDelay):
+			return true
+		case <-w.stop:
+			return false
+		case <-deadline:
+			return true
+		}
+	}
+}
+
+// runStartupCommands types a host's or profile's startup commands into a new
+// terminal. It subscribes to the output before returning, so it must be called
+// before the output readers start. Progress is reported as terminal:startup events.
+func (a *App) runStartupCommands(termSession *TerminalSession, commands []StartupCommand) {
+	if len(commands) == 0 {
+		return
+	}
+	patterns, err := validateStartupCommands(commands)
+	if err != nil {
+		log.Printf("⚠️ [Startup] Skipping startup commands for %s: %v", termSession.SessionID, err)
+		return
+	}
+
+	output, unsubscribe := termSession.subscribeOutput(256)
+	go func() {
+		defer unsubscribe()
+		w := &outputWatcher{output: output, stop: termSession.stopChan}
+		report := func(i int, status string, err error) {
+			switch status {
+			case "timeout":
+				log.Printf("⚠️ [Startup] Terminal %s: gave up waiting before %q", termSession.SessionID, commands[i].Command)
+			case "error":
+				log.Printf("⚠️ [Startup] Terminal %s: failed to send %q: %v", termSession.SessionID, commands[i].Command, err)
+			}
+			a.emitStartupStatus(termSession, i, commands[i].Command, status, err)
+		}
+		if sendStartupCommands(w, commands, patterns, termSession.writeInput, report) {
+			log.Printf("✅ [Startup] Sent %d startup command(s) to terminal %s", len(commands), termSession.SessionID)
+		}
+	}()
+}
+
+// skipStartupCommands reports every startup command as skipped, so the user
+// knows why a terminal came up without them
+func (a *App) skipStartupCommands(termSession *TerminalSession, commands []StartupCommand, reason error) {
+	log.Printf("ℹ️ [Startup] Skipping %d startup command(s) for terminal %s: %v", len(commands), termSession.SessionID, reason)
+	for i, c := range commands {
+		a.emitStartupStatus(termSession, i, c.Command, "skipped", reason)
+	}
+}
+
+// sendStartupCommands types each command with send once its prompt regex
+// matches, or once the shell settles if it has none, and reports its status.
+// Returns false if it stopped early.
+func sendStartupCommands(w *outputWatcher, commands []StartupCommand, patterns []*regexp.Regexp, send func(string) error, report func(index int, status string, err error)) bool {
+	for i, c := range commands {
+		var ok bool
+		if patterns[i] != nil {
+			timeout := StartupWaitTimeout
+			if c.TimeoutSeconds > 0 {
+				timeout = time.Duration(c.TimeoutSeconds) * time.Second
+			}
+			ok = w.waitFor(patterns[i:i+1], timeout) >= 0
+		} else {
+			ok = w.settle()
+		}
+		if !ok {
+			report(i, "timeout", nil)
+			return false
+		}
+
+		if c.DelayMs > 0 {
+			select {
+			case <-time.After(time.Duration(c.DelayMs) * time.Millisecond):
+			case <-w.stop:
+				return false
+			}
+		}
+
+		w.text = ""
+		if err := send(c.Command + "\r"); err != nil {
+			report(i, "error", err)
+			return false
+		}
+		report(i, "sent", nil)
+	}
+	return true
+}
+
+// emitStartupStatus reports the progress of a terminal's startup commands
+func (a *App) emitStartupStatus(termSession *TerminalSession, index int, command string, status string, err error) {
+	if a.ctx == nil {
+		return
+	}
+	event := map[string]interface{}{
+		"sessionId": termSession.SessionID,
+		"index":     index,
+		"command":   command,
+		"status":    status, // "sent", "timeout", "error" or "skipped"
+	}
+	if err != nil {
+		event["error"] = err.Error()
+	}
+	wailsRuntime.EventsEmit(a.ctx, "terminal:startup", event)
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSendStartupCommandsSettlesBeforeEach(t *testing.T) {
+	commands := []StartupCommand{{Command: "cd /srv"}, {Command: "source venv/bin/activate", DelayMs: 10}, {Command: "ls", WaitFor: `\(venv\) .*\$ $`}}
+	patterns, err := validateStartupCommands(commands)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := make(chan string, 4)
+	output <- "user@host:~$ "
+	w := &outputWatcher{output: output, stop: make(chan struct{})}
+
+	replies := map[string]string{
+		"cd /srv\r":                  "cd /srv\r\nuser@host:/srv$ ",
+		"source venv/bin/activate\r": "source venv/bin/activate\r\n(venv) user@host:/srv$ ",
+	}
+	var sent []string
+	send := func(text string) error {
+		// Every command must wait for the output of the one before it
+		if len(output) > 0 {
+			t.Errorf("%q sent before the earlier output was read", text)
+		}
+		sent = append(sent, text)
+		if reply, ok := replies[text]; ok {
+			output <- reply
+		}
+		return nil
+	}
+	var statuses []string
+	report := func(i int, status string, err error) { statuses = append(statuses, status) }
+
+	if !sendStartupCommands(w, commands, patterns, send, report) {
+		t.Fatalf("Expected all commands to be sent, got %v", statuses)
+	}
+	if got := strings.Join(sent, "|"); got != "cd /srv\r|source venv/bin/activate\r|ls\r" {
+		t.Errorf("Unexpected input: %q", got)
+	}
+}
@@ -0,0 +1,559 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Trigger constants
+const (
+	// MaxTriggerLineLength caps how much of an unterminated line is buffered
+	// for matching (e.g. a progress bar redrawn with \r)
+	MaxTriggerLineLength = 4 * 1024
+	// TriggerSaveDelay batches counter writes when a trigger matches a lot of lines
+	TriggerSaveDelay = 2 * time.Second
+)
+
+// Trigger actions
+const (
+	TriggerActionNotify    = "notify"    // frontend shows a notification
+	TriggerActionHighlight = "highlight" // frontend highlights the match in the terminal
+	TriggerActionRespond   = "respond"   // Response is typed into the terminal
+	TriggerActionRecord    = "record"    // the terminal starts recording
+)
+
+// TriggerRule watches terminal output for a regular expression.
+// Rules with a SessionID only live as long as that terminal and aren't saved;
+// all other rules are persisted to triggers.json along with their counters.
+type TriggerRule struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Pattern         string   `json:"pattern"` // Go regexp, matched against each output line without escape sequences
+	CaseInsensitive bool     `json:"caseInsensitive"`
+	SessionID       string   `json:"sessionId"` // empty applies to all terminals
+	Host            string   `json:"host"`      // SSH host alias or "local", empty applies to all hosts
+	Actions         []string `json:"actions"`
+	Response        string   `json:"response"`        // text sent by the respond action
+	CooldownSeconds int      `json:"cooldownSeconds"` // minimum time between actions per terminal
+	Enabled         bool     `json:"enabled"`
+	MatchCount      int64    `json:"matchCount"`
+	LastMatchAt     string   `json:"lastMatchAt"`
+}
+
+// triggerLineBuffer splits a terminal's output into lines for matching.
+// Guarded by TerminalSession.shellMu.
+type triggerLineBuffer struct {
+	partial string          // text after the last newline
+	fired   map[string]bool // rules that already matched the partial line
+	escape  string          // unfinished escape sequence at the end of the last chunk
+}
+
+// triggerLine is a line of output to match triggers against
+type triggerLine struct {
+	text     string
+	complete bool // false for the partial line at the end of the chunk
+}
+
+// stripEscapes removes escape sequences from a chunk of output. A sequence
+// split across reads is held back until the rest arrives, so its tail never
+// reaches the text the patterns match against.
+func (b *triggerLineBuffer) stripEscapes(output string) string {
+	output = b.escape + output
+	end := incompleteEscapeStart(output)
+	b.escape = output[end:]
+	if len(b.escape) > MaxOSCLength {
+		b.escape = "" // A runaway sequence; there is nothing in it to match
+	}
+	return stripANSI(output[:end])
+}
+
+// incompleteEscapeStart returns where an escape sequence left unfinished at
+// the end of s begins, or len(s) if every sequence in s is complete
+func incompleteEscapeStart(s string) int {
+	for i := strings.IndexByte(s, '\x1b'); i >= 0 && i < len(s); i++ {
+		if s[i] != '\x1b' {
+			continue
+		}
+		if i+1 >= len(s) {
+			return i
+		}
+		switch s[i+1] {
+		case '[':
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			if j >= len(s) {
+				return i
+			}
+			i = j
+		case ']':
+			end, termLen := findOSCTerminator(s[i+2:])
+			if end < 0 {
+				return i
+			}
+			i += 2 + end + termLen - 1
+		case '(', ')', '*', '+':
+			if i+2 >= len(s) {
+				return i
+			}
+			i += 2
+		default:
+			i++
+		}
+	}
+	return len(s)
+}
+
+// feed adds output text and returns the complete lines plus the current
+// partial line. A prompt like "Password: " is matched before Enter is pressed.
+func (b *triggerLineBuffer) feed(text string) []triggerLine {
+	var lines []triggerLine
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, triggerLine{text: strings.TrimRight(b.partial+text[:i], "\r"), complete: true})
+		b.partial = ""
+		text = text[i+1:]
+	}
+	b.partial += text
+	if len(b.partial) > MaxTriggerLineLength {
+		b.partial = b.partial[len(b.partial)-MaxTriggerLineLength:]
+	}
+	if text != "" {
+		lines = append(lines, triggerLine{text: b.partial})
+	}
+	return lines
+}
+
+// shouldFire reports whether a rule that matched line should fire. A rule
+// fires at most once per line, even when it matched the line while partial.
+func (b *triggerLineBuffer) shouldFire(ruleID string, line triggerLine) bool {
+	if b.fired[ruleID] {
+		return false
+	}
+	if !line.complete {
+		if b.fired == nil {
+			b.fired = make(map[string]bool)
+		}
+		b.fired[ruleID] = true
+	}
+	return true
+}
+
+// lineDone resets per-line state after a complete line was matched
+func (b *triggerLineBuffer) lineDone() {
+	b.fired = nil
+}
+
+// triggerStore holds trigger rules in memory, backed by triggers.json
+var triggerStore = struct {
+	mu        sync.Mutex
+	loaded    bool
+	rules     []*TriggerRule
+	compiled  map[string]*regexp.Regexp
+	lastFired map[string]time.Time // keyed by rule ID + session ID
+	saveTimer *time.Timer
+}{
+	compiled:  make(map[string]*regexp.Regexp),
+	lastFired: make(map[string]time.Time),
+}
+
+// compileTriggerPattern compiles a rule's pattern
+func compileTriggerPattern(rule *TriggerRule) (*regexp.Regexp, error) {
+	pattern := rule.Pattern
+	if rule.CaseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trigger pattern: %v", err)
+	}
+	return re, nil
+}
+
+// loadTriggersLocked reads triggers.json once. Caller must hold the lock.
+func loadTriggersLocked() {
+	if triggerStore.loaded {
+		return
+	}
+	triggerStore.loaded = true
+
+	triggersPath, err := getAppConfigPath("triggers.json")
+	if err != nil {
+		log.Printf("⚠️ [Triggers] Failed to get triggers path: %v", err)
+		return
+	}
+
+	data, err := os.ReadFile(triggersPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ [Triggers] Failed to read triggers: %v", err)
+		}
+		return
+	}
+
+	var rules []*TriggerRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		log.Printf("⚠️ [Triggers] Failed to parse triggers: %v", err)
+		return
+	}
+
+	for _, rule := range rules {
+		re, err := compileTriggerPattern(rule)
+		if err != nil {
+			log.Printf("⚠️ [Triggers] Skipping rule %s: %v", rule.ID, err)
+			continue
+		}
+		triggerStore.compiled[rule.ID] = re
+		triggerStore.rules = append(triggerStore.rules, rule)
+	}
+}
+
+// saveTriggersLocked writes persistent rules to triggers.json. Caller must hold the lock.
+func saveTriggersLocked() error {
+	rules := []*TriggerRule{}
+	for _, rule := range triggerStore.rules {
+		if rule.SessionID == "" {
+			rules = append(rules, rule)
+		}
+	}
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal triggers: %v", err)
+	}
+	triggersPath, err := getAppConfigPath("triggers.json")
+	if err != nil {
+		return err
+	}
+	// Responses can answer password prompts, so only the user may read
+	// the file, including one saved readable by everyone before
+	if err := os.Chmod(triggersPath, 0600); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to restrict triggers: %v", err)
+	}
+	if err := os.WriteFile(triggersPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write triggers: %v", err)
+	}
+	return nil
+}
+
+// scheduleTriggerSaveLocked saves the rules after TriggerSaveDelay, so match
+// counters don't write the file on every hit. Caller must hold the lock.
+func scheduleTriggerSaveLocked() {
+	if triggerStore.saveTimer != nil {
+		return
+	}
+	triggerStore.saveTimer = time.AfterFunc(TriggerSaveDelay, func() {
+		triggerStore.mu.Lock()
+		defer triggerStore.mu.Unlock()
+		triggerStore.saveTimer = nil
+		if err := saveTriggersLocked(); err != nil {
+			log.Printf("⚠️ [Triggers] Failed to save triggers: %v", err)
+		}
+	})
+}
+
+// flushTriggers writes match counters still waiting on a scheduled save
+func flushTriggers() {
+	triggerStore.mu.Lock()
+	defer triggerStore.mu.Unlock()
+	if triggerStore.saveTimer == nil {
+		return
+	}
+	triggerStore.saveTimer.Stop()
+	triggerStore.saveTimer = nil
+	if err := saveTriggersLocked(); err != nil {
+		log.Printf("⚠️ [Triggers] Failed to save triggers: %v", err)
+	}
+}
+
+// activeTrigger is an enabled rule that applies to a terminal
+type activeTrigger struct {
+	rule TriggerRule
+	re   *regexp.Regexp
+}
+
+// activeTriggers returns the enabled rules that apply to a terminal session
+func activeTriggers(termSession *TerminalSession) []activeTrigger {
+	triggerStore.mu.Lock()
+	defer triggerStore.mu.Unlock()
+	loadTriggersLocked()
+
+	var result []activeTrigger
+	for _, rule := range triggerStore.rules {
+		if !rule.Enabled {
+			continue
+		}
+		if rule.SessionID != "" && rule.SessionID != termSession.SessionID {
+			continue
+		}
+		if rule.Host != "" && rule.Host != termSession.host {
+			continue
+		}
+		result = append(result, activeTrigger{rule: *rule, re: triggerStore.compiled[rule.ID]})
+	}
+	return result
+}
+
+// triggerMatch is a rule that matched a line of output
+type triggerMatch struct {
+	rule  TriggerRule
+	line  string
+	match string
+}
+
+// handleTriggers matches a chunk of terminal output against the trigger rules
+// and runs the actions of those that match. Called from the output readers.
+func (a *App) handleTriggers(termSession *TerminalSession, output string) {
+	triggers := activeTriggers(termSession)
+	if len(triggers) == 0 {
+		return
+	}
+
+	var matches []triggerMatch
+	termSession.shellMu.Lock()
+	buffer := &termSession.triggerLines
+	for _, line := range buffer.feed(buffer.stripEscapes(output)) {
+		for _, t := range triggers {
+			if m := t.re.FindString(line.text); m != "" && buffer.shouldFire(t.rule.ID, line) {
+				matches = append(matches, triggerMatch{rule: t.rule, line: line.text, match: m})
+			}
+		}
+		if line.complete {
+			buffer.lineDone()
+		}
+	}
+	termSession.shellMu.Unlock()
+
+	for _, m := range matches {
+		a.fireTrigger(termSession, m)
+	}
+}
+
+// fireTrigger counts a match and runs the rule's actions unless it is cooling down
+func (a *App) fireTrigger(termSession *TerminalSession, m triggerMatch) {
+	now := time.Now()
+	cooldownKey := m.rule.ID + "\x00" + termSession.SessionID
+
+	triggerStore.mu.Lock()
+	for _, rule := range triggerStore.rules {
+		if rule.ID == m.rule.ID {
+			rule.MatchCount++
+			rule.LastMatchAt = now.Format(time.RFC3339)
+			m.rule.MatchCount = rule.MatchCount
+			break
+		}
+	}
+	cooling := m.rule.CooldownSeconds > 0 &&
+		now.Sub(triggerStore.lastFired[cooldownKey]) < time.Duration(m.rule.CooldownSeconds)*time.Second
+	if !cooling {
+		triggerStore.lastFired[cooldownKey] = now
+	}
+	scheduleTriggerSaveLocked()
+	triggerStore.mu.Unlock()
+
+	if cooling {
+		return
+	}
+
+	log.Printf("🔔 [Triggers] Rule %q matched in terminal %s: %s", m.rule.Name, termSession.SessionID, m.match)
+
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "terminal:trigger", map[string]interface{}{
+			"ruleId":     m.rule.ID,
+			"name":       m.rule.Name,
+			"sessionId":  termSession.SessionID,
+			"host":       termSession.host,
+			"actions":    m.rule.Actions,
+			"line":       m.line,
+			"match":      m.match,
+			"matchCount": m.rule.MatchCount,
+		})
+	}
+
+	// Actions that touch the terminal run asynchronously so the output reader
+	// (the caller) never waits on terminal input or file creation
+	for _, action := range m.rule.Actions {
+		switch action {
+		case TriggerActionRespond:
+			go func() {
+				if err := termSession.writeInput(m.rule.Response); err != nil {
+					log.Printf("⚠️ [Triggers] Failed to send response: %v", err)
+				}
+			}()
+		case TriggerActionRecord:
+			go func() {
+				if _, err := a.StartTerminalRecording(termSession.SessionID); err != nil {
+					log.Printf("⚠️ [Triggers] Failed to start recording: %v", err)
+				}
+			}()
+		}
+	}
+}
+
+// validateTriggerRule checks a rule's pattern and actions and returns its compiled pattern
+func validateTriggerRule(rule *TriggerRule) (*regexp.Regexp, error) {
+	if rule.Pattern == "" {
+		return nil, fmt.Errorf("trigger pattern is required")
+	}
+	re, err := compileTriggerPattern(rule)
+	if err != nil {
+		return nil, err
+	}
+	if re.MatchString("") {
+		return nil, fmt.Errorf("trigger pattern matches empty text")
+	}
+	if len(rule.Actions) == 0 {
+		return nil, fmt.Errorf("trigger needs at least one action")
+	}
+	for _, action := range rule.Actions {
+		switch action {
+		case TriggerActionNotify, TriggerActionHighlight, TriggerActionRecord:
+		case TriggerActionRespond:
+			if rule.Response == "" {
+				return nil, fmt.Errorf("respond action needs a response")
+			}
+		default:
+			return nil, fmt.Errorf("unknown trigger action: %s", action)
+		}
+	}
+	if rule.CooldownSeconds < 0 {
+		return nil, fmt.Errorf("cooldown cannot be negative")
+	}
+	return re, nil
+}
+
+// GetTriggerRules returns all trigger rules
+func (a *App) GetTriggerRules() []TriggerRule {
+	triggerStore.mu.Lock()
+	defer triggerStore.mu.Unlock()
+	loadTriggersLocked()
+
+	result := make([]TriggerRule, 0, len(triggerStore.rules))
+	for _, rule := range triggerStore.rules {
+		result = append(result, *rule)
+	}
+	return result
+}
+
+// AddTriggerRule validates and adds a trigger rule, returning it with its ID assigned
+func (a *App) AddTriggerRule(rule TriggerRule) (TriggerRule, error) {
+	re, err := validateTriggerRule(&rule)
+	if err != nil {
+		return TriggerRule{}, err
+	}
+
+	rule.ID = fmt.Sprintf("trigger-%d", time.Now().UnixNano())
+	rule.MatchCount = 0
+	rule.LastMatchAt = ""
+	if rule.Name == "" {
+		rule.Name = rule.Pattern
+	}
+
+	triggerStore.mu.Lock()
+	defer triggerStore.mu.Unlock()
+	loadTriggersLocked()
+
+	triggerStore.rules = append(triggerStore.rules, &rule)
+	triggerStore.compiled[rule.ID] = re
+	if err := saveTriggersLocked(); err != nil {
+		return TriggerRule{}, err
+	}
+
+	log.Printf("🔔 [Triggers] Added rule %q (%s)", rule.Name, rule.Pattern)
+	return rule, nil
+}
+
+// UpdateTriggerRule replaces a trigger rule's settings, keeping its match counters
+func (a *App) UpdateTriggerRule(rule TriggerRule) error {
+	re, err := validateTriggerRule(&rule)
+	if err != nil {
+		return err
+	}
+
+	triggerStore.mu.Lock()
+	defer triggerStore.mu.Unlock()
+	loadTriggersLocked()
+
+	for i, existing := range triggerStore.rules {
+		if existing.ID == rule.ID {
+			rule.MatchCount = existing.MatchCount
+			rule.LastMatchAt = existing.LastMatchAt
+			if rule.Name == "" {
+				rule.Name = rule.Pattern
+			}
+			triggerStore.rules[i] = &rule
+			triggerStore.compiled[rule.ID] = re
+			return saveTriggersLocked()
+		}
+	}
+	return fmt.Errorf("trigger rule not found: %s", rule.ID)
+}
+
+// DeleteTriggerRule removes a trigger rule
+func (a *App) DeleteTriggerRule(ruleID string) error {
+	triggerStore.mu.Lock()
+	defer triggerStore.mu.Unlock()
+	loadTriggersLocked()
+
+	for i, rule := range triggerStore.rules {
+		if rule.ID == ruleID {
+			triggerStore.rules = append(triggerStore.rules[:i], triggerStore.rules[i+1:]...)
+			delete(triggerStore.compiled, ruleID)
+			return saveTriggersLocked()
+		}
+	}
+	return fmt.Errorf("trigger rule not found: %s", ruleID)
+}
+
+// ResetTriggerCounters zeroes a rule's match counter, or every rule's if ruleID is empty
+func (a *App) ResetTriggerCounters(ruleID string) error {
+	triggerStore.mu.Lock()
+	defer triggerStore.mu.Unlock()
+	loadTriggersLocked()
+
+	found := false
+	for _, rule := range triggerStore.rules {
+		if ruleID == "" || rule.ID == ruleID {
+			rule.MatchCount = 0
+			rule.LastMatchAt = ""
+			found = true
+		}
+	}
+	if !found && ruleID != "" {
+		return fmt.Errorf("trigger rule not found: %s", ruleID)
+	}
+	return saveTriggersLocked()
+}
+
+// removeSessionTriggers drops the rules scoped to a closed terminal
+func removeSessionTriggers(sessionID string) {
+	triggerStore.mu.Lock()
+	defer triggerStore.mu.Unlock()
+
+	kept := triggerStore.rules[:0]
+	for _, rule := range triggerStore.rules {
+		if rule.SessionID == sessionID {
+			delete(triggerStore.compiled, rule.ID)
+			continue
+		}
+		kept = append(kept, rule)
+	}
+	triggerStore.rules = kept
+
+	for key := range triggerStore.lastFired {
+		if strings.HasSuffix(key, "\x00"+sessionID) {
+			delete(triggerStore.lastFired, key)
+		}
+	}
+}
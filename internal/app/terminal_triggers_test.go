@@ -0,0 +1,88 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTriggerLineBuffer(t *testing.T) {
+	b := &triggerLineBuffer{}
+
+	lines := b.feed("deploy started\r\nPass")
+	if len(lines) != 2 || lines[0].text != "deploy started" || !lines[0].complete ||
+		lines[1].text != "Pass" || lines[1].complete {
+		t.Fatalf("Unexpected lines: %+v", lines)
+	}
+
+	// The partial line grows until its newline arrives
+	lines = b.feed("word: ")
+	if len(lines) != 1 || lines[0].text != "Password: " || lines[0].complete {
+		t.Fatalf("Unexpected lines: %+v", lines)
+	}
+	if !b.shouldFire("rule", lines[0]) {
+		t.Errorf("Expected first match on the partial line to fire")
+	}
+
+	lines = b.feed("\n")
+	if len(lines) != 1 || lines[0].text != "Password: " || !lines[0].complete {
+		t.Fatalf("Unexpected lines: %+v", lines)
+	}
+	if b.shouldFire("rule", lines[0]) {
+		t.Errorf("Expected rule not to fire twice for the same line")
+	}
+	b.lineDone()
+
+	lines = b.feed("Password: \n")
+	if len(lines) != 1 || !b.shouldFire("rule", lines[0]) {
+		t.Errorf("Expected rule to fire again on a new line")
+	}
+}
+
+func TestTriggerLineBufferSplitEscape(t *testing.T) {
+	b := &triggerLineBuffer{}
+
+	// A color sequence split between reads, then a title set split the same way
+	text := b.stripEscapes("ok\x1b[3")
+	text += b.stripEscapes("2mPass\x1b]0;ti")
+	text += b.stripEscapes("tle\x07word: ")
+	if text != "okPassword: " {
+		t.Errorf("Expected escape tails to be held back, got %q", text)
+	}
+	if b.escape != "" {
+		t.Errorf("Expected nothing held after complete sequences, got %q", b.escape)
+	}
+}
+
+func TestTriggerRuleEditsSaveImmediately(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	triggerStore.mu.Lock()
+	triggerStore.loaded, triggerStore.rules = true, nil
+	triggerStore.mu.Unlock()
+	t.Cleanup(func() {
+		triggerStore.mu.Lock()
+		triggerStore.rules = nil
+		triggerStore.mu.Unlock()
+	})
+
+	a := &App{}
+	rule, err := a.AddTriggerRule(TriggerRule{Pattern: "[Pp]assword: $", Actions: []string{TriggerActionRespond}, Response: "hunter2", Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	triggersPath, _ := getAppConfigPath("triggers.json")
+	data, err := os.ReadFile(triggersPath)
+	if err != nil || !strings.Contains(string(data), rule.ID) {
+		t.Fatalf("Expected the rule on disk without waiting, got %q (%v)", data, err)
+	}
+	if info, err := os.Stat(triggersPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected triggers.json to be readable only by the user (%v)", err)
+	}
+
+	if err := a.DeleteTriggerRule(rule.ID); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(triggersPath); strings.Contains(string(data), rule.ID) {
+		t.Errorf("Expected the deleted rule to be gone from disk, got %q", data)
+	}
+}
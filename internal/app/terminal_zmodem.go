@@ -0,0 +1,310 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ZmodemIdleTimeout cancels a transfer when the remote side stops responding
+const ZmodemIdleTimeout = 60 * time.Second
+
+// ZMODEM transfer directions, from this side's point of view
+const (
+	zmodemDownload = "download" // Remote ran sz
+	zmodemUpload   = "upload"   // Remote ran rz
+)
+
+// zmodemStartPrefix begins the hex header that starts a transfer. The next
+// byte tells the direction: ZRQINIT ("0") from sz, ZRINIT ("1") from rz.
+var zmodemStartPrefix = []byte("**\x18B0")
+
+// zmodemTransfer is the transfer running in a terminal
+type zmodemTransfer struct {
+	direction    string
+	cancelled    atomic.Bool
+	lastActivity atomic.Int64 // Unix nanoseconds of the last byte received
+}
+
+// zmodemDetector finds transfer start sequences in raw SSH output.
+// Bytes that may begin a sequence split across reads are held back.
+//
+// Thread safety: NOT thread-safe. Owned by the stdout reader goroutine.
+type zmodemDetector struct {
+	held []byte
+}
+
+// scan returns the output to display and, if a transfer starts, its
+// direction and the bytes from the start sequence onwards
+func (d *zmodemDetector) scan(data []byte) (output []byte, direction string, rest []byte) {
+	if len(d.held) > 0 {
+		data = append(d.held, data...)
+		d.held = nil
+	}
+
+	for pos := 0; ; {
+		i := bytes.Index(data[pos:], zmodemStartPrefix)
+		if i < 0 {
+			break
+		}
+		i += pos
+		next := i + len(zmodemStartPrefix)
+		if next >= len(data) {
+			d.held = append([]byte(nil), data[i:]...)
+			return data[:i], "", nil
+		}
+		switch data[next] {
+		case '0':
+			return data[:i], zmodemDownload, data[i:]
+		case '1':
+			return data[:i], zmodemUpload, data[i:]
+		}
+		pos = i + 1
+	}
+
+	// Hold a trailing "**", "**\x18", ... in case the sequence continues in the next read
+	for k := len(zmodemStartPrefix) - 1; k >= 2; k-- {
+		if bytes.HasSuffix(data, zmodemStartPrefix[:k]) {
+			d.held = append([]byte(nil), data[len(data)-k:]...)
+			return data[:len(data)-k], "", nil
+		}
+	}
+	return data, "", nil
+}
+
+// flush returns any held-back bytes. Called when the session ends.
+func (d *zmodemDetector) flush() []byte {
+	held := d.held
+	d.held = nil
+	return held
+}
+
+// zmodemActivityReader records when the remote last sent something, for the idle watchdog
+type zmodemActivityReader struct {
+	r        io.Reader
+	transfer *zmodemTransfer
+}
+
+func (r *zmodemActivityReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.transfer.lastActivity.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
+
+// zmodemWriter sends protocol bytes to the remote, serialized with other terminal writes
+type zmodemWriter struct {
+	termSession *TerminalSession
+}
+
+func (w zmodemWriter) Write(p []byte) (int, error) {
+	w.termSession.mu.Lock()
+	defer w.termSession.mu.Unlock()
+	return w.termSession.StdinPipe.Write(p)
+}
+
+// cancelZmodemTransfer aborts a transfer by sending CANs to the remote. The
+// protocol loop stops at its next read.
+func cancelZmodemTransfer(termSession *TerminalSession, transfer *zmodemTransfer, reason string) {
+	if !transfer.cancelled.CompareAndSwap(false, true) {
+		return
+	}
+	log.Printf("🛑 [ZMODEM] Cancelling transfer in terminal %s: %s", termSession.SessionID, reason)
+	if _, err := (zmodemWriter{termSession}).Write(zmodemAbortSequence); err != nil {
+		log.Printf("⚠️ [ZMODEM] Failed to send abort sequence: %v", err)
+	}
+}
+
+// CancelZmodemTransfer aborts the ZMODEM transfer running in a terminal
+func (a *App) CancelZmodemTransfer(sessionID string) error {
+	termSessionMu.RLock()
+	termSession, exists := terminalSessions[sessionID]
+	termSessionMu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("terminal session not found: %s", sessionID)
+	}
+
+	transfer := termSession.zmodem.Load()
+	if transfer == nil {
+		return fmt.Errorf("no ZMODEM transfer in progress")
+	}
+	cancelZmodemTransfer(termSession, transfer, "cancelled by user")
+	return nil
+}
+
+// zmodemDefaultDirectory is where the directory dialog for downloads opens
+func zmodemDefaultDirectory() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	downloads := filepath.Join(homeDir, "Downloads")
+	if stat, err := os.Stat(downloads); err == nil && stat.IsDir() {
+		return downloads
+	}
+	return homeDir
+}
+
+// newZmodemProgressReporter returns a progress callback that emits
+// transfer:progress events, at most every 100ms while a file is transferring
+func (a *App) newZmodemProgressReporter(sessionID string, direction string) zmodemProgress {
+	var lastEmit time.Time
+	var lastFile string
+	return func(fileName string, transferred, total int64, status string, err error) {
+		if status == "transferring" && fileName == lastFile && time.Since(lastEmit) < 100*time.Millisecond {
+			return
+		}
+		lastEmit, lastFile = time.Now(), fileName
+
+		progress := TransferProgress{
+			SessionID:   sessionID,
+			Direction:   direction,
+			FileName:    fileName,
+			TotalBytes:  total,
+			Transferred: transferred,
+			Status:      status,
+		}
+		if total > 0 {
+			progress.Percent = float64(transferred) / float64(total) * 100
+		} else if status == "completed" {
+			progress.Percent = 100
+		}
+		if err != nil {
+			progress.Error = err.Error()
+			log.Printf("❌ [ZMODEM] %s: %v", fileName, err)
+		}
+
+		if a.ctx != nil {
+			wailsRuntime.EventsEmit(a.ctx, "transfer:progress", progress)
+		}
+	}
+}
+
+// runZmodemTransfer runs a transfer started by the remote, reading protocol
+// data from stdout in the caller's reader goroutine. start holds the bytes from
+// the start sequence on. Returns bytes read past the end of the transfer,
+// which are normal terminal output.
+func (a *App) runZmodemTransfer(termSession *TerminalSession, stdout io.Reader, direction string, start []byte) []byte {
+	sessionID := termSession.SessionID
+	transfer := &zmodemTransfer{direction: direction}
+	termSession.zmodem.Store(transfer)
+	defer termSession.zmodem.Store(nil)
+
+	log.Printf("📦 [ZMODEM] %s started in terminal %s", direction, sessionID)
+
+	// Ask the user what to receive into or what to send. The remote waits meanwhile.
+	var dir string
+	var files []string
+	var err error
+	if a.ctx != nil {
+		if direction == zmodemDownload {
+			dir, err = wailsRuntime.OpenDirectoryDialog(a.ctx, wailsRuntime.OpenDialogOptions{
+				Title:                "Save files sent by sz to",
+				DefaultDirectory:     zmodemDefaultDirectory(),
+				CanCreateDirectories: true,
+			})
+		} else {
+			files, err = wailsRuntime.OpenMultipleFilesDialog(a.ctx, wailsRuntime.OpenDialogOptions{
+				Title: "Select files to send to rz",
+			})
+		}
+	}
+	if err != nil || (dir == "" && len(files) == 0) {
+		if err != nil {
+			log.Printf("⚠️ [ZMODEM] Dialog failed: %v", err)
+		}
+		// The start sequence is dropped; the remote prints its own message after the abort
+		cancelZmodemTransfer(termSession, transfer, "no files selected")
+		a.emitTerminalOutput(sessionID, "\r\n\x1b[33m[ZMODEM] Transfer cancelled\x1b[0m\r\n")
+		return nil
+	}
+
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "zmodem:started", map[string]interface{}{
+			"sessionId": sessionID,
+			"direction": direction,
+		})
+	}
+
+	bufSize := IOBufferSize
+	if len(start) > bufSize {
+		bufSize = len(start)
+	}
+	transfer.lastActivity.Store(time.Now().UnixNano())
+	reader := bufio.NewReaderSize(io.MultiReader(bytes.NewReader(start), &zmodemActivityReader{stdout, transfer}), bufSize)
+	conn := &zmodemConn{r: reader, w: zmodemWriter{termSession}, cancelled: &transfer.cancelled}
+	progress := a.newZmodemProgressReporter(sessionID, direction)
+
+	// Cancel if the remote goes quiet, e.g. a serial hop dropped the connection
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if time.Since(time.Unix(0, transfer.lastActivity.Load())) > ZmodemIdleTimeout {
+					cancelZmodemTransfer(termSession, transfer, "remote stopped responding")
+					return
+				}
+			}
+		}
+	}()
+
+	var transferred []string
+	if direction == zmodemDownload {
+		receiver := &zmodemReceiver{conn: conn, dir: dir, progress: progress}
+		err = receiver.run()
+		transferred = receiver.received
+	} else {
+		sender := &zmodemSender{conn: conn, files: files, progress: progress}
+		err = sender.run()
+		transferred = sender.sent
+	}
+	close(done)
+
+	var summary string
+	switch {
+	case err != nil:
+		if err != errZmodemCancelled {
+			cancelZmodemTransfer(termSession, transfer, err.Error())
+		}
+		log.Printf("❌ [ZMODEM] %s failed in terminal %s: %v", direction, sessionID, err)
+		summary = fmt.Sprintf("\r\n\x1b[31m[ZMODEM] Transfer failed after %d file(s): %v\x1b[0m\r\n", len(transferred), err)
+	case direction == zmodemDownload:
+		log.Printf("✅ [ZMODEM] Received %d file(s) into %s", len(transferred), dir)
+		summary = fmt.Sprintf("\r\n\x1b[32m[ZMODEM] Received %d file(s) into %s\x1b[0m\r\n", len(transferred), dir)
+	default:
+		log.Printf("✅ [ZMODEM] Sent %d file(s)", len(transferred))
+		summary = fmt.Sprintf("\r\n\x1b[32m[ZMODEM] Sent %d file(s)\x1b[0m\r\n", len(transferred))
+	}
+	a.emitTerminalOutput(sessionID, summary)
+
+	if a.ctx != nil {
+		event := map[string]interface{}{
+			"sessionId": sessionID,
+			"direction": direction,
+			"files":     transferred,
+		}
+		if err != nil {
+			event["error"] = err.Error()
+		}
+		wailsRuntime.EventsEmit(a.ctx, "zmodem:finished", event)
+	}
+
+	// Whatever was read past the end of the session is ordinary output again
+	leftover, _ := reader.Peek(reader.Buffered())
+	return append([]byte(nil), leftover...)
+}
@@ -0,0 +1,696 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Transfer queue constants
+const (
+	// DefaultTransferConcurrency is how many queued transfers run at once
+	DefaultTransferConcurrency = 3
+	// MaxTransferConcurrency caps SetTransferConcurrency
+	MaxTransferConcurrency = 16
+	// MaxTransferHistory is the number of finished jobs kept in transfer-history.json
+	MaxTransferHistory = 100
+	// MaxFailedFiles is the number of per-file failures kept for a directory job
+	MaxFailedFiles = 100
+	// transferProgressInterval throttles transfer:progress events per job
+	transferProgressInterval = 200 * time.Millisecond
+	// transferRateWindow is the period the transfer rate is averaged over
+	transferRateWindow = 5 * time.Second
+)
+
+// TransferJob is an upload, download or remote-to-remote copy in the transfer queue
+type TransferJob struct {
+	ID          string `json:"id"`
+	SessionID   string `json:"sessionId"` // Source session of a copy
+	Direction   string `json:"direction"` // "upload", "download" or "copy"
+	Source      string `json:"source"`    // Local path for uploads, remote path for downloads and copies
+	TargetDir   string `json:"targetDir"` // Directory the source is copied into
+	Target      string `json:"target"`    // Path of the copy, once known
+	IsDir       bool   `json:"isDir"`
+	Status      string `json:"status"` // "queued", "transferring", "paused", "completed", "error" or "cancelled"
+	TotalBytes  int64  `json:"totalBytes"`
+	Transferred int64  `json:"transferred"`
+	Error       string `json:"error,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+	FinishedAt  string `json:"finishedAt,omitempty"`
+
+	// Target session of a copy, and whether to try pushing from the source
+	// server straight to the target before streaming through the app
+	TargetSessionID string `json:"targetSessionId,omitempty"`
+	Direct          bool   `json:"direct,omitempty"`
+
+	// Files of a directory job that failed, up to MaxFailedFiles
+	FailedFiles []TransferFileError `json:"failedFiles,omitempty"`
+
+	// Measured throughput: bytes copied (not counting resumed data) over the
+	// time spent transferring, excluding pauses
+	DurationMs     int64   `json:"durationMs"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+}
+
+// transferJob is a job waiting in or being run by the queue. Its fields are
+// guarded by transferQueue.mu.
+type transferJob struct {
+	info     TransferJob
+	cancel   context.CancelFunc // Set while transferring
+	pausing  bool               // cancel was called to pause, not to cancel
+	resumed  bool               // Skip files an earlier run of a directory download completed
+	lastEmit time.Time
+	rate     transferRate
+	runStart time.Time     // When the current run started
+	active   time.Duration // Time spent in earlier runs
+	copied   int64         // Bytes copied over all runs
+}
+
+// transferRate measures throughput over the last transferRateWindow
+type transferRate struct {
+	samples []rateSample
+}
+
+type rateSample struct {
+	at    time.Time
+	bytes int64
+}
+
+// add records the total bytes transferred at a point in time
+func (r *transferRate) add(at time.Time, bytes int64) {
+	r.samples = append(r.samples, rateSample{at: at, bytes: bytes})
+	for len(r.samples) > 2 && at.Sub(r.samples[0].at) > transferRateWindow {
+		r.samples = r.samples[1:]
+	}
+}
+
+// bytesPerSecond returns the average rate over the samples, or 0 before there are two
+func (r *transferRate) bytesPerSecond() float64 {
+	if len(r.samples) < 2 {
+		return 0
+	}
+	first, last := r.samples[0], r.samples[len(r.samples)-1]
+	seconds := last.at.Sub(first.at).Seconds()
+	if seconds <= 0 {
+		return 0
+	}
+	return float64(last.bytes-first.bytes) / seconds
+}
+
+// transferQueue holds queued, running and paused jobs in order, and finished
+// jobs backed by transfer-history.json
+var transferQueue = struct {
+	mu          sync.Mutex
+	loaded      bool
+	concurrency int
+	running     int
+	jobs        []*transferJob
+	history     []TransferJob // Newest first
+}{
+	concurrency: DefaultTransferConcurrency,
+}
+
+// loadTransferHistoryLocked reads transfer-history.json once. Caller must hold the lock.
+func loadTransferHistoryLocked() {
+	if transferQueue.loaded {
+		return
+	}
+	transferQueue.loaded = true
+
+	historyPath, err := getAppConfigPath("transfer-history.json")
+	if err != nil {
+		log.Printf("⚠️ [Transfers] Failed to get history path: %v", err)
+		return
+	}
+
+	data, err := os.ReadFile(historyPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ [Transfers] Failed to read history: %v", err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(data, &transferQueue.history); err != nil {
+		log.Printf("⚠️ [Transfers] Failed to parse history: %v", err)
+		transferQueue.history = nil
+	}
+}
+
+// saveTransferHistoryLocked writes the finished jobs to disk. Caller must hold the lock.
+func saveTransferHistoryLocked() error {
+	historyPath, err := getAppConfigPath("transfer-history.json")
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(transferQueue.history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transfer history: %v", err)
+	}
+	if err := os.WriteFile(historyPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write transfer history: %v", err)
+	}
+	return nil
+}
+
+// findTransferJobLocked returns the index of an unfinished job, or -1. Caller must hold the lock.
+func findTransferJobLocked(id string) int {
+	for i, job := range transferQueue.jobs {
+		if job.info.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// finishTransferJobLocked moves a job from the queue to the history. Caller must hold the lock.
+func finishTransferJobLocked(job *transferJob) {
+	if i := findTransferJobLocked(job.info.ID); i >= 0 {
+		transferQueue.jobs = append(transferQueue.jobs[:i], transferQueue.jobs[i+1:]...)
+	}
+	job.info.FinishedAt = time.Now().Format(time.RFC3339)
+
+	loadTransferHistoryLocked()
+	transferQueue.history = append([]TransferJob{job.info}, transferQueue.history...)
+	if len(transferQueue.history) > MaxTransferHistory {
+		transferQueue.history = transferQueue.history[:MaxTransferHistory]
+	}
+	if err := saveTransferHistoryLocked(); err != nil {
+		log.Printf("⚠️ [Transfers] %v", err)
+	}
+}
+
+// progressLocked describes a job as a transfer:progress event. Caller must hold the lock.
+func (job *transferJob) progressLocked() TransferProgress {
+	progress := TransferProgress{
+		ID:          job.info.ID,
+		SessionID:   job.info.SessionID,
+		Direction:   job.info.Direction,
+		FileName:    filepath.Base(job.info.Source),
+		TotalBytes:  job.info.TotalBytes,
+		Transferred: job.info.Transferred,
+		Status:      job.info.Status,
+		Error:       job.info.Error,
+	}
+	if job.info.TotalBytes > 0 {
+		progress.Percent = float64(job.info.Transferred) / float64(job.info.TotalBytes) * 100
+	} else if job.info.Status == "completed" {
+		progress.Percent = 100
+	}
+	if job.info.Status == "completed" {
+		progress.BytesPerSecond = job.info.BytesPerSecond
+	}
+	if job.info.Status == "transferring" {
+		progress.BytesPerSecond = job.rate.bytesPerSecond()
+		if progress.BytesPerSecond > 0 && job.info.TotalBytes > job.info.Transferred {
+			progress.EtaSeconds = int64(float64(job.info.TotalBytes-job.info.Transferred) / progress.BytesPerSecond)
+		}
+	}
+	return progress
+}
+
+// emitTransferProgress sends a transfer:progress event
+func (a *App) emitTransferProgress(progress TransferProgress) {
+	if a.ctx != nil {
+		wailsRuntime.EventsEmit(a.ctx, "transfer:progress", progress)
+	}
+}
+
+// enqueueTransfer adds a job to the queue and starts it if a slot is free
+func (a *App) enqueueTransfer(info TransferJob) TransferJob {
+	info.ID = fmt.Sprintf("transfer-%d", time.Now().UnixNano())
+	info.Status = "queued"
+	info.CreatedAt = time.Now().Format(time.RFC3339)
+	job := &transferJob{info: info}
+
+	transferQueue.mu.Lock()
+	transferQueue.jobs = append(transferQueue.jobs, job)
+	progress := job.progressLocked()
+	a.scheduleTransfersLocked()
+	transferQueue.mu.Unlock()
+
+	log.Printf("📋 [Transfers] Queued %s of %s", info.Direction, info.Source)
+	a.emitTransferProgress(progress)
+	return info
+}
+
+// scheduleTransfersLocked starts queued jobs while there are free slots. Caller must hold the lock.
+func (a *App) scheduleTransfersLocked() {
+	for _, job := range transferQueue.jobs {
+		if transferQueue.running >= transferQueue.concurrency {
+			return
+		}
+		if job.info.Status != "queued" {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		job.cancel = cancel
+		job.pausing = false
+		job.info.Status = "transferring"
+		job.info.Error = ""
+		job.info.FailedFiles = nil
+		job.rate = transferRate{}
+		job.runStart = time.Now()
+		transferQueue.running++
+		go a.runTransfer(ctx, job)
+	}
+}
+
+// runTransfer runs a job in its slot, then records how it ended
+func (a *App) runTransfer(ctx context.Context, job *transferJob) {
+	transferQueue.mu.Lock()
+	info := job.info
+	resumed := job.resumed
+	transferQueue.mu.Unlock()
+
+	var err error
+	switch info.Direction {
+	case "upload":
+		err = a.runUploadJob(ctx, job, info, resumed)
+	case "copy":
+		err = a.runCopyJob(ctx, job, info, resumed)
+	default:
+		err = a.runDownloadJob(ctx, job, info, resumed)
+	}
+
+	cancelled := ctx.Err() != nil
+	transferQueue.mu.Lock()
+	job.cancel()
+	job.cancel = nil
+	transferQueue.running--
+	job.active += time.Since(job.runStart)
+	job.info.DurationMs = job.active.Milliseconds()
+	if job.active > 0 {
+		job.info.BytesPerSecond = float64(job.copied) / job.active.Seconds()
+	}
+	switch {
+	case job.pausing:
+		job.info.Status = "paused"
+		job.resumed = true
+	case cancelled:
+		job.info.Status = "cancelled"
+	case err != nil:
+		job.info.Status = "error"
+		job.info.Error = err.Error()
+	default:
+		job.info.Status = "completed"
+	}
+	if job.info.Status != "paused" {
+		finishTransferJobLocked(job)
+	}
+	progress := job.progressLocked()
+	average := job.info.BytesPerSecond
+	a.scheduleTransfersLocked()
+	transferQueue.mu.Unlock()
+
+	if progress.Status == "cancelled" {
+		discardPartialTransfer(job.info)
+	}
+	if err != nil && progress.Status == "error" {
+		log.Printf("❌ [Transfers] %s of %s failed: %v", info.Direction, info.Source, err)
+	} else {
+		log.Printf("📋 [Transfers] %s of %s %s (%.0f KB/s)", info.Direction, info.Source, progress.Status, average/1024)
+	}
+	a.emitTransferProgress(progress)
+}
+
+// jobProgress returns a callback that counts a job's copied bytes and emits throttled progress
+func (a *App) jobProgress(job *transferJob) func(int64) {
+	return func(n int64) {
+		transferQueue.mu.Lock()
+		job.info.Transferred += n
+		job.copied += n
+		now := time.Now()
+		emit := now.Sub(job.lastEmit) >= transferProgressInterval
+		var progress TransferProgress
+		if emit {
+			job.lastEmit = now
+			job.rate.add(now, job.info.Transferred)
+			progress = job.progressLocked()
+		}
+		transferQueue.mu.Unlock()
+
+		if emit {
+			a.emitTransferProgress(progress)
+		}
+	}
+}
+
+// jobSkipped returns a callback that counts bytes a job already had, without
+// them counting towards its throughput
+func (a *App) jobSkipped(job *transferJob) func(int64) {
+	return func(n int64) {
+		transferQueue.mu.Lock()
+		job.info.Transferred += n
+		job.rate = transferRate{}
+		transferQueue.mu.Unlock()
+	}
+}
+
+// startJobLocked records a job's target, size and the bytes it already has
+// before the copy starts. Caller must hold the lock.
+func startJobLocked(job *transferJob, target string, total int64, offset int64) {
+	job.info.Target = target
+	job.info.TotalBytes = total
+	job.info.Transferred = offset
+	job.rate.add(time.Now(), offset)
+}
+
+// runDownloadJob downloads a remote file or directory into the job's target directory
+func (a *App) runDownloadJob(ctx context.Context, job *transferJob, info TransferJob, resumed bool) error {
+	sftpClient, err := getSFTPClient(info.SessionID)
+	if err != nil {
+		return err
+	}
+	remotePath := resolveRemotePath(sftpClient, info.Source)
+	remoteInfo, err := sftpClient.Stat(remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat remote path: %v", err)
+	}
+	localPath := filepath.Join(info.TargetDir, filepath.Base(remotePath))
+
+	if !remoteInfo.IsDir() {
+		start := func(offset, total int64) {
+			transferQueue.mu.Lock()
+			startJobLocked(job, localPath, total, offset)
+			transferQueue.mu.Unlock()
+		}
+		fc := fileCopyFor(info.SessionID)
+		fc.start, fc.progress = start, a.jobProgress(job)
+		_, err := resumableDownload(ctx, sftpClient, remotePath, localPath, fc)
+		return err
+	}
+
+	if err := os.MkdirAll(localPath, 0755); err != nil {
+		return fmt.Errorf("failed to create local directory: %v", err)
+	}
+	opts := a.jobTreeTransfer(job, info.SessionID, resumed)
+	entries, errs := listRemoteTree(sftpClient, remotePath, opts.symlinks)
+	startTreeJob(job, localPath, entries)
+
+	errs = append(errs, downloadRemoteTree(ctx, sftpClient, entries, localPath, opts)...)
+	return finishTreeJob(job, errs)
+}
+
+// runUploadJob uploads a local file or directory into the job's remote target directory
+func (a *App) runUploadJob(ctx context.Context, job *transferJob, info TransferJob, resumed bool) error {
+	sftpClient, err := getSFTPClient(info.SessionID)
+	if err != nil {
+		return err
+	}
+	localInfo, err := os.Stat(info.Source)
+	if err != nil {
+		return fmt.Errorf("failed to stat local file: %v", err)
+	}
+	remotePath := resolveRemotePath(sftpClient, info.TargetDir) + "/" + filepath.Base(info.Source)
+
+	if !localInfo.IsDir() {
+		start := func(offset, total int64) {
+			transferQueue.mu.Lock()
+			startJobLocked(job, remotePath, total, offset)
+			transferQueue.mu.Unlock()
+		}
+		fc := fileCopyFor(info.SessionID)
+		fc.start, fc.progress = start, a.jobProgress(job)
+		_, err = resumableUpload(ctx, sftpClient, info.Source, remotePath, fc)
+		return err
+	}
+
+	if err := sftpClient.MkdirAll(remotePath); err != nil {
+		return fmt.Errorf("failed to create remote directory: %v", err)
+	}
+	opts := a.jobTreeTransfer(job, info.SessionID, resumed)
+	entries, errs := listLocalTree(info.Source, opts.symlinks)
+	startTreeJob(job, remotePath, entries)
+
+	errs = append(errs, uploadTree(ctx, sftpClient, entries, remotePath, opts)...)
+	return finishTreeJob(job, errs)
+}
+
+// startTreeJob records a directory job's target and the size of its files
+func startTreeJob(job *transferJob, target string, entries []treeEntry) {
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir {
+			total += entry.Size
+		}
+	}
+	transferQueue.mu.Lock()
+	job.info.IsDir = true
+	startJobLocked(job, target, total, 0)
+	transferQueue.mu.Unlock()
+}
+
+// jobTreeTransfer configures a directory job's transfer. A resumed job skips
+// files an earlier run completed.
+func (a *App) jobTreeTransfer(job *transferJob, sessionID string, resumed bool) treeTransfer {
+	opts := treeTransferFor(sessionID)
+	opts.skipComplete = resumed
+	opts.progress = a.jobProgress(job)
+	opts.skipped = a.jobSkipped(job)
+	return opts
+}
+
+// finishTreeJob records a directory job's per-file failures and returns them as one error
+func finishTreeJob(job *transferJob, errs []error) error {
+	failed := transferFileErrors(errs)
+	if len(failed) > MaxFailedFiles {
+		failed = failed[:MaxFailedFiles]
+	}
+	transferQueue.mu.Lock()
+	job.info.FailedFiles = failed
+	transferQueue.mu.Unlock()
+	return summarizeTransferErrors(errs)
+}
+
+// summarizeTransferErrors turns per-file failures into one error, or nil if there were none
+func summarizeTransferErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return fmt.Errorf("%d files failed, first: %v", len(errs), errs[0])
+}
+
+// discardPartialTransfer removes the .part file of a cancelled file transfer.
+// Files of a cancelled directory transfer are left in place.
+func discardPartialTransfer(info TransferJob) {
+	if info.IsDir || info.Target == "" {
+		return
+	}
+	if info.Direction == "download" {
+		os.Remove(info.Target + partSuffix)
+		return
+	}
+	sessionID := info.SessionID
+	if info.Direction == "copy" {
+		sessionID = info.TargetSessionID
+	}
+	if sftpClient, err := getSFTPClient(sessionID); err == nil {
+		sftpClient.Remove(info.Target + partSuffix)
+	}
+}
+
+// QueueDownload queues a download of a remote file or directory into localDir
+// and returns the job. Progress arrives as transfer:progress events.
+func (a *App) QueueDownload(sessionID string, remotePath string, localDir string) (TransferJob, error) {
+	if _, err := getConnectedSSHSession(sessionID); err != nil {
+		return TransferJob{}, err
+	}
+	if info, err := os.Stat(localDir); err != nil || !info.IsDir() {
+		return TransferJob{}, fmt.Errorf("local directory does not exist: %s", localDir)
+	}
+	return a.enqueueTransfer(TransferJob{
+		SessionID: sessionID,
+		Direction: "download",
+		Source:    remotePath,
+		TargetDir: localDir,
+	}), nil
+}
+
+// QueueUpload queues an upload of a local file or directory into remoteDir and returns the job
+func (a *App) QueueUpload(sessionID string, localPath string, remoteDir string) (TransferJob, error) {
+	if _, err := getConnectedSSHSession(sessionID); err != nil {
+		return TransferJob{}, err
+	}
+	if _, err := os.Stat(localPath); err != nil {
+		return TransferJob{}, fmt.Errorf("failed to stat local file: %v", err)
+	}
+	return a.enqueueTransfer(TransferJob{
+		SessionID: sessionID,
+		Direction: "upload",
+		Source:    localPath,
+		TargetDir: strings.TrimSuffix(remoteDir, "/"),
+	}), nil
+}
+
+// GetTransferJobs returns the queued, running and paused jobs in queue order
+func (a *App) GetTransferJobs() []TransferJob {
+	transferQueue.mu.Lock()
+	defer transferQueue.mu.Unlock()
+
+	result := make([]TransferJob, 0, len(transferQueue.jobs))
+	for _, job := range transferQueue.jobs {
+		result = append(result, job.info)
+	}
+	return result
+}
+
+// GetTransferHistory returns finished jobs, newest first
+func (a *App) GetTransferHistory() []TransferJob {
+	transferQueue.mu.Lock()
+	defer transferQueue.mu.Unlock()
+	loadTransferHistoryLocked()
+
+	return append([]TransferJob{}, transferQueue.history...)
+}
+
+// ClearTransferHistory forgets all finished jobs
+func (a *App) ClearTransferHistory() error {
+	transferQueue.mu.Lock()
+	defer transferQueue.mu.Unlock()
+	loadTransferHistoryLocked()
+
+	transferQueue.history = nil
+	return saveTransferHistoryLocked()
+}
+
+// CancelTransfer stops a job and removes its incomplete file
+func (a *App) CancelTransfer(id string) error {
+	transferQueue.mu.Lock()
+	i := findTransferJobLocked(id)
+	if i < 0 {
+		transferQueue.mu.Unlock()
+		return fmt.Errorf("transfer not found: %s", id)
+	}
+	job := transferQueue.jobs[i]
+	if job.info.Status == "transferring" {
+		// runTransfer records the cancellation
+		job.pausing = false
+		job.cancel()
+		transferQueue.mu.Unlock()
+		return nil
+	}
+
+	job.info.Status = "cancelled"
+	finishTransferJobLocked(job)
+	info := job.info
+	progress := job.progressLocked()
+	transferQueue.mu.Unlock()
+
+	discardPartialTransfer(info)
+	a.emitTransferProgress(progress)
+	return nil
+}
+
+// PauseTransfer stops a job without discarding what it transferred. A paused
+// job gives up its slot until it is resumed.
+func (a *App) PauseTransfer(id string) error {
+	transferQueue.mu.Lock()
+	defer transferQueue.mu.Unlock()
+
+	i := findTransferJobLocked(id)
+	if i < 0 {
+		return fmt.Errorf("transfer not found: %s", id)
+	}
+	job := transferQueue.jobs[i]
+	switch job.info.Status {
+	case "transferring":
+		// runTransfer marks the job paused once the copy stops
+		job.pausing = true
+		job.cancel()
+	case "queued":
+		job.info.Status = "paused"
+		a.emitTransferProgress(job.progressLocked())
+	}
+	return nil
+}
+
+// ResumeTransfer queues a paused job again. It continues where it stopped.
+func (a *App) ResumeTransfer(id string) error {
+	transferQueue.mu.Lock()
+	defer transferQueue.mu.Unlock()
+
+	i := findTransferJobLocked(id)
+	if i < 0 {
+		return fmt.Errorf("transfer not found: %s", id)
+	}
+	job := transferQueue.jobs[i]
+	if job.info.Status != "paused" {
+		return fmt.Errorf("transfer is not paused")
+	}
+	job.info.Status = "queued"
+	a.emitTransferProgress(job.progressLocked())
+	a.scheduleTransfersLocked()
+	return nil
+}
+
+// RetryTransfer queues a finished job from the history again as a new job
+func (a *App) RetryTransfer(id string) (TransferJob, error) {
+	transferQueue.mu.Lock()
+	loadTransferHistoryLocked()
+	var previous *TransferJob
+	for i := range transferQueue.history {
+		if transferQueue.history[i].ID == id {
+			previous = &transferQueue.history[i]
+			break
+		}
+	}
+	var info TransferJob
+	if previous != nil {
+		info = TransferJob{
+			SessionID:       previous.SessionID,
+			Direction:       previous.Direction,
+			Source:          previous.Source,
+			TargetDir:       previous.TargetDir,
+			TargetSessionID: previous.TargetSessionID,
+			Direct:          previous.Direct,
+		}
+	}
+	transferQueue.mu.Unlock()
+
+	if previous == nil {
+		return TransferJob{}, fmt.Errorf("transfer not found in history: %s", id)
+	}
+	if _, err := getConnectedSSHSession(info.SessionID); err != nil {
+		return TransferJob{}, err
+	}
+	if info.Direction == "copy" {
+		if _, err := getConnectedSSHSession(info.TargetSessionID); err != nil {
+			return TransferJob{}, err
+		}
+	}
+	return a.enqueueTransfer(info), nil
+}
+
+// GetTransferConcurrency returns how many queued transfers run at once
+func (a *App) GetTransferConcurrency() int {
+	transferQueue.mu.Lock()
+	defer transferQueue.mu.Unlock()
+	return transferQueue.concurrency
+}
+
+// SetTransferConcurrency changes how many queued transfers run at once.
+// Running jobs above a lowered limit finish normally.
+func (a *App) SetTransferConcurrency(concurrency int) error {
+	if concurrency < 1 || concurrency > MaxTransferConcurrency {
+		return fmt.Errorf("concurrency must be between 1 and %d", MaxTransferConcurrency)
+	}
+
+	transferQueue.mu.Lock()
+	defer transferQueue.mu.Unlock()
+	transferQueue.concurrency = concurrency
+	a.scheduleTransfersLocked()
+	return nil
+}
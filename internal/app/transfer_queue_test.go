@@ -0,0 +1,87 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// pipeConn joins the two pipe ends an SFTP server talks over
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// newTestSFTPClient serves the local filesystem over an in-process SFTP
+// server and registers the client for sessionID
+func newTestSFTPClient(t *testing.T, sessionID string, options ...sftp.ClientOption) *sftp.Client {
+	t.Helper()
+	clientRead, serverWrite := io.Pipe()
+	serverRead, clientWrite := io.Pipe()
+	server, err := sftp.NewServer(pipeConn{serverRead, serverWrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(clientRead, clientWrite, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sftpPool.mu.Lock()
+	sftpPool.clients[sessionID] = client
+	sftpPool.mu.Unlock()
+	t.Cleanup(func() {
+		// Ends the client's receive loop, which Close waits for
+		serverWrite.Close()
+		closeSFTPClient(sessionID)
+	})
+	return client
+}
+
+// waitForTransfer polls the queue and history until a job has the status
+func waitForTransfer(t *testing.T, a *App, id string, status string) TransferJob {
+	t.Helper()
+	for i := 0; i < 500; i++ {
+		for _, job := range append(a.GetTransferJobs(), a.GetTransferHistory()...) {
+			if job.ID == id && job.Status == status {
+				return job
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Transfer %s never became %s", id, status)
+	return TransferJob{}
+}
+
+func TestTransferQueuePauseResume(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	newTestSFTPClient(t, "transfer-test")
+	a := &App{}
+
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 2<<20)
+	if err := os.WriteFile(filepath.Join(srcDir, "data.bin"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	job := a.enqueueTransfer(TransferJob{SessionID: "transfer-test", Direction: "download", Source: filepath.Join(srcDir, "data.bin"), TargetDir: dstDir})
+	if err := a.PauseTransfer(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	waitForTransfer(t, a, job.ID, "paused")
+	if err := a.ResumeTransfer(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	done := waitForTransfer(t, a, job.ID, "completed")
+
+	got, err := os.ReadFile(done.Target)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Downloaded file differs from the source (%v)", err)
+	}
+}
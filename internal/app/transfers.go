@@ -0,0 +1,797 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// Resume checks, chosen per host with HostSettings.ResumeCheck
+const (
+	// ResumeCheckTail resumes a partial copy only if a hash of its last block
+	// matches the same range of the source
+	ResumeCheckTail = "tail"
+	// ResumeCheckSize resumes any partial copy no larger than the source
+	ResumeCheckSize = "size"
+)
+
+// SFTP tuning limits, see HostSettings
+const (
+	// DefaultParallelFiles is how many files of a directory transfer at once
+	DefaultParallelFiles = 4
+	// MaxParallelFiles caps HostSettings.ParallelFiles
+	MaxParallelFiles = 32
+	// MaxSFTPRequestSize caps HostSettings.SFTPRequestSize; OpenSSH accepts up to 256 KiB
+	MaxSFTPRequestSize = 256 * 1024
+	// MaxSFTPConcurrency caps HostSettings.SFTPConcurrency
+	MaxSFTPConcurrency = 256
+)
+
+// Symlink policies for directory transfers and SFTP syncs, chosen per host
+// with HostSettings.Symlinks
+const (
+	// SymlinkFollow copies the file a link points to. Links to directories fail.
+	SymlinkFollow = "follow"
+	// SymlinkSkip leaves links out
+	SymlinkSkip = "skip"
+	// SymlinkCopy recreates links as links with the same target
+	SymlinkCopy = "copy"
+)
+
+const (
+	// preservedModeBits are the mode bits a preserving copy carries over
+	preservedModeBits = os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky
+	// partSuffix marks an incomplete copy. It's renamed to the target once complete.
+	partSuffix = ".part"
+	// resumeTailBlock is how much of a partial copy ResumeCheckTail compares
+	resumeTailBlock = 64 * 1024
+	// remoteSourceBuffer is the read size for remote-to-remote copies
+	remoteSourceBuffer = 1024 * 1024
+)
+
+// sftpClientOptions turns a host's SFTP tuning into client options. Concurrent
+// reads and writes keep several requests in flight per file, which is what
+// makes transfers fast on high-latency links.
+func sftpClientOptions(settings HostSettings) []sftp.ClientOption {
+	options := []sftp.ClientOption{sftp.UseConcurrentReads(true), sftp.UseConcurrentWrites(true)}
+	if settings.SFTPRequestSize > 0 {
+		options = append(options, sftp.MaxPacketUnchecked(settings.SFTPRequestSize))
+	}
+	if settings.SFTPConcurrency > 0 {
+		options = append(options, sftp.MaxConcurrentRequestsPerFile(settings.SFTPConcurrency))
+	}
+	return options
+}
+
+// parallelFiles returns how many files of a directory transfer run at once for a session
+func parallelFiles(sessionID string) int {
+	if n := sessionHostSettings(sessionID).ParallelFiles; n > 0 {
+		return n
+	}
+	return DefaultParallelFiles
+}
+
+// transferMonitor counts a transfer's bytes and stops it when its context ends
+type transferMonitor struct {
+	ctx      context.Context
+	progress func(n int64) // Called with each chunk's size, may be nil
+}
+
+func (m *transferMonitor) count(n int) {
+	if n > 0 && m.progress != nil {
+		m.progress(int64(n))
+	}
+}
+
+// copySource is where an upload reads from: the local filesystem, or another
+// SFTP server for remote-to-remote copies
+type copySource interface {
+	Stat(name string) (os.FileInfo, error)
+	Open(name string) (sourceFile, error)
+	AccessTime(info os.FileInfo) time.Time
+}
+
+// sourceFile is an open file of a copySource
+type sourceFile interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+}
+
+// localSource reads uploads from the local filesystem
+type localSource struct{}
+
+func (localSource) Stat(name string) (os.FileInfo, error) { return os.Stat(name) }
+func (localSource) Open(name string) (sourceFile, error)  { return os.Open(name) }
+func (localSource) AccessTime(info os.FileInfo) time.Time { return localAccessTime(info) }
+
+// remoteSource reads uploads from another SFTP server
+type remoteSource struct {
+	client *sftp.Client
+}
+
+func (r remoteSource) Stat(name string) (os.FileInfo, error) { return r.client.Stat(name) }
+func (r remoteSource) Open(name string) (sourceFile, error)  { return r.client.Open(name) }
+func (r remoteSource) AccessTime(info os.FileInfo) time.Time { return remoteAccessTime(info) }
+
+// sourceOrLocal returns source, or the local filesystem if it's nil
+func sourceOrLocal(source copySource) copySource {
+	if source == nil {
+		return localSource{}
+	}
+	return source
+}
+
+// monitoredReader is the source side of an upload
+type monitoredReader struct {
+	r         io.Reader
+	m         *transferMonitor
+	remaining int64
+}
+
+// Size tells sftp.File.ReadFrom how much is left, so it can pipeline the writes
+func (r *monitoredReader) Size() int64 {
+	return r.remaining
+}
+
+func (r *monitoredReader) Read(p []byte) (int, error) {
+	if err := r.m.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	r.m.count(n)
+	return n, err
+}
+
+// monitoredWriter is the local side of a download
+type monitoredWriter struct {
+	w io.Writer
+	m *transferMonitor
+}
+
+func (w *monitoredWriter) Write(p []byte) (int, error) {
+	if err := w.m.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := w.w.Write(p)
+	w.m.count(n)
+	return n, err
+}
+
+// downloadRemoteFile copies a remote file to localPath, continuing at offset
+// if it's above 0. Returns the size of the local file.
+func downloadRemoteFile(ctx context.Context, sftpClient *sftp.Client, remotePath string, localPath string, offset int64, progress func(int64)) (int64, error) {
+	remoteFile, err := sftpClient.Open(remotePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open remote file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_CREATE
+	}
+	localFile, err := os.OpenFile(localPath, flags, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create local file: %v", err)
+	}
+	defer localFile.Close()
+
+	if offset > 0 {
+		if _, err := remoteFile.Seek(offset, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("failed to seek remote file: %v", err)
+		}
+		if _, err := localFile.Seek(offset, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("failed to seek local file: %v", err)
+		}
+	}
+
+	monitor := &transferMonitor{ctx: ctx, progress: progress}
+	written, err := io.Copy(&monitoredWriter{w: localFile, m: monitor}, remoteFile)
+	if err != nil {
+		return offset + written, err
+	}
+	if err := localFile.Close(); err != nil {
+		return offset + written, fmt.Errorf("failed to write local file: %v", err)
+	}
+	return offset + written, nil
+}
+
+// uploadFile copies a file from source to remotePath, continuing at offset if
+// it's above 0. Returns the size of the remote file.
+func uploadFile(ctx context.Context, source copySource, sourcePath string, sftpClient *sftp.Client, remotePath string, offset int64, progress func(int64)) (int64, error) {
+	srcFile, err := source.Open(sourcePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open source file: %v", err)
+	}
+	defer srcFile.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_CREATE
+	}
+	remoteFile, err := sftpClient.OpenFile(remotePath, flags)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create remote file: %v", err)
+	}
+	defer remoteFile.Close()
+
+	if offset > 0 {
+		if _, err := srcFile.Seek(offset, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("failed to seek source file: %v", err)
+		}
+		if _, err := remoteFile.Seek(offset, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("failed to seek remote file: %v", err)
+		}
+	}
+
+	srcInfo, err := source.Stat(sourcePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat source file: %v", err)
+	}
+
+	var reader io.Reader = srcFile
+	if _, ok := source.(remoteSource); ok {
+		// Large reads let the source server pipeline its requests too
+		reader = bufio.NewReaderSize(srcFile, remoteSourceBuffer)
+	}
+	monitor := &transferMonitor{ctx: ctx, progress: progress}
+	written, err := io.Copy(remoteFile, &monitoredReader{r: reader, m: monitor, remaining: srcInfo.Size() - offset})
+	if err != nil {
+		// Concurrent writes past a failed one can leave holes. The file position
+		// is the end of what was written without gaps, so cut the rest off for resume.
+		if end, seekErr := remoteFile.Seek(0, io.SeekCurrent); seekErr == nil {
+			remoteFile.Truncate(end)
+			return end, err
+		}
+		return offset + written, err
+	}
+	if err := remoteFile.Close(); err != nil {
+		return offset + written, fmt.Errorf("failed to write remote file: %v", err)
+	}
+	return offset + written, nil
+}
+
+// resumeOffset returns how much of a partial copy can be kept: all of it if
+// it's no larger than the source and passes the check, otherwise nothing
+func resumeOffset(partial io.ReaderAt, partialSize int64, source io.ReaderAt, sourceSize int64, check string) int64 {
+	if partialSize <= 0 || partialSize > sourceSize {
+		return 0
+	}
+	if check == ResumeCheckSize {
+		return partialSize
+	}
+
+	n := min(partialSize, resumeTailBlock)
+	partialTail := make([]byte, n)
+	sourceTail := make([]byte, n)
+	if _, err := io.ReadFull(io.NewSectionReader(partial, partialSize-n, n), partialTail); err != nil {
+		return 0
+	}
+	if _, err := io.ReadFull(io.NewSectionReader(source, partialSize-n, n), sourceTail); err != nil {
+		return 0
+	}
+	if sha256.Sum256(partialTail) != sha256.Sum256(sourceTail) {
+		return 0
+	}
+	return partialSize
+}
+
+// transferResumeCheck returns the resume check configured for a session's host
+func transferResumeCheck(sessionID string) string {
+	if check := sessionHostSettings(sessionID).ResumeCheck; check != "" {
+		return check
+	}
+	return ResumeCheckTail
+}
+
+// fileCopy configures a resumable copy of one file
+type fileCopy struct {
+	source   copySource                // Where uploads read from, the local filesystem if nil
+	check    string                    // Resume check for partial files
+	preserve bool                      // Carry over mode bits and access and modification times
+	start    func(offset, total int64) // Called with the bytes kept and the total before copying, may be nil
+	progress func(int64)               // Called with bytes copied, may be nil
+}
+
+// fileCopyFor returns the resume check and attribute preservation configured for a session's host
+func fileCopyFor(sessionID string) fileCopy {
+	return fileCopy{
+		check:    transferResumeCheck(sessionID),
+		preserve: sessionHostSettings(sessionID).PreserveAttributes,
+	}
+}
+
+// remoteAccessTime returns a remote file's access time, or its modification
+// time if the server didn't send one
+func remoteAccessTime(info os.FileInfo) time.Time {
+	if stat, ok := info.Sys().(*sftp.FileStat); ok && stat.Atime != 0 {
+		return time.Unix(int64(stat.Atime), 0)
+	}
+	return info.ModTime()
+}
+
+// preserveLocal applies a source's mode bits and times to a local copy
+func preserveLocal(localPath string, mode os.FileMode, atime, mtime time.Time) error {
+	if err := os.Chmod(localPath, mode&preservedModeBits); err != nil {
+		return fmt.Errorf("failed to set mode: %v", err)
+	}
+	if err := os.Chtimes(localPath, atime, mtime); err != nil {
+		return fmt.Errorf("failed to set times: %v", err)
+	}
+	return nil
+}
+
+// preserveRemote applies a source's mode bits and times to a remote copy
+func preserveRemote(sftpClient *sftp.Client, remotePath string, mode os.FileMode, atime, mtime time.Time) error {
+	if err := sftpClient.Chmod(remotePath, mode&preservedModeBits); err != nil {
+		return fmt.Errorf("failed to set mode: %v", err)
+	}
+	if err := sftpClient.Chtimes(remotePath, atime, mtime); err != nil {
+		return fmt.Errorf("failed to set times: %v", err)
+	}
+	return nil
+}
+
+// linkLocal recreates a symlink locally, replacing a file or link at localPath
+func linkLocal(target string, localPath string) error {
+	if info, err := os.Lstat(localPath); err == nil && !info.IsDir() {
+		os.Remove(localPath)
+	}
+	if err := os.Symlink(target, localPath); err != nil {
+		return fmt.Errorf("failed to create symlink: %v", err)
+	}
+	return nil
+}
+
+// linkRemote recreates a symlink remotely, replacing a file or link at remotePath
+func linkRemote(sftpClient *sftp.Client, target string, remotePath string) error {
+	if info, err := sftpClient.Lstat(remotePath); err == nil && !info.IsDir() {
+		sftpClient.Remove(remotePath)
+	}
+	if err := sftpClient.Symlink(target, remotePath); err != nil {
+		return fmt.Errorf("failed to create symlink: %v", err)
+	}
+	return nil
+}
+
+// resumableDownload downloads a remote file to localPath through
+// localPath.part, continuing a partial copy an earlier attempt left behind,
+// and renames it into place once complete. Returns the file's size.
+func resumableDownload(ctx context.Context, sftpClient *sftp.Client, remotePath string, localPath string, opts fileCopy) (int64, error) {
+	remoteInfo, err := sftpClient.Stat(remotePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat remote file: %v", err)
+	}
+
+	partPath := localPath + partSuffix
+	var offset int64
+	if partInfo, err := os.Stat(partPath); err == nil && partInfo.Size() > 0 {
+		partFile, err := os.Open(partPath)
+		if err == nil {
+			if remoteFile, err := sftpClient.Open(remotePath); err == nil {
+				offset = resumeOffset(partFile, partInfo.Size(), remoteFile, remoteInfo.Size(), opts.check)
+				remoteFile.Close()
+			}
+			partFile.Close()
+		}
+		if offset > 0 {
+			log.Printf("⏯️ Resuming download of %s at %d bytes", remotePath, offset)
+		} else {
+			log.Printf("⚠️ Partial download %s doesn't match %s, starting over", partPath, remotePath)
+		}
+	}
+	if opts.start != nil {
+		opts.start(offset, remoteInfo.Size())
+	}
+
+	size, err := downloadRemoteFile(ctx, sftpClient, remotePath, partPath, offset, opts.progress)
+	if err != nil {
+		return size, err
+	}
+	// A request size the server doesn't accept shows up as short reads, not an error
+	if partInfo, err := os.Stat(partPath); err != nil || partInfo.Size() != remoteInfo.Size() {
+		os.Remove(partPath)
+		return size, fmt.Errorf("incomplete download of %s (check the SFTP request size)", remotePath)
+	}
+	if err := os.Rename(partPath, localPath); err != nil {
+		return size, fmt.Errorf("failed to rename %s: %v", partPath, err)
+	}
+	if opts.preserve {
+		if err := preserveLocal(localPath, remoteInfo.Mode(), remoteAccessTime(remoteInfo), remoteInfo.ModTime()); err != nil {
+			return size, err
+		}
+	}
+	return size, nil
+}
+
+// resumableUpload uploads a file from opts.source to remotePath through
+// remotePath.part, like resumableDownload
+func resumableUpload(ctx context.Context, sftpClient *sftp.Client, srcPath string, remotePath string, opts fileCopy) (int64, error) {
+	source := sourceOrLocal(opts.source)
+	srcInfo, err := source.Stat(srcPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat source file: %v", err)
+	}
+
+	partPath := remotePath + partSuffix
+	var offset int64
+	if partInfo, err := sftpClient.Stat(partPath); err == nil && partInfo.Size() > 0 {
+		partFile, err := sftpClient.Open(partPath)
+		if err == nil {
+			if srcFile, err := source.Open(srcPath); err == nil {
+				offset = resumeOffset(partFile, partInfo.Size(), srcFile, srcInfo.Size(), opts.check)
+				srcFile.Close()
+			}
+			partFile.Close()
+		}
+		if offset > 0 {
+			log.Printf("⏯️ Resuming upload of %s at %d bytes", srcPath, offset)
+		} else {
+			log.Printf("⚠️ Partial upload %s doesn't match %s, starting over", partPath, srcPath)
+		}
+	}
+	if opts.start != nil {
+		opts.start(offset, srcInfo.Size())
+	}
+
+	size, err := uploadFile(ctx, source, srcPath, sftpClient, partPath, offset, opts.progress)
+	if err != nil {
+		return size, err
+	}
+	if partInfo, err := sftpClient.Stat(partPath); err != nil || partInfo.Size() != srcInfo.Size() {
+		sftpClient.Remove(partPath)
+		return size, fmt.Errorf("incomplete upload of %s (check the SFTP request size)", srcPath)
+	}
+	if err := replaceRemoteFile(sftpClient, partPath, remotePath); err != nil {
+		return size, err
+	}
+	if opts.preserve {
+		if err := preserveRemote(sftpClient, remotePath, srcInfo.Mode(), source.AccessTime(srcInfo), srcInfo.ModTime()); err != nil {
+			return size, err
+		}
+	}
+	return size, nil
+}
+
+// replaceRemoteFile moves a finished .part file over the target. posix-rename
+// replaces the target atomically; only servers without it get the target
+// removed first, so a failed rename never loses the existing file.
+func replaceRemoteFile(sftpClient *sftp.Client, partPath string, remotePath string) error {
+	if _, ok := sftpClient.HasExtension("posix-rename@openssh.com"); ok {
+		if err := sftpClient.PosixRename(partPath, remotePath); err != nil {
+			return fmt.Errorf("failed to rename %s: %v", partPath, err)
+		}
+		return nil
+	}
+	sftpClient.Remove(remotePath)
+	if err := sftpClient.Rename(partPath, remotePath); err != nil {
+		return fmt.Errorf("failed to rename %s: %v", partPath, err)
+	}
+	return nil
+}
+
+// treeEntry is a file, directory or symlink under a directory being transferred
+type treeEntry struct {
+	Path       string // Source path
+	Rel        string // Path relative to the walked directory, with local separators
+	IsDir      bool
+	Size       int64
+	Mode       os.FileMode
+	ModTime    time.Time
+	AccessTime time.Time
+	Link       string // Target of a symlink to recreate as a link, empty otherwise
+}
+
+// errLinkedDirectory rejects following a symlink to a directory, which could loop
+var errLinkedDirectory = errors.New("links to directories are not followed")
+
+// fileError is a failure to copy one entry of a directory transfer
+type fileError struct {
+	path string
+	err  error
+}
+
+func (e *fileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.path, e.err)
+}
+
+func (e *fileError) Unwrap() error {
+	return e.err
+}
+
+// TransferFileError is a file a directory transfer couldn't copy
+type TransferFileError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// transferFileErrors lists the per-file failures among errs
+func transferFileErrors(errs []error) []TransferFileError {
+	var result []TransferFileError
+	for _, err := range errs {
+		var fe *fileError
+		if errors.As(err, &fe) {
+			result = append(result, TransferFileError{Path: fe.path, Error: fe.err.Error()})
+		}
+	}
+	return result
+}
+
+// listRemoteTree walks a remote directory, handling symlinks below it by
+// policy. The directory itself is the first entry, with Rel ".". Entries that
+// can't be read are returned as errors alongside the entries that could.
+func listRemoteTree(sftpClient *sftp.Client, remotePath string, symlinks string) ([]treeEntry, []error) {
+	var entries []treeEntry
+	var errs []error
+	// The trailing slash makes the server follow a symlinked root
+	walker := sftpClient.Walk(strings.TrimSuffix(remotePath, "/") + "/")
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			errs = append(errs, &fileError{path: walker.Path(), err: err})
+			continue
+		}
+
+		relPath, err := filepath.Rel(remotePath, walker.Path())
+		if err != nil {
+			continue
+		}
+		info := walker.Stat()
+		if info.Mode()&os.ModeSymlink != 0 && relPath != "." {
+			switch symlinks {
+			case SymlinkSkip:
+				continue
+			case SymlinkCopy:
+				target, err := sftpClient.ReadLink(walker.Path())
+				if err != nil {
+					errs = append(errs, &fileError{path: walker.Path(), err: err})
+				} else {
+					entries = append(entries, treeEntry{Path: walker.Path(), Rel: relPath, Link: target})
+				}
+				continue
+			}
+			if info, err = sftpClient.Stat(walker.Path()); err == nil && info.IsDir() {
+				err = errLinkedDirectory
+			}
+			if err != nil {
+				errs = append(errs, &fileError{path: walker.Path(), err: err})
+				continue
+			}
+		}
+		entries = append(entries, treeEntry{
+			Path:       walker.Path(),
+			Rel:        relPath,
+			IsDir:      info.IsDir(),
+			Size:       info.Size(),
+			Mode:       info.Mode(),
+			ModTime:    info.ModTime(),
+			AccessTime: remoteAccessTime(info),
+		})
+	}
+	return entries, errs
+}
+
+// listLocalTree walks a local directory like listRemoteTree
+func listLocalTree(localPath string, symlinks string) ([]treeEntry, []error) {
+	var entries []treeEntry
+	var errs []error
+	if resolved, err := filepath.EvalSymlinks(localPath); err == nil {
+		localPath = resolved
+	}
+	filepath.WalkDir(localPath, func(walkPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, &fileError{path: walkPath, err: err})
+			return nil
+		}
+		relPath, err := filepath.Rel(localPath, walkPath)
+		if err != nil {
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			switch symlinks {
+			case SymlinkSkip:
+				return nil
+			case SymlinkCopy:
+				target, err := os.Readlink(walkPath)
+				if err != nil {
+					errs = append(errs, &fileError{path: walkPath, err: err})
+				} else {
+					entries = append(entries, treeEntry{Path: walkPath, Rel: relPath, Link: target})
+				}
+				return nil
+			}
+		}
+		info, err := os.Stat(walkPath)
+		if err == nil && info.IsDir() && !d.IsDir() {
+			err = errLinkedDirectory
+		}
+		if err != nil {
+			errs = append(errs, &fileError{path: walkPath, err: err})
+			return nil
+		}
+		entries = append(entries, treeEntry{
+			Path:       walkPath,
+			Rel:        relPath,
+			IsDir:      info.IsDir(),
+			Size:       info.Size(),
+			Mode:       info.Mode(),
+			ModTime:    info.ModTime(),
+			AccessTime: localAccessTime(info),
+		})
+		return nil
+	})
+	return entries, errs
+}
+
+// treeTransfer configures a directory transfer
+type treeTransfer struct {
+	source       copySource  // Where uploads read from, the local filesystem if nil
+	check        string      // Resume check for partial files
+	preserve     bool        // Carry over mode bits and times of files and directories
+	symlinks     string      // Symlink policy the tree was listed with
+	parallel     int         // Files copied at once
+	skipComplete bool        // Skip files whose copy already has the source's size
+	progress     func(int64) // Called with bytes copied, may be nil
+	skipped      func(int64) // Called with bytes kept from partial or skipped files, may be nil
+}
+
+// treeTransferFor returns the directory transfer settings configured for a session's host
+func treeTransferFor(sessionID string) treeTransfer {
+	settings := sessionHostSettings(sessionID)
+	return treeTransfer{
+		check:    transferResumeCheck(sessionID),
+		preserve: settings.PreserveAttributes,
+		symlinks: settings.Symlinks,
+		parallel: parallelFiles(sessionID),
+	}
+}
+
+// fileCopy returns the single-file copy settings, counting resumed bytes as skipped
+func (opts treeTransfer) fileCopy() fileCopy {
+	fc := fileCopy{source: opts.source, check: opts.check, preserve: opts.preserve, progress: opts.progress}
+	if opts.skipped != nil {
+		fc.start = func(offset, total int64) { opts.skipped(offset) }
+	}
+	return fc
+}
+
+// skip reports whether an existing copy of size targetSize makes entry complete
+func (opts treeTransfer) skip(entry treeEntry, targetSize int64) bool {
+	if !opts.skipComplete || targetSize != entry.Size {
+		return false
+	}
+	if opts.skipped != nil {
+		opts.skipped(entry.Size)
+	}
+	return true
+}
+
+// copyTreeFiles runs copyFile for each file on opts.parallel workers.
+// Per-file failures are returned; only the context ending stops the transfer early.
+func copyTreeFiles(ctx context.Context, files []treeEntry, parallel int, copyFile func(treeEntry) error) []error {
+	var mu sync.Mutex
+	var errs []error
+
+	work := make(chan treeEntry)
+	var wg sync.WaitGroup
+	for i := 0; i < max(parallel, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range work {
+				if err := copyFile(entry); err != nil && ctx.Err() == nil {
+					mu.Lock()
+					errs = append(errs, &fileError{path: entry.Path, err: err})
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, entry := range files {
+		select {
+		case work <- entry:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	return errs
+}
+
+// downloadRemoteTree copies listed remote entries into localPath, resuming
+// partial files. Skipping complete files lets a paused download carry on.
+func downloadRemoteTree(ctx context.Context, sftpClient *sftp.Client, entries []treeEntry, localPath string, opts treeTransfer) []error {
+	// Directories first, so files can be written in any order
+	var files, dirs []treeEntry
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir {
+			files = append(files, entry)
+		} else if err := os.MkdirAll(filepath.Join(localPath, entry.Rel), 0755); err != nil {
+			errs = append(errs, &fileError{path: entry.Path, err: err})
+		} else {
+			dirs = append(dirs, entry)
+		}
+	}
+
+	fc := opts.fileCopy()
+	errs = append(errs, copyTreeFiles(ctx, files, opts.parallel, func(entry treeEntry) error {
+		targetPath := filepath.Join(localPath, entry.Rel)
+		if entry.Link != "" {
+			return linkLocal(entry.Link, targetPath)
+		}
+		if info, err := os.Stat(targetPath); err == nil && opts.skip(entry, info.Size()) {
+			return nil
+		}
+		_, err := resumableDownload(ctx, sftpClient, entry.Path, targetPath, fc)
+		return err
+	})...)
+
+	// Directory times last, as writing their files changes them
+	if opts.preserve && ctx.Err() == nil {
+		for _, dir := range dirs {
+			if err := preserveLocal(filepath.Join(localPath, dir.Rel), dir.Mode, dir.AccessTime, dir.ModTime); err != nil {
+				errs = append(errs, &fileError{path: dir.Path, err: err})
+			}
+		}
+	}
+	return errs
+}
+
+// uploadTree copies listed entries from opts.source into remotePath, the
+// upload counterpart of downloadRemoteTree
+func uploadTree(ctx context.Context, sftpClient *sftp.Client, entries []treeEntry, remotePath string, opts treeTransfer) []error {
+	var files, dirs []treeEntry
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir {
+			files = append(files, entry)
+		} else if err := sftpClient.MkdirAll(path.Join(remotePath, filepath.ToSlash(entry.Rel))); err != nil {
+			errs = append(errs, &fileError{path: entry.Path, err: err})
+		} else {
+			dirs = append(dirs, entry)
+		}
+	}
+
+	fc := opts.fileCopy()
+	errs = append(errs, copyTreeFiles(ctx, files, opts.parallel, func(entry treeEntry) error {
+		targetPath := path.Join(remotePath, filepath.ToSlash(entry.Rel))
+		if entry.Link != "" {
+			return linkRemote(sftpClient, entry.Link, targetPath)
+		}
+		if info, err := sftpClient.Stat(targetPath); err == nil && opts.skip(entry, info.Size()) {
+			return nil
+		}
+		_, err := resumableUpload(ctx, sftpClient, entry.Path, targetPath, fc)
+		return err
+	})...)
+
+	if opts.preserve && ctx.Err() == nil {
+		for _, dir := range dirs {
+			if err := preserveRemote(sftpClient, path.Join(remotePath, filepath.ToSlash(dir.Rel)), dir.Mode, dir.AccessTime, dir.ModTime); err != nil {
+				errs = append(errs, &fileError{path: dir.Path, err: err})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResumeOffset(t *testing.T) {
+	source := bytes.Repeat([]byte("abcdefghij"), 10000)
+	partial := append([]byte(nil), source[:70000]...)
+	size := int64(len(source))
+
+	if got := resumeOffset(bytes.NewReader(partial), 70000, bytes.NewReader(source), size, ResumeCheckTail); got != 70000 {
+		t.Errorf("Matching partial: got offset %d", got)
+	}
+
+	partial[69999] ^= 0xff
+	if got := resumeOffset(bytes.NewReader(partial), 70000, bytes.NewReader(source), size, ResumeCheckTail); got != 0 {
+		t.Errorf("Corrupt tail: got offset %d", got)
+	}
+	if got := resumeOffset(bytes.NewReader(partial), 70000, bytes.NewReader(source), size, ResumeCheckSize); got != 70000 {
+		t.Errorf("Size check: got offset %d", got)
+	}
+	if got := resumeOffset(bytes.NewReader(source), size, bytes.NewReader(source[:10]), 10, ResumeCheckSize); got != 0 {
+		t.Errorf("Partial larger than source: got offset %d", got)
+	}
+}
+
+func TestResumableDownload(t *testing.T) {
+	sftpClient := newTestSFTPClient(t, "resume-test")
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	data := bytes.Repeat([]byte("0123456789"), 50000)
+	remotePath := filepath.Join(srcDir, "data.bin")
+	localPath := filepath.Join(dstDir, "data.bin")
+	if err := os.WriteFile(remotePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// An interrupted earlier attempt
+	if err := os.WriteFile(localPath+partSuffix, data[:123456], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var offset, copied int64
+	opts := fileCopy{
+		check:    ResumeCheckTail,
+		start:    func(o, total int64) { offset = o },
+		progress: func(n int64) { copied += n },
+	}
+	if _, err := resumableDownload(context.Background(), sftpClient, remotePath, localPath, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if offset != 123456 || copied != int64(len(data))-123456 {
+		t.Errorf("Expected to resume at 123456, got offset %d and copied %d", offset, copied)
+	}
+	if got, err := os.ReadFile(localPath); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Downloaded file differs from the source (%v)", err)
+	}
+	if _, err := os.Stat(localPath + partSuffix); !os.IsNotExist(err) {
+		t.Errorf("Expected the .part file to be renamed")
+	}
+}
+
+func TestDownloadRemoteTree(t *testing.T) {
+	tuning := HostSettings{SFTPRequestSize: 16 * 1024, SFTPConcurrency: 8}
+	sftpClient := newTestSFTPClient(t, "tree-test", sftpClientOptions(tuning)...)
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+
+	var total int64
+	for i := 0; i < 12; i++ {
+		dir := filepath.Join(srcDir, fmt.Sprintf("d%d", i%3))
+		os.MkdirAll(dir, 0755)
+		data := bytes.Repeat([]byte{byte('a' + i)}, 100000*(i+1))
+		total += int64(len(data))
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d", i)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// One file is already there from an earlier run
+	os.MkdirAll(filepath.Join(dstDir, "d0"), 0755)
+	if err := os.WriteFile(filepath.Join(dstDir, "d0", "f0"), bytes.Repeat([]byte{'a'}, 100000), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, errs := listRemoteTree(sftpClient, srcDir, SymlinkFollow)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	var copied, skipped atomic.Int64
+	errs = downloadRemoteTree(context.Background(), sftpClient, entries, dstDir, treeTransfer{
+		check:        ResumeCheckTail,
+		parallel:     3,
+		skipComplete: true,
+		progress:     func(n int64) { copied.Add(n) },
+		skipped:      func(n int64) { skipped.Add(n) },
+	})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	if skipped.Load() != 100000 || copied.Load() != total-100000 {
+		t.Errorf("Expected 100000 bytes skipped and %d copied, got %d and %d", total-100000, skipped.Load(), copied.Load())
+	}
+	for _, entry := range entries {
+		if entry.IsDir {
+			continue
+		}
+		want, _ := os.ReadFile(entry.Path)
+		got, err := os.ReadFile(filepath.Join(dstDir, entry.Rel))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s differs from the source (%v)", entry.Rel, err)
+		}
+	}
+}
+
+func TestUploadLocalTree(t *testing.T) {
+	sftpClient := newTestSFTPClient(t, "upload-tree-test")
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+
+	for i := 0; i < 6; i++ {
+		dir := filepath.Join(srcDir, fmt.Sprintf("d%d", i%2), "sub")
+		os.MkdirAll(dir, 0755)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d", i)), bytes.Repeat([]byte{byte('a' + i)}, 50000), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A non-empty directory in the way of one file makes only that file fail
+	blocked := filepath.Join(dstDir, "d1", "sub", "f3")
+	os.MkdirAll(blocked, 0755)
+	os.WriteFile(filepath.Join(blocked, "keep"), nil, 0644)
+
+	entries, errs := listLocalTree(srcDir, SymlinkFollow)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	errs = uploadTree(context.Background(), sftpClient, entries, dstDir, treeTransfer{parallel: 2})
+	failed := transferFileErrors(errs)
+	if len(errs) != 1 || len(failed) != 1 || failed[0].Path != filepath.Join(srcDir, "d1", "sub", "f3") {
+		t.Fatalf("Expected only f3 to fail, got %v", errs)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir || entry.Rel == filepath.Join("d1", "sub", "f3") {
+			continue
+		}
+		want, _ := os.ReadFile(entry.Path)
+		got, err := os.ReadFile(filepath.Join(dstDir, entry.Rel))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s differs from the source (%v)", entry.Rel, err)
+		}
+	}
+}
+
+func TestUploadLocalTreePreserve(t *testing.T) {
+	sftpClient := newTestSFTPClient(t, "preserve-test")
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	os.MkdirAll(filepath.Join(srcDir, "bin"), 0755)
+	script := filepath.Join(srcDir, "bin", "run.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chmod(script, 0750)
+	os.Chtimes(script, mtime, mtime)
+	os.Symlink("bin/run.sh", filepath.Join(srcDir, "run"))
+	os.Symlink("bin", filepath.Join(srcDir, "tools"))
+	os.Chtimes(filepath.Join(srcDir, "bin"), mtime, mtime)
+
+	// Following links fails for the directory link only
+	_, errs := listLocalTree(srcDir, SymlinkFollow)
+	if len(errs) != 1 || !errors.Is(errs[0], errLinkedDirectory) {
+		t.Errorf("Expected the directory link to fail, got %v", errs)
+	}
+
+	entries, errs := listLocalTree(srcDir, SymlinkCopy)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	opts := treeTransfer{preserve: true, symlinks: SymlinkCopy, parallel: 2}
+	if errs := uploadTree(context.Background(), sftpClient, entries, dstDir, opts); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	info, err := os.Stat(filepath.Join(dstDir, "bin", "run.sh"))
+	if err != nil || info.Mode().Perm() != 0750 || !info.ModTime().Equal(mtime) {
+		t.Errorf("Expected mode 0750 and mtime %v, got %v", mtime, info)
+	}
+	if info, err := os.Stat(filepath.Join(dstDir, "bin")); err != nil || !info.ModTime().Equal(mtime) {
+		t.Errorf("Expected the directory mtime to be preserved, got %v", info.ModTime())
+	}
+	for link, want := range map[string]string{"run": "bin/run.sh", "tools": "bin"} {
+		if target, err := os.Readlink(filepath.Join(dstDir, link)); err != nil || target != want {
+			t.Errorf("Expected %s to link to %s, got %q (%v)", link, want, target, err)
+		}
+	}
+}
@@ -0,0 +1,951 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ZMODEM framing bytes (see Chuck Forsberg's "ZMODEM protocol" and lrzsz)
+const (
+	zPad   = '*'  // Header lead-in
+	zDLE   = 0x18 // Escape character (also CAN)
+	zBin   = 'A'  // Binary header, CRC-16
+	zHex   = 'B'  // Hex header, CRC-16
+	zBin32 = 'C'  // Binary header, CRC-32
+	xon    = 0x11
+	xoff   = 0x13
+)
+
+// ZMODEM frame types
+const (
+	zRQINIT  = 0  // Sender: request receive init
+	zRINIT   = 1  // Receiver: ready, with capabilities
+	zSINIT   = 2  // Sender: send init (attention string)
+	zACK     = 3  // Acknowledge
+	zFILE    = 4  // Sender: file name and info follow
+	zSKIP    = 5  // Receiver: skip this file
+	zNAK     = 6  // Last header was garbled
+	zABORT   = 7  // Abort batch transfer
+	zFIN     = 8  // Finish session
+	zRPOS    = 9  // Receiver: resume data at this position
+	zDATA    = 10 // Sender: data packets follow
+	zEOF     = 11 // Sender: end of file
+	zFERR    = 12 // Fatal read or write error
+	zCRC     = 13 // Request for / reply with file CRC
+	zCAN     = 16 // Other end cancelled with CAN*5
+	zFREECNT = 17 // Request for free disk space
+	zCOMMAND = 18 // Sender: command (never executed here)
+)
+
+// Data subpacket terminators (sent after ZDLE)
+const (
+	zCRCE = 'h' // End of frame, no response expected
+	zCRCG = 'i' // Frame continues, no response expected
+	zCRCQ = 'j' // Frame continues, ZACK expected
+	zCRCW = 'k' // End of frame, ZACK expected
+	zRUB0 = 'l' // Escaped 0x7f
+	zRUB1 = 'm' // Escaped 0xff
+)
+
+// ZRINIT capability flags (ZF0)
+const (
+	zCanFDX  = 0x01 // Full duplex
+	zCanOVIO = 0x02 // Can receive data during disk I/O
+	zCanFC32 = 0x20 // Can use 32-bit CRC
+	zEscCtl  = 0x40 // Wants all control characters escaped
+)
+
+// ZMODEM tuning
+const (
+	// ZmodemSubpacketSize is the data subpacket size used when sending
+	ZmodemSubpacketSize = 1024
+	// ZmodemWindowSize is how much is sent before waiting for an acknowledgement
+	ZmodemWindowSize = 32 * 1024
+	// ZmodemMaxSubpacket caps the size of a received subpacket (lrzsz sends at most 8 KB)
+	ZmodemMaxSubpacket = 16 * 1024
+	// ZmodemMaxErrors is how many consecutive protocol errors abort a transfer
+	ZmodemMaxErrors = 10
+	// ZmodemMaxGarbage is how many non-header bytes are skipped while waiting for a header
+	ZmodemMaxGarbage = 64 * 1024
+)
+
+var (
+	errZmodemCancelled   = errors.New("transfer cancelled")
+	errZmodemInterrupted = errors.New("transfer interrupted")
+	errZmodemGarbage     = errors.New("no ZMODEM header found")
+	errZmodemBadCRC      = &zmodemProtocolError{"bad CRC"}
+
+	// errZmodemInputDropped is returned for input to a terminal that's running a transfer
+	errZmodemInputDropped = errors.New("input dropped during a ZMODEM transfer")
+)
+
+// zmodemProtocolError is a recoverable error in a received frame (bad CRC,
+// bad escape). The other side is asked to resend instead of aborting.
+type zmodemProtocolError struct {
+	msg string
+}
+
+func (e *zmodemProtocolError) Error() string {
+	return e.msg
+}
+
+// isZmodemProtocolError reports whether err is recoverable by resending
+func isZmodemProtocolError(err error) bool {
+	var pe *zmodemProtocolError
+	return errors.As(err, &pe)
+}
+
+// zmodemAbortSequence cancels a transfer on the remote side: eight CANs, then
+// backspaces to erase them if they land at a shell prompt
+var zmodemAbortSequence = append(bytes.Repeat([]byte{zDLE}, 8), bytes.Repeat([]byte{'\b'}, 10)...)
+
+// zmodemHeader is a frame type with its four data bytes (ZP0..ZP3, or ZF3..ZF0)
+type zmodemHeader struct {
+	Type byte
+	Data [4]byte
+}
+
+// newPosHeader returns a header carrying a file position
+func newPosHeader(frameType byte, pos int64) zmodemHeader {
+	h := zmodemHeader{Type: frameType}
+	binary.LittleEndian.PutUint32(h.Data[:], uint32(pos))
+	return h
+}
+
+// Pos returns the file position carried in the header
+func (h zmodemHeader) Pos() int64 {
+	return int64(binary.LittleEndian.Uint32(h.Data[:]))
+}
+
+// Flags returns ZF0, the first flag byte
+func (h zmodemHeader) Flags() byte {
+	return h.Data[3]
+}
+
+// crc16 computes the CRC-16/XMODEM checksum ZMODEM uses
+func crc16(crc uint16, data []byte) uint16 {
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// zmodemConn reads and writes ZMODEM frames over a terminal's byte stream
+type zmodemConn struct {
+	r *bufio.Reader
+	w io.Writer
+
+	txCRC32 bool // Send binary frames with CRC-32
+	rxCRC32 bool // The last binary header received used CRC-32 (so do its subpackets)
+	escCtl  bool // Escape all control characters (receiver asked with ESCCTL)
+
+	cancelled *atomic.Bool
+}
+
+// isCancelled reports whether the transfer was cancelled by the user or the watchdog
+func (c *zmodemConn) isCancelled() bool {
+	return c.cancelled != nil && c.cancelled.Load()
+}
+
+// readByte reads a byte, failing once the transfer is cancelled
+func (c *zmodemConn) readByte() (byte, error) {
+	if c.isCancelled() {
+		return 0, errZmodemCancelled
+	}
+	return c.r.ReadByte()
+}
+
+// readEscaped reads one ZDLE-decoded byte. If a subpacket terminator is read
+// instead, it is returned as frameEnd.
+func (c *zmodemConn) readEscaped() (b byte, frameEnd byte, err error) {
+	for {
+		ch, err := c.readByte()
+		if err != nil {
+			return 0, 0, err
+		}
+		switch ch {
+		case xon, xoff, xon | 0x80, xoff | 0x80:
+			continue // Flow control noise
+		case zDLE:
+		default:
+			return ch, 0, nil
+		}
+
+		cans := 1
+		for {
+			ch, err = c.readByte()
+			if err != nil {
+				return 0, 0, err
+			}
+			if ch == xon || ch == xoff || ch == xon|0x80 || ch == xoff|0x80 {
+				continue
+			}
+			if ch != zDLE {
+				break
+			}
+			if cans++; cans >= 5 {
+				return 0, 0, errZmodemCancelled // CAN*5 from the other side
+			}
+		}
+
+		switch ch {
+		case zCRCE, zCRCG, zCRCQ, zCRCW:
+			return 0, ch, nil
+		case zRUB0:
+			return 0x7f, 0, nil
+		case zRUB1:
+			return 0xff, 0, nil
+		default:
+			if ch&0x60 == 0x40 {
+				return ch ^ 0x40, 0, nil
+			}
+			return 0, 0, &zmodemProtocolError{fmt.Sprintf("bad escape sequence 0x%02x", ch)}
+		}
+	}
+}
+
+// readHeader skips to the next header and reads it
+func (c *zmodemConn) readHeader() (zmodemHeader, error) {
+	garbage := 0
+	for {
+		ch, err := c.readByte()
+		if err != nil {
+			return zmodemHeader{}, err
+		}
+		if ch != zPad {
+			if garbage++; garbage > ZmodemMaxGarbage {
+				return zmodemHeader{}, errZmodemGarbage
+			}
+			continue
+		}
+		for ch == zPad {
+			if ch, err = c.readByte(); err != nil {
+				return zmodemHeader{}, err
+			}
+		}
+		if ch != zDLE {
+			continue
+		}
+		format, err := c.readByte()
+		if err != nil {
+			return zmodemHeader{}, err
+		}
+		switch format {
+		case zHex:
+			return c.readHexHeader()
+		case zBin:
+			return c.readBinaryHeader(false)
+		case zBin32:
+			return c.readBinaryHeader(true)
+		}
+	}
+}
+
+// readHexHeader reads the body of a hex header (after ZPAD ZPAD ZDLE 'B')
+func (c *zmodemConn) readHexHeader() (zmodemHeader, error) {
+	digits := make([]byte, 14)
+	for i := range digits {
+		ch, err := c.readByte()
+		if err != nil {
+			return zmodemHeader{}, err
+		}
+		digits[i] = ch
+	}
+	raw := make([]byte, 7)
+	if _, err := hex.Decode(raw, bytes.ToLower(digits)); err != nil {
+		return zmodemHeader{}, &zmodemProtocolError{fmt.Sprintf("bad hex header: %v", err)}
+	}
+	if crc16(0, raw[:5]) != binary.BigEndian.Uint16(raw[5:]) {
+		return zmodemHeader{}, errZmodemBadCRC
+	}
+
+	// Consume the CR LF (and XON) that end the header
+	if ch, err := c.r.Peek(1); err == nil && ch[0] == '\r' {
+		c.r.ReadByte()
+		if ch, err := c.r.Peek(1); err == nil && ch[0]&0x7f == '\n' {
+			c.r.ReadByte()
+		}
+	}
+	if c.r.Buffered() > 0 {
+		if ch, err := c.r.Peek(1); err == nil && ch[0] == xon {
+			c.r.ReadByte()
+		}
+	}
+
+	h := zmodemHeader{Type: raw[0]}
+	copy(h.Data[:], raw[1:5])
+	return h, nil
+}
+
+// readBinaryHeader reads the body of a binary header (after ZPAD ZDLE 'A' or 'C')
+func (c *zmodemConn) readBinaryHeader(useCRC32 bool) (zmodemHeader, error) {
+	crcLen := 2
+	if useCRC32 {
+		crcLen = 4
+	}
+	raw := make([]byte, 5+crcLen)
+	for i := range raw {
+		b, end, err := c.readEscaped()
+		if err != nil {
+			return zmodemHeader{}, err
+		}
+		if end != 0 {
+			return zmodemHeader{}, &zmodemProtocolError{"unexpected frame end in header"}
+		}
+		raw[i] = b
+	}
+
+	if useCRC32 {
+		if crc32.ChecksumIEEE(raw[:5]) != binary.LittleEndian.Uint32(raw[5:]) {
+			return zmodemHeader{}, errZmodemBadCRC
+		}
+	} else if crc16(0, raw[:5]) != binary.BigEndian.Uint16(raw[5:]) {
+		return zmodemHeader{}, errZmodemBadCRC
+	}
+
+	c.rxCRC32 = useCRC32
+	h := zmodemHeader{Type: raw[0]}
+	copy(h.Data[:], raw[1:5])
+	return h, nil
+}
+
+// readSubpacket reads a data subpacket following a binary header
+func (c *zmodemConn) readSubpacket() ([]byte, byte, error) {
+	var data []byte
+	var frameEnd byte
+	for {
+		b, end, err := c.readEscaped()
+		if err != nil {
+			return nil, 0, err
+		}
+		if end != 0 {
+			frameEnd = end
+			break
+		}
+		if len(data) >= ZmodemMaxSubpacket {
+			return nil, 0, &zmodemProtocolError{"subpacket too long"}
+		}
+		data = append(data, b)
+	}
+
+	crcLen := 2
+	if c.rxCRC32 {
+		crcLen = 4
+	}
+	crcBytes := make([]byte, crcLen)
+	for i := range crcBytes {
+		b, end, err := c.readEscaped()
+		if err != nil {
+			return nil, 0, err
+		}
+		if end != 0 {
+			return nil, 0, errZmodemBadCRC
+		}
+		crcBytes[i] = b
+	}
+
+	if c.rxCRC32 {
+		crc := crc32.Update(crc32.ChecksumIEEE(data), crc32.IEEETable, []byte{frameEnd})
+		if crc != binary.LittleEndian.Uint32(crcBytes) {
+			return nil, 0, errZmodemBadCRC
+		}
+	} else if crc16(crc16(0, data), []byte{frameEnd}) != binary.BigEndian.Uint16(crcBytes) {
+		return nil, 0, errZmodemBadCRC
+	}
+	return data, frameEnd, nil
+}
+
+// escape appends b to buf with ZDLE escaping
+func (c *zmodemConn) escape(buf *bytes.Buffer, b byte, prev byte) {
+	switch b {
+	case zDLE, 0x10, 0x90, xon, xon | 0x80, xoff, xoff | 0x80:
+		buf.WriteByte(zDLE)
+		buf.WriteByte(b ^ 0x40)
+		return
+	case '\r', '\r' | 0x80:
+		// CR after @ is escaped so telnet's "@ CR" escape doesn't trigger
+		if prev&0x7f == '@' {
+			buf.WriteByte(zDLE)
+			buf.WriteByte(b ^ 0x40)
+			return
+		}
+	}
+	if c.escCtl && b&0x60 == 0 {
+		buf.WriteByte(zDLE)
+		buf.WriteByte(b ^ 0x40)
+		return
+	}
+	buf.WriteByte(b)
+}
+
+// escapeAll appends data to buf with ZDLE escaping
+func (c *zmodemConn) escapeAll(buf *bytes.Buffer, data []byte) {
+	var prev byte
+	for _, b := range data {
+		c.escape(buf, b, prev)
+		prev = b
+	}
+}
+
+// writeHexHeader sends a hex header, used for receiver replies and ZFIN
+func (c *zmodemConn) writeHexHeader(h zmodemHeader) error {
+	raw := append([]byte{h.Type}, h.Data[:]...)
+	raw = binary.BigEndian.AppendUint16(raw, crc16(0, raw))
+
+	var buf bytes.Buffer
+	buf.WriteString("**\x18B")
+	buf.WriteString(hex.EncodeToString(raw))
+	buf.WriteString("\r\x8a")
+	if h.Type != zFIN && h.Type != zACK {
+		buf.WriteByte(xon)
+	}
+	_, err := c.w.Write(buf.Bytes())
+	return err
+}
+
+// writeBinaryHeader sends a binary header, CRC-32 if the receiver supports it
+func (c *zmodemConn) writeBinaryHeader(h zmodemHeader) error {
+	raw := append([]byte{h.Type}, h.Data[:]...)
+
+	var buf bytes.Buffer
+	buf.WriteByte(zPad)
+	buf.WriteByte(zDLE)
+	if c.txCRC32 {
+		buf.WriteByte(zBin32)
+		raw = binary.LittleEndian.AppendUint32(raw, crc32.ChecksumIEEE(raw))
+	} else {
+		buf.WriteByte(zBin)
+		raw = binary.BigEndian.AppendUint16(raw, crc16(0, raw))
+	}
+	c.escapeAll(&buf, raw)
+	_, err := c.w.Write(buf.Bytes())
+	return err
+}
+
+// writeSubpacket sends a data subpacket ending with frameEnd
+func (c *zmodemConn) writeSubpacket(data []byte, frameEnd byte) error {
+	var buf bytes.Buffer
+	c.escapeAll(&buf, data)
+	buf.WriteByte(zDLE)
+	buf.WriteByte(frameEnd)
+
+	var crcBytes []byte
+	if c.txCRC32 {
+		crc := crc32.Update(crc32.ChecksumIEEE(data), crc32.IEEETable, []byte{frameEnd})
+		crcBytes = binary.LittleEndian.AppendUint32(nil, crc)
+	} else {
+		crcBytes = binary.BigEndian.AppendUint16(nil, crc16(crc16(0, data), []byte{frameEnd}))
+	}
+	c.escapeAll(&buf, crcBytes)
+	if frameEnd == zCRCW {
+		buf.WriteByte(xon)
+	}
+	_, err := c.w.Write(buf.Bytes())
+	return err
+}
+
+// zmodemFileInfo is the file description sent in a ZFILE subpacket
+type zmodemFileInfo struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+	Mode    os.FileMode
+}
+
+// parseZmodemFileInfo parses "name\0size mtime mode ..." (mtime and mode in octal)
+func parseZmodemFileInfo(data []byte) zmodemFileInfo {
+	name, rest, _ := bytes.Cut(data, []byte{0})
+	info := zmodemFileInfo{Name: string(name), Size: -1}
+
+	fields := strings.Fields(string(bytes.TrimRight(rest, "\x00")))
+	if len(fields) > 0 {
+		if size, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+			info.Size = size
+		}
+	}
+	if len(fields) > 1 {
+		if mtime, err := strconv.ParseInt(fields[1], 8, 64); err == nil && mtime > 0 {
+			info.ModTime = time.Unix(mtime, 0)
+		}
+	}
+	if len(fields) > 2 {
+		if mode, err := strconv.ParseUint(fields[2], 8, 32); err == nil {
+			info.Mode = os.FileMode(mode) & os.ModePerm
+		}
+	}
+	return info
+}
+
+// formatZmodemFileInfo builds a ZFILE subpacket
+func formatZmodemFileInfo(name string, stat os.FileInfo, filesLeft int, bytesLeft int64) []byte {
+	meta := fmt.Sprintf("%d %o %o 0 %d %d", stat.Size(), stat.ModTime().Unix(), stat.Mode().Perm()|0100000, filesLeft, bytesLeft)
+	data := append([]byte(name), 0)
+	data = append(data, meta...)
+	return append(data, 0)
+}
+
+// safeZmodemFileName reduces a name sent by the remote to a plain file name
+func safeZmodemFileName(name string) (string, error) {
+	name = filepath.Base(filepath.FromSlash(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == ".." || name == string(filepath.Separator) {
+		return "", fmt.Errorf("invalid file name")
+	}
+	return name, nil
+}
+
+// zmodemProgress reports bytes transferred for the current file
+type zmodemProgress func(fileName string, transferred, total int64, status string, err error)
+
+// zmodemReceiver receives files from a remote "sz" into a local directory
+type zmodemReceiver struct {
+	conn     *zmodemConn
+	dir      string
+	progress zmodemProgress
+	received []string // Paths of files saved so far
+}
+
+// rinit is the ZRINIT header this side sends: full duplex, overlapped I/O, CRC-32
+func (r *zmodemReceiver) rinit() zmodemHeader {
+	return zmodemHeader{Type: zRINIT, Data: [4]byte{0, 0, 0, zCanFDX | zCanOVIO | zCanFC32}}
+}
+
+// run receives files until the sender finishes the session
+func (r *zmodemReceiver) run() error {
+	c := r.conn
+	if err := c.writeHexHeader(r.rinit()); err != nil {
+		return err
+	}
+
+	var file *os.File
+	var info zmodemFileInfo
+	var path string
+	var offset int64
+	defer func() {
+		if file != nil {
+			// Interrupted mid-file: don't leave a truncated copy behind
+			file.Close()
+			os.Remove(path)
+			r.progress(info.Name, offset, info.Size, "error", errZmodemInterrupted)
+		}
+	}()
+
+	errorCount := 0
+	for {
+		h, err := c.readHeader()
+		if err != nil {
+			if isZmodemProtocolError(err) {
+				if errorCount++; errorCount > ZmodemMaxErrors {
+					return fmt.Errorf("too many errors: %v", err)
+				}
+				if file != nil {
+					c.writeHexHeader(newPosHeader(zRPOS, offset))
+				} else {
+					c.writeHexHeader(zmodemHeader{Type: zNAK})
+				}
+				continue
+			}
+			return err
+		}
+		errorCount = 0
+
+		switch h.Type {
+		case zRQINIT:
+			if err := c.writeHexHeader(r.rinit()); err != nil {
+				return err
+			}
+
+		case zSINIT:
+			if _, _, err := c.readSubpacket(); err != nil {
+				c.writeHexHeader(zmodemHeader{Type: zNAK})
+				continue
+			}
+			if err := c.writeHexHeader(zmodemHeader{Type: zACK}); err != nil {
+				return err
+			}
+
+		case zFILE:
+			data, _, err := c.readSubpacket()
+			if err != nil {
+				c.writeHexHeader(zmodemHeader{Type: zNAK})
+				continue
+			}
+			if file != nil {
+				// Sender restarted with a new file; drop the unfinished one
+				file.Close()
+				os.Remove(path)
+				file = nil
+			}
+
+			info = parseZmodemFileInfo(data)
+			name, err := safeZmodemFileName(info.Name)
+			if err != nil {
+				c.writeHexHeader(zmodemHeader{Type: zSKIP})
+				continue
+			}
+			path = filepath.Join(r.dir, name)
+			if _, err := os.Stat(path); err == nil {
+				path = generateUniquePath(path)
+			}
+			if file, err = os.Create(path); err != nil {
+				r.progress(info.Name, 0, info.Size, "error", err)
+				file = nil
+				c.writeHexHeader(zmodemHeader{Type: zSKIP})
+				continue
+			}
+			offset = 0
+			r.progress(info.Name, 0, info.Size, "transferring", nil)
+			if err := c.writeHexHeader(newPosHeader(zRPOS, 0)); err != nil {
+				return err
+			}
+
+		case zDATA:
+			if file == nil {
+				continue
+			}
+			if h.Pos() != offset {
+				c.writeHexHeader(newPosHeader(zRPOS, offset))
+				continue
+			}
+			if err := r.receiveData(file, &offset, info); err != nil {
+				if !isZmodemProtocolError(err) {
+					return err
+				}
+				if errorCount++; errorCount > ZmodemMaxErrors {
+					return fmt.Errorf("too many errors: %v", err)
+				}
+				c.writeHexHeader(newPosHeader(zRPOS, offset))
+			}
+
+		case zEOF:
+			if file == nil || h.Pos() != offset {
+				continue // Stale EOF; the sender will resend from our ZRPOS
+			}
+			err := file.Close()
+			file = nil
+			if err != nil {
+				r.progress(info.Name, offset, info.Size, "error", err)
+				os.Remove(path)
+			} else {
+				if !info.ModTime.IsZero() {
+					os.Chtimes(path, info.ModTime, info.ModTime)
+				}
+				if info.Mode != 0 {
+					os.Chmod(path, info.Mode)
+				}
+				r.received = append(r.received, path)
+				r.progress(info.Name, offset, offset, "completed", nil)
+			}
+			if err := c.writeHexHeader(r.rinit()); err != nil {
+				return err
+			}
+
+		case zFREECNT:
+			c.writeHexHeader(newPosHeader(zACK, 0xffffffff))
+
+		case zCOMMAND:
+			// Remote command execution is never allowed
+			c.w.Write(zmodemAbortSequence)
+			return fmt.Errorf("remote requested command execution, refused")
+
+		case zFIN:
+			if err := c.writeHexHeader(zmodemHeader{Type: zFIN}); err != nil {
+				return err
+			}
+			// The sender ends the session with "OO" (over and out)
+			if oo, err := c.r.Peek(2); err == nil && string(oo) == "OO" {
+				c.r.Discard(2)
+			}
+			return nil
+
+		case zCAN, zABORT, zFERR:
+			return errZmodemCancelled
+		}
+	}
+}
+
+// receiveData writes the data subpackets of one ZDATA frame to file
+func (r *zmodemReceiver) receiveData(file *os.File, offset *int64, info zmodemFileInfo) error {
+	c := r.conn
+	for {
+		data, frameEnd, err := c.readSubpacket()
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(data); err != nil {
+			c.writeHexHeader(zmodemHeader{Type: zFERR})
+			return err
+		}
+		*offset += int64(len(data))
+		r.progress(info.Name, *offset, info.Size, "transferring", nil)
+
+		switch frameEnd {
+		case zCRCW:
+			return c.writeHexHeader(newPosHeader(zACK, *offset))
+		case zCRCQ:
+			if err := c.writeHexHeader(newPosHeader(zACK, *offset)); err != nil {
+				return err
+			}
+		case zCRCE:
+			return nil
+		}
+	}
+}
+
+// zmodemSender sends local files to a remote "rz"
+type zmodemSender struct {
+	conn     *zmodemConn
+	files    []string
+	progress zmodemProgress
+	window   int64
+	sent     []string // Paths of files sent so far
+}
+
+// run waits for the receiver's ZRINIT, sends every file and ends the session
+func (s *zmodemSender) run() error {
+	c := s.conn
+	rinit, err := s.waitForRINIT()
+	if err != nil {
+		return err
+	}
+	c.txCRC32 = rinit.Flags()&zCanFC32 != 0
+	c.escCtl = rinit.Flags()&zEscCtl != 0
+	s.window = ZmodemWindowSize
+	if bufSize := int64(rinit.Data[0]) | int64(rinit.Data[1])<<8; bufSize > 0 && bufSize < s.window {
+		s.window = bufSize
+	}
+
+	var bytesLeft int64
+	for _, path := range s.files {
+		if stat, err := os.Stat(path); err == nil {
+			bytesLeft += stat.Size()
+		}
+	}
+
+	for i, path := range s.files {
+		// Local problems with one file skip it; protocol and connection errors end the batch
+		file, err := os.Open(path)
+		if err != nil {
+			s.progress(filepath.Base(path), 0, 0, "error", err)
+			continue
+		}
+		stat, err := file.Stat()
+		if err == nil && !stat.Mode().IsRegular() {
+			err = fmt.Errorf("not a regular file")
+		}
+		if err != nil {
+			file.Close()
+			s.progress(filepath.Base(path), 0, 0, "error", err)
+			continue
+		}
+
+		err = s.sendFile(file, stat, len(s.files)-i, bytesLeft)
+		file.Close()
+		if err != nil {
+			s.progress(stat.Name(), 0, stat.Size(), "error", err)
+			return err
+		}
+		bytesLeft -= stat.Size()
+	}
+
+	// End the session: ZFIN, wait for the receiver's ZFIN, then "OO"
+	for attempt := 0; attempt < ZmodemMaxErrors; attempt++ {
+		if err := c.writeHexHeader(zmodemHeader{Type: zFIN}); err != nil {
+			return err
+		}
+		h, err := c.readHeader()
+		if err != nil && !isZmodemProtocolError(err) {
+			return err
+		}
+		if err == nil && h.Type == zFIN {
+			_, err := c.w.Write([]byte("OO"))
+			return err
+		}
+	}
+	return fmt.Errorf("receiver did not finish the session")
+}
+
+// waitForRINIT reads headers until the receiver's ZRINIT arrives
+func (s *zmodemSender) waitForRINIT() (zmodemHeader, error) {
+	c := s.conn
+	for attempt := 0; attempt < ZmodemMaxErrors; attempt++ {
+		h, err := c.readHeader()
+		if err != nil {
+			if !isZmodemProtocolError(err) {
+				return zmodemHeader{}, err
+			}
+			continue
+		}
+		switch h.Type {
+		case zRINIT:
+			return h, nil
+		case zCAN, zABORT:
+			return zmodemHeader{}, errZmodemCancelled
+		default:
+			c.writeHexHeader(zmodemHeader{Type: zRQINIT})
+		}
+	}
+	return zmodemHeader{}, fmt.Errorf("receiver never became ready")
+}
+
+// sendFile offers one file and streams it from the position the receiver asks for
+func (s *zmodemSender) sendFile(file *os.File, stat os.FileInfo, filesLeft int, bytesLeft int64) error {
+	c := s.conn
+	name := stat.Name()
+	size := stat.Size()
+
+	// Offer the file until the receiver asks for data or skips it
+	var pos int64 = -1
+	offer := true
+	for attempt := 0; pos < 0; {
+		if offer {
+			if attempt++; attempt > ZmodemMaxErrors {
+				return fmt.Errorf("receiver did not accept the file")
+			}
+			// ZF0 = ZCBIN: binary transfer, no newline conversion
+			if err := c.writeBinaryHeader(zmodemHeader{Type: zFILE, Data: [4]byte{0, 0, 0, 1}}); err != nil {
+				return err
+			}
+			if err := c.writeSubpacket(formatZmodemFileInfo(name, stat, filesLeft, bytesLeft), zCRCW); err != nil {
+				return err
+			}
+			offer = false
+		}
+
+		h, err := c.readHeader()
+		if err != nil {
+			if !isZmodemProtocolError(err) {
+				return err
+			}
+			offer = true
+			continue
+		}
+		switch h.Type {
+		case zRPOS:
+			pos = h.Pos()
+		case zSKIP:
+			s.progress(name, 0, size, "skipped", nil)
+			return nil
+		case zCRC:
+			// Receiver has a file with this name and asks for the CRC to decide whether to resume
+			crc := crc32.NewIEEE()
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			if _, err := io.Copy(crc, file); err != nil {
+				return err
+			}
+			reply := zmodemHeader{Type: zCRC}
+			binary.LittleEndian.PutUint32(reply.Data[:], crc.Sum32())
+			if err := c.writeHexHeader(reply); err != nil {
+				return err
+			}
+		case zRINIT, zNAK:
+			offer = true
+		case zCAN, zABORT, zFERR:
+			return errZmodemCancelled
+		}
+	}
+
+	s.progress(name, pos, size, "transferring", nil)
+	buf := make([]byte, ZmodemSubpacketSize)
+	for errorCount := 0; errorCount <= ZmodemMaxErrors; {
+		if _, err := file.Seek(pos, io.SeekStart); err != nil {
+			return err
+		}
+		if err := c.writeBinaryHeader(newPosHeader(zDATA, pos)); err != nil {
+			return err
+		}
+
+		// Stream a window of subpackets; the last one asks for an ACK
+		var windowSent int64
+		atEOF := false
+		for {
+			n, err := io.ReadFull(file, buf)
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				atEOF = true
+			} else if err != nil {
+				return err
+			}
+
+			frameEnd := byte(zCRCG)
+			if atEOF {
+				frameEnd = zCRCE
+			} else if windowSent+int64(n) >= s.window {
+				frameEnd = zCRCW
+			}
+			if err := c.writeSubpacket(buf[:n], frameEnd); err != nil {
+				return err
+			}
+			pos += int64(n)
+			windowSent += int64(n)
+			s.progress(name, pos, size, "transferring", nil)
+
+			if frameEnd != zCRCG {
+				break
+			}
+		}
+
+		if atEOF {
+			if err := c.writeHexHeader(newPosHeader(zEOF, pos)); err != nil {
+				return err
+			}
+		}
+
+		// Wait for the receiver: ZACK continues, ZRINIT after ZEOF means done,
+		// ZRPOS rewinds after an error
+		for {
+			h, err := c.readHeader()
+			if err != nil {
+				if !isZmodemProtocolError(err) {
+					return err
+				}
+				errorCount++
+				break
+			}
+			switch h.Type {
+			case zACK:
+				if atEOF {
+					continue
+				}
+			case zRINIT:
+				if !atEOF {
+					continue
+				}
+				s.sent = append(s.sent, file.Name())
+				s.progress(name, size, size, "completed", nil)
+				return nil
+			case zRPOS:
+				pos = h.Pos()
+				errorCount++
+			case zSKIP:
+				s.progress(name, pos, size, "skipped", nil)
+				return nil
+			case zCAN, zABORT, zFERR:
+				return errZmodemCancelled
+			default:
+				continue
+			}
+			break
+		}
+	}
+	return fmt.Errorf("too many errors")
+}
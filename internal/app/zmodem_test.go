@@ -0,0 +1,122 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestZmodemHexHeaderMatchesLrzsz(t *testing.T) {
+	// The ZRINIT that lrzsz's rz prints when it starts
+	var buf bytes.Buffer
+	c := &zmodemConn{w: &buf}
+	if err := c.writeHexHeader(zmodemHeader{Type: zRINIT, Data: [4]byte{0, 0, 0, 0x23}}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "**\x18B0100000023be50\r\x8a\x11" {
+		t.Errorf("Unexpected header %q", buf.String())
+	}
+
+	c = &zmodemConn{r: bufio.NewReader(bytes.NewReader(buf.Bytes()))}
+	h, err := c.readHeader()
+	if err != nil || h.Type != zRINIT || h.Flags() != 0x23 {
+		t.Errorf("Failed to read header back: %+v, %v", h, err)
+	}
+}
+
+func TestZmodemSendReceive(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	rng := rand.New(rand.NewSource(1))
+	contents := map[string][]byte{
+		"big.bin":   make([]byte, ZmodemWindowSize*2+123), // several windows
+		"empty.txt": {},
+		"escapes":   {zDLE, xon, xoff, 0x10, 0x90, '@', '\r', 0x7f, 0xff, '*', '*', zDLE, 'B'},
+	}
+	rng.Read(contents["big.bin"])
+
+	var files []string
+	for name, data := range contents {
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, data, 0640); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	// os.Pipe buffers, so both sides can write at once like a real connection
+	toReceiver, fromSender, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	toSender, fromReceiver, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer toReceiver.Close()
+	defer toSender.Close()
+
+	noProgress := func(string, int64, int64, string, error) {}
+	receiver := &zmodemReceiver{
+		conn:     &zmodemConn{r: bufio.NewReader(toReceiver), w: fromReceiver},
+		dir:      dstDir,
+		progress: noProgress,
+	}
+	sender := &zmodemSender{
+		conn:     &zmodemConn{r: bufio.NewReader(toSender), w: fromSender},
+		files:    files,
+		progress: noProgress,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- receiver.run()
+		fromReceiver.Close()
+	}()
+
+	if err := sender.run(); err != nil {
+		t.Fatalf("Sender failed: %v", err)
+	}
+	fromSender.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Receiver failed: %v", err)
+	}
+
+	if len(receiver.received) != len(contents) {
+		t.Errorf("Expected %d files, received %v", len(contents), receiver.received)
+	}
+	for name, expected := range contents {
+		got, err := os.ReadFile(filepath.Join(dstDir, name))
+		if err != nil {
+			t.Errorf("Missing %s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(got, expected) {
+			t.Errorf("Content of %s differs (%d bytes, expected %d)", name, len(got), len(expected))
+		}
+	}
+}
+
+func TestZmodemDetectorSplitStart(t *testing.T) {
+	d := &zmodemDetector{}
+	output, direction, _ := d.scan([]byte("$ sz file\r\n**\x18"))
+	if string(output) != "$ sz file\r\n" || direction != "" {
+		t.Fatalf("first read: output %q, direction %q", output, direction)
+	}
+	output, direction, rest := d.scan([]byte("B00000000000000\r\x8a\x11"))
+	if len(output) != 0 || direction != zmodemDownload || !bytes.HasPrefix(rest, []byte("**\x18B00")) {
+		t.Fatalf("second read: output %q, direction %q, rest %q", output, direction, rest)
+	}
+
+	// A lone "**" that isn't followed by a header is plain output
+	d = &zmodemDetector{}
+	output, _, _ = d.scan([]byte("a **"))
+	more, direction, _ := d.scan([]byte("bold**"))
+	if string(output)+string(more) != "a **bold" || direction != "" {
+		t.Fatalf("plain text mangled: %q%q", output, more)
+	}
+}
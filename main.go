@@ -60,6 +60,7 @@ func main() {
 	if err != nil {
 		log.Fatal("Failed to get sub filesystem:", err)
 	}
+	app.SetShareViewerAssets(distFS)
 
 	// Create application menu with standard macOS menus
 	appMenu := menu.NewMenu()
@@ -194,8 +195,9 @@ func main() {
 		}
 	}
 
-	// Shutdown function: cleanup temp directories used for clipboard operations
+	// Shutdown function: save pending state and cleanup temp directories used for clipboard operations
 	shutdownFunc := func(ctx context.Context) {
+		app.FlushPendingSaves()
 		log.Printf("🧹 App shutting down, cleaning temp directories...")
 		app.CleanupTempDirs()
 	}